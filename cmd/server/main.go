@@ -1,12 +1,17 @@
 package main
 
 import (
+	"context"
+	"go-payroll-service/internal/auth"
 	"go-payroll-service/internal/config"
 	"go-payroll-service/internal/db"
 	controller2 "go-payroll-service/internal/payroll/controller"
+	"go-payroll-service/internal/payroll/disbursement"
+	"go-payroll-service/internal/payroll/document"
 	repository2 "go-payroll-service/internal/payroll/repository"
 	service2 "go-payroll-service/internal/payroll/service"
 	"log"
+	"os"
 
 	"github.com/gin-gonic/gin"
 )
@@ -19,21 +24,100 @@ func main() {
 		log.Fatalf("Error connecting to database: %v", err)
 	}
 
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		if err := runMigrate(context.Background(), dbConn, os.Args[2:]); err != nil {
+			log.Fatalf("Error migrating database: %v", err)
+		}
+		return
+	}
+	if err := checkSchema(dbConn); err != nil {
+		log.Fatalf("Error checking database schema: %v", err)
+	}
+
+	payslipTemplate := document.DefaultTemplate()
+	if cfg.PayslipTemplate != "" {
+		if payslipTemplate, err = document.LoadTemplate(cfg.PayslipTemplate); err != nil {
+			log.Fatalf("Error loading payslip template: %v", err)
+		}
+	}
+	payslipRenderer, err := document.NewRenderer(payslipTemplate)
+	if err != nil {
+		log.Fatalf("Error loading payslip template: %v", err)
+	}
+
+	verifier, err := auth.NewVerifier(cfg.Auth)
+	if err != nil {
+		log.Fatalf("Error configuring authentication: %v", err)
+	}
+
 	r := gin.Default()
+	r.Use(controller2.RequestID())
 
 	//dependency injection
 	empRepo := repository2.NewEmployeeRepository(dbConn)
 	payrollRepo := repository2.NewPayrollRepository(dbConn)
+	periodRepo := repository2.NewPeriodRepository(dbConn)
+	taxRepo := repository2.NewTaxRepository(dbConn)
+	bpjsRepo := repository2.NewBPJSRepository(dbConn)
+	componentRepo := repository2.NewComponentRepository(dbConn)
+	attendanceRepo := repository2.NewAttendanceRepository(dbConn)
+	bankAccountRepo := repository2.NewBankAccountRepository(dbConn)
+	glMappingRepo := repository2.NewGLMappingRepository(dbConn)
+	salaryRepo := repository2.NewSalaryRepository(dbConn)
+	overtimeRepo := repository2.NewOvertimeRepository(dbConn)
+	leaveRepo := repository2.NewLeaveRepository(dbConn)
+	loanRepo := repository2.NewLoanRepository(dbConn)
+	runRepo := repository2.NewRunRepository(dbConn)
+	auditRepo := repository2.NewAuditRepository(dbConn)
+	transactor := repository2.NewTransactor(dbConn)
 
-	empService := service2.NewEmployeeService(empRepo)
-	payrollService := service2.NewPayrollService(empRepo, payrollRepo)
+	empService := service2.NewEmployeeService(empRepo, salaryRepo, auditRepo, transactor)
+	payrollService := service2.NewPayrollService(empRepo, payrollRepo, periodRepo, taxRepo, bpjsRepo, componentRepo, attendanceRepo, salaryRepo, overtimeRepo, leaveRepo, loanRepo, runRepo, auditRepo, transactor,
+		cfg.ProrationMethod, cfg.WorkWeek)
+	componentService := service2.NewComponentService(componentRepo, empRepo, auditRepo, transactor)
+	periodService := service2.NewPeriodService(periodRepo, runRepo, auditRepo, transactor)
+	salaryService := service2.NewSalaryService(salaryRepo, empRepo, auditRepo, transactor)
+	disbursementService := service2.NewDisbursementService(bankAccountRepo, empRepo, periodRepo, payrollRepo, runRepo, auditRepo, transactor,
+		disbursement.NewRegistry(disbursement.CSV{}, disbursement.Pain001{}, disbursement.BCA{}),
+		disbursement.Account{Name: cfg.CompanyName, BankCode: cfg.CompanyBankCode, Number: cfg.CompanyAccountNumber})
+	ledgerService := service2.NewLedgerService(glMappingRepo, periodRepo, payrollRepo, auditRepo, transactor)
+	payslipDocumentService := service2.NewPayslipDocumentService(payrollRepo, periodRepo, empRepo, payslipRenderer)
+	auditService := service2.NewAuditService(auditRepo)
+	overtimeService := service2.NewOvertimeService(overtimeRepo, empRepo, periodRepo, auditRepo, transactor, cfg.WorkWeek)
+	leaveService := service2.NewLeaveService(leaveRepo, empRepo, periodRepo, runRepo, auditRepo, transactor, cfg.WorkWeek)
+	loanService := service2.NewLoanService(loanRepo, empRepo, periodRepo, auditRepo, transactor)
+	runService := service2.NewRunService(runRepo, periodRepo, payrollRepo, auditRepo, transactor, cfg.ApprovalPolicy)
 
 	empController := controller2.NewEmployeeController(empService)
 	payrollController := controller2.NewPayrollController(payrollService)
+	componentController := controller2.NewComponentController(componentService)
+	periodController := controller2.NewPeriodController(periodService)
+	salaryController := controller2.NewSalaryController(salaryService)
+	disbursementController := controller2.NewDisbursementController(disbursementService)
+	ledgerController := controller2.NewLedgerController(ledgerService)
+	payslipDocumentController := controller2.NewPayslipDocumentController(payslipDocumentService)
+	meController := controller2.NewMeController(empService, payrollService, payslipDocumentService, leaveService, loanService)
+	auditController := controller2.NewAuditController(auditService)
+	overtimeController := controller2.NewOvertimeController(overtimeService)
+	leaveController := controller2.NewLeaveController(leaveService)
+	loanController := controller2.NewLoanController(loanService)
+	runController := controller2.NewRunController(runService)
 
-	api := r.Group("/api/v1")
+	api := r.Group("/api/v1", auth.Authenticate(verifier))
 	empController.RegisterRoutes(api)
 	payrollController.RegisterRoutes(api)
+	componentController.RegisterRoutes(api)
+	periodController.RegisterRoutes(api)
+	salaryController.RegisterRoutes(api)
+	payslipDocumentController.RegisterRoutes(api)
+	disbursementController.RegisterRoutes(api)
+	ledgerController.RegisterRoutes(api)
+	meController.RegisterRoutes(api)
+	auditController.RegisterRoutes(api)
+	overtimeController.RegisterRoutes(api)
+	leaveController.RegisterRoutes(api)
+	loanController.RegisterRoutes(api)
+	runController.RegisterRoutes(api)
 
 	addr := ":" + cfg.HTTPPort
 	log.Println("Listening on " + addr)
@@ -0,0 +1,137 @@
+package auth
+
+import (
+	"context"
+	"crypto/rsa"
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const (
+	RoleHRAdmin         = "hr_admin"
+	RolePayrollOfficer  = "payroll_officer"
+	RoleFinanceViewer   = "finance_viewer"
+	RolePayrollApprover = "payroll_approver"
+	RoleEmployee        = "employee"
+)
+
+const (
+	AlgorithmHS256 = "HS256"
+	AlgorithmRS256 = "RS256"
+)
+
+var ErrUnauthenticated = errors.New("missing or invalid bearer token")
+
+// Principal is the caller a verified token identifies.
+type Principal struct {
+	Subject string
+	Roles   []string
+	// EmployeeID links the caller to their employee record; 0 if none.
+	EmployeeID int64
+}
+
+func (p Principal) HasAny(roles ...string) bool {
+	for _, r := range roles {
+		if slices.Contains(p.Roles, r) {
+			return true
+		}
+	}
+	return false
+}
+
+type principalCtxKey struct{}
+
+// WithPrincipal returns ctx carrying p, for code below the HTTP layer.
+func WithPrincipal(ctx context.Context, p Principal) context.Context {
+	return context.WithValue(ctx, principalCtxKey{}, p)
+}
+
+// FromContext returns the principal ctx carries, if any.
+func FromContext(ctx context.Context) (Principal, bool) {
+	p, ok := ctx.Value(principalCtxKey{}).(Principal)
+	return p, ok
+}
+
+// Claims are the token claims read besides the registered ones.
+type Claims struct {
+	Roles      []string `json:"roles"`
+	EmployeeID int64    `json:"employee_id,omitempty"`
+	jwt.RegisteredClaims
+}
+
+// Config selects how tokens are verified. HS256 needs Secret; RS256 needs
+// PublicKeyFile, a PEM encoded RSA public key. Issuer and Audience are
+// checked when set.
+type Config struct {
+	Algorithm     string
+	Secret        string
+	PublicKeyFile string
+	Issuer        string
+	Audience      string
+}
+
+type Verifier struct {
+	parser *jwt.Parser
+	key    any
+}
+
+func NewVerifier(cfg Config) (*Verifier, error) {
+	v := &Verifier{}
+	switch cfg.Algorithm {
+	case AlgorithmHS256:
+		if len(cfg.Secret) < 32 {
+			return nil, errors.New("HS256 secret must be at least 32 bytes")
+		}
+		v.key = []byte(cfg.Secret)
+	case AlgorithmRS256:
+		pem, err := os.ReadFile(cfg.PublicKeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("read RS256 public key: %w", err)
+		}
+		var key *rsa.PublicKey
+		if key, err = jwt.ParseRSAPublicKeyFromPEM(pem); err != nil {
+			return nil, fmt.Errorf("parse RS256 public key: %w", err)
+		}
+		v.key = key
+	default:
+		return nil, fmt.Errorf("unsupported algorithm %q, expected %s or %s", cfg.Algorithm, AlgorithmHS256, AlgorithmRS256)
+	}
+
+	opts := []jwt.ParserOption{
+		jwt.WithValidMethods([]string{cfg.Algorithm}),
+		jwt.WithExpirationRequired(),
+		jwt.WithLeeway(30 * time.Second),
+	}
+	if cfg.Issuer != "" {
+		opts = append(opts, jwt.WithIssuer(cfg.Issuer))
+	}
+	if cfg.Audience != "" {
+		opts = append(opts, jwt.WithAudience(cfg.Audience))
+	}
+	v.parser = jwt.NewParser(opts...)
+	return v, nil
+}
+
+// Verify checks the token's signature and claims and returns its principal.
+func (v *Verifier) Verify(token string) (Principal, error) {
+	var claims Claims
+	_, err := v.parser.ParseWithClaims(token, &claims, func(*jwt.Token) (any, error) {
+		return v.key, nil
+	})
+	if err != nil {
+		return Principal{}, fmt.Errorf("%w: %w", ErrUnauthenticated, err)
+	}
+	if claims.Subject == "" {
+		return Principal{}, fmt.Errorf("%w: token has no subject", ErrUnauthenticated)
+	}
+	return Principal{
+		Subject:    claims.Subject,
+		Roles:      claims.Roles,
+		EmployeeID: claims.EmployeeID,
+	}, nil
+}
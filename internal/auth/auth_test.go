@@ -0,0 +1,204 @@
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const secret = "0123456789abcdef0123456789abcdef"
+
+func claims(mod func(c *Claims)) Claims {
+	c := Claims{
+		Roles:      []string{RolePayrollOfficer, RoleEmployee},
+		EmployeeID: 42,
+		RegisteredClaims: jwt.RegisteredClaims{
+			Subject:   "siti@example.com",
+			Issuer:    "hr-portal",
+			Audience:  jwt.ClaimStrings{"payroll"},
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour)),
+		},
+	}
+	if mod != nil {
+		mod(&c)
+	}
+	return c
+}
+
+func sign(t *testing.T, method jwt.SigningMethod, key any, c Claims) string {
+	t.Helper()
+	token, err := jwt.NewWithClaims(method, c).SignedString(key)
+	if err != nil {
+		t.Fatalf("sign token: %v", err)
+	}
+	return token
+}
+
+func TestVerifyHS256(t *testing.T) {
+	v, err := NewVerifier(Config{Algorithm: AlgorithmHS256, Secret: secret, Issuer: "hr-portal", Audience: "payroll"})
+	if err != nil {
+		t.Fatalf("NewVerifier() error = %v", err)
+	}
+	hs := func(c Claims) string { return sign(t, jwt.SigningMethodHS256, []byte(secret), c) }
+
+	tests := []struct {
+		name    string
+		token   string
+		wantErr bool
+	}{
+		{"valid", hs(claims(nil)), false},
+		{"within leeway", hs(claims(func(c *Claims) { c.ExpiresAt = jwt.NewNumericDate(time.Now().Add(-10 * time.Second)) })), false},
+		{"expired", hs(claims(func(c *Claims) { c.ExpiresAt = jwt.NewNumericDate(time.Now().Add(-time.Minute)) })), true},
+		{"no expiry", hs(claims(func(c *Claims) { c.ExpiresAt = nil })), true},
+		{"not yet valid", hs(claims(func(c *Claims) { c.NotBefore = jwt.NewNumericDate(time.Now().Add(time.Hour)) })), true},
+		{"no subject", hs(claims(func(c *Claims) { c.Subject = "" })), true},
+		{"other issuer", hs(claims(func(c *Claims) { c.Issuer = "elsewhere" })), true},
+		{"other audience", hs(claims(func(c *Claims) { c.Audience = jwt.ClaimStrings{"billing"} })), true},
+		{"wrong secret", sign(t, jwt.SigningMethodHS256, []byte(secret+"x"), claims(nil)), true},
+		{"other algorithm", sign(t, jwt.SigningMethodHS512, []byte(secret), claims(nil)), true},
+		{"unsigned", sign(t, jwt.SigningMethodNone, jwt.UnsafeAllowNoneSignatureType, claims(nil)), true},
+		{"garbage", "not.a.token", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, err := v.Verify(tt.token)
+			if tt.wantErr {
+				if !errors.Is(err, ErrUnauthenticated) {
+					t.Errorf("Verify() error = %v, want ErrUnauthenticated", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Verify() error = %v", err)
+			}
+			want := Principal{Subject: "siti@example.com", Roles: []string{RolePayrollOfficer, RoleEmployee}, EmployeeID: 42}
+			if p.Subject != want.Subject || p.EmployeeID != want.EmployeeID || !slices.Equal(p.Roles, want.Roles) {
+				t.Errorf("Verify() = %+v, want %+v", p, want)
+			}
+		})
+	}
+}
+
+func TestVerifyRS256(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	der, err := x509.MarshalPKIXPublicKey(&key.PublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	file := filepath.Join(t.TempDir(), "public.pem")
+	if err := os.WriteFile(file, pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	v, err := NewVerifier(Config{Algorithm: AlgorithmRS256, PublicKeyFile: file})
+	if err != nil {
+		t.Fatalf("NewVerifier() error = %v", err)
+	}
+
+	other, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name    string
+		token   string
+		wantErr bool
+	}{
+		{"valid", sign(t, jwt.SigningMethodRS256, key, claims(nil)), false},
+		{"other key", sign(t, jwt.SigningMethodRS256, other, claims(nil)), true},
+		// An HS256 token keyed with the public key must not pass.
+		{"algorithm confusion", sign(t, jwt.SigningMethodHS256, pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}), claims(nil)), true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, err := v.Verify(tt.token)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Verify() error = %v, want error %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && p.Subject != "siti@example.com" {
+				t.Errorf("Verify() subject = %q", p.Subject)
+			}
+		})
+	}
+}
+
+func TestNewVerifierErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		cfg  Config
+	}{
+		{"short secret", Config{Algorithm: AlgorithmHS256, Secret: "short"}},
+		{"no public key", Config{Algorithm: AlgorithmRS256, PublicKeyFile: filepath.Join(t.TempDir(), "missing.pem")}},
+		{"unsupported algorithm", Config{Algorithm: "none", Secret: secret}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := NewVerifier(tt.cfg); err == nil {
+				t.Error("NewVerifier() error = nil, want an error")
+			}
+		})
+	}
+}
+
+func TestMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	v, err := NewVerifier(Config{Algorithm: AlgorithmHS256, Secret: secret})
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := gin.New()
+	r.GET("/payroll", Authenticate(v), Require(RoleHRAdmin, RolePayrollOfficer), func(c *gin.Context) {
+		c.String(http.StatusOK, CurrentPrincipal(c).Subject)
+	})
+
+	officer := sign(t, jwt.SigningMethodHS256, []byte(secret), claims(nil))
+	employee := sign(t, jwt.SigningMethodHS256, []byte(secret), claims(func(c *Claims) { c.Roles = []string{RoleEmployee} }))
+	tests := []struct {
+		name   string
+		header string
+		want   int
+	}{
+		{"no header", "", http.StatusUnauthorized},
+		{"not a bearer token", "Basic " + officer, http.StatusUnauthorized},
+		{"invalid token", "Bearer x", http.StatusUnauthorized},
+		{"role missing", "Bearer " + employee, http.StatusForbidden},
+		{"role held", "Bearer " + officer, http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/payroll", nil)
+			if tt.header != "" {
+				req.Header.Set("Authorization", tt.header)
+			}
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+			if w.Code != tt.want {
+				t.Errorf("status = %d, want %d", w.Code, tt.want)
+			}
+		})
+	}
+}
+
+func TestFromContext(t *testing.T) {
+	if _, ok := FromContext(t.Context()); ok {
+		t.Error("FromContext() found a principal in an empty context")
+	}
+	p, ok := FromContext(WithPrincipal(t.Context(), Principal{Subject: "budi"}))
+	if !ok || p.Subject != "budi" {
+		t.Errorf("FromContext() = %+v, %v", p, ok)
+	}
+}
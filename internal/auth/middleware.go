@@ -0,0 +1,49 @@
+package auth
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+const principalKey = "auth.principal"
+
+// Authenticate rejects requests without a valid bearer token and stores the
+// caller's Principal on the context.
+func Authenticate(v *Verifier) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		token, ok := strings.CutPrefix(c.GetHeader("Authorization"), "Bearer ")
+		if !ok || token == "" {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": ErrUnauthenticated.Error()})
+			return
+		}
+		p, err := v.Verify(token)
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": ErrUnauthenticated.Error()})
+			return
+		}
+		c.Set(principalKey, p)
+		c.Request = c.Request.WithContext(WithPrincipal(c.Request.Context(), p))
+		c.Next()
+	}
+}
+
+// Require lets a request through only if the caller has one of roles.
+func Require(roles ...string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !CurrentPrincipal(c).HasAny(roles...) {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "not allowed"})
+			return
+		}
+		c.Next()
+	}
+}
+
+// CurrentPrincipal is the caller Authenticate stored; the zero Principal,
+// which has no roles, if it did not run.
+func CurrentPrincipal(c *gin.Context) Principal {
+	p, _ := c.Get(principalKey)
+	principal, _ := p.(Principal)
+	return principal
+}
@@ -1,6 +1,10 @@
 package config
 
 import (
+	"go-payroll-service/internal/auth"
+	"go-payroll-service/internal/payroll/approval"
+	"go-payroll-service/internal/payroll/overtime"
+	"go-payroll-service/internal/payroll/proration"
 	"log"
 	"os"
 
@@ -8,8 +12,23 @@ import (
 )
 
 type Config struct {
-	HTTPPort    string
-	DatabaseURL string
+	HTTPPort        string
+	DatabaseURL     string
+	ProrationMethod string
+	// WorkWeek is the number of working days a week, 5 or 6. It sets the
+	// rest day overtime multipliers and the days leave requests count.
+	WorkWeek int
+	// PayslipTemplate is an optional JSON file branding payslip PDFs.
+	PayslipTemplate string
+	// The company's bank account net salaries are paid from.
+	CompanyName          string
+	CompanyBankCode      string
+	CompanyAccountNumber string
+	// ApprovalPolicy is how many approvals a payroll run needs by its total
+	// net pay.
+	ApprovalPolicy approval.Policy
+	// Auth verifies the bearer tokens API requests carry.
+	Auth auth.Config
 }
 
 func Load() Config {
@@ -22,9 +41,40 @@ func Load() Config {
 		log.Fatal("DATABASE_URL is required")
 	}
 
+	prorationMethod, err := proration.ParseMethod(getEnv("PRORATION_METHOD", proration.CalendarDays))
+	if err != nil {
+		log.Fatalf("PRORATION_METHOD: %v", err)
+	}
+	workWeek, err := overtime.ParseWorkWeek(getEnv("WORK_WEEK", "5"))
+	if err != nil {
+		log.Fatalf("WORK_WEEK: %v", err)
+	}
+	approvalPolicy := approval.DefaultPolicy
+	if v := os.Getenv("RUN_APPROVAL_LEVELS"); v != "" {
+		if approvalPolicy, err = approval.ParsePolicy(v); err != nil {
+			log.Fatalf("RUN_APPROVAL_LEVELS: %v", err)
+		}
+	}
+
 	return Config{
-		HTTPPort:    httpPort,
-		DatabaseURL: dbURL,
+		HTTPPort:        httpPort,
+		DatabaseURL:     dbURL,
+		ProrationMethod: prorationMethod,
+		WorkWeek:        workWeek,
+		PayslipTemplate: os.Getenv("PAYSLIP_TEMPLATE"),
+		ApprovalPolicy:  approvalPolicy,
+
+		CompanyName:          os.Getenv("COMPANY_NAME"),
+		CompanyBankCode:      os.Getenv("COMPANY_BANK_CODE"),
+		CompanyAccountNumber: os.Getenv("COMPANY_ACCOUNT_NUMBER"),
+
+		Auth: auth.Config{
+			Algorithm:     getEnv("JWT_ALGORITHM", auth.AlgorithmHS256),
+			Secret:        os.Getenv("JWT_SECRET"),
+			PublicKeyFile: os.Getenv("JWT_PUBLIC_KEY_FILE"),
+			Issuer:        os.Getenv("JWT_ISSUER"),
+			Audience:      os.Getenv("JWT_AUDIENCE"),
+		},
 	}
 }
 
@@ -0,0 +1,376 @@
+package db
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"embed"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+//go:embed migrations/*.sql
+var migrationFiles embed.FS
+
+// migrationLockKey is the Postgres advisory lock held while migrating, so
+// that two instances starting together do not both apply a migration.
+const migrationLockKey = 7_346_109_215
+
+var (
+	ErrChecksumMismatch = errors.New("applied migration was modified")
+	ErrUnknownMigration = errors.New("applied migration is not in this build")
+	// ErrUnrecordedSchema is returned when the database has tables but no
+	// applied migrations, as when it was set up from the old sql/schema.sql.
+	ErrUnrecordedSchema = errors.New("database has a schema that no migration recorded, run `server migrate baseline`")
+	// ErrIncompleteSchema is returned by Baseline when the database lacks
+	// tables the first migration creates, as when it was set up from an
+	// older sql/schema.sql.
+	ErrIncompleteSchema = errors.New("database does not have the schema of the first migration")
+
+	migrationNameExpr = regexp.MustCompile(`^(\d+)_([a-z0-9_]+)\.(up|down)\.sql$`)
+	createTableExpr   = regexp.MustCompile(`(?i)\bCREATE\s+TABLE\s+(?:IF\s+NOT\s+EXISTS\s+)?([a-z_][a-z0-9_]*)`)
+)
+
+// Migration is one numbered schema change, read from a
+// NNNN_name.up.sql file and its optional NNNN_name.down.sql.
+type Migration struct {
+	Version  int64
+	Name     string
+	Up       string
+	Down     string
+	Checksum string
+}
+
+type MigrationStatus struct {
+	Migration
+	AppliedAt *time.Time
+}
+
+type Migrator struct {
+	db         *sql.DB
+	migrations []Migration
+}
+
+// NewMigrator returns a migrator for the migrations embedded in the binary.
+func NewMigrator(db *sql.DB) (*Migrator, error) {
+	migrations, err := LoadMigrations(migrationFiles, "migrations")
+	if err != nil {
+		return nil, err
+	}
+	return &Migrator{db: db, migrations: migrations}, nil
+}
+
+// LoadMigrations reads the migrations in dir of fsys, ordered by version.
+// The checksum covers the up file, the part that has been applied.
+func LoadMigrations(fsys fs.FS, dir string) ([]Migration, error) {
+	entries, err := fs.ReadDir(fsys, dir)
+	if err != nil {
+		return nil, err
+	}
+
+	byVersion := map[int64]*Migration{}
+	for _, entry := range entries {
+		m := migrationNameExpr.FindStringSubmatch(entry.Name())
+		if entry.IsDir() || m == nil {
+			return nil, fmt.Errorf("migration %s: name is not NNNN_name.up.sql or NNNN_name.down.sql", entry.Name())
+		}
+		version, _ := strconv.ParseInt(m[1], 10, 64)
+		raw, err := fs.ReadFile(fsys, path.Join(dir, entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+
+		mig, ok := byVersion[version]
+		if !ok {
+			mig = &Migration{Version: version, Name: m[2]}
+			byVersion[version] = mig
+		}
+		if mig.Name != m[2] {
+			return nil, fmt.Errorf("migration %d has two names, %s and %s", version, mig.Name, m[2])
+		}
+		if m[3] == "up" {
+			mig.Up = string(raw)
+			sum := sha256.Sum256(raw)
+			mig.Checksum = hex.EncodeToString(sum[:])
+		} else {
+			mig.Down = string(raw)
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, mig := range byVersion {
+		if mig.Checksum == "" {
+			return nil, fmt.Errorf("migration %d_%s has no up file", mig.Version, mig.Name)
+		}
+		migrations = append(migrations, *mig)
+	}
+	slices.SortFunc(migrations, func(a, b Migration) int { return int(a.Version - b.Version) })
+	return migrations, nil
+}
+
+// Up applies every pending migration in order, each in its own transaction,
+// and returns the ones applied.
+func (m *Migrator) Up(ctx context.Context) ([]Migration, error) {
+	var done []Migration
+	err := m.locked(ctx, func(conn *sql.Conn) error {
+		applied, err := m.verify(ctx, conn)
+		if err != nil {
+			return err
+		}
+		if len(applied) == 0 {
+			legacy, err := hasLegacySchema(ctx, conn)
+			if err != nil {
+				return err
+			}
+			if legacy {
+				return ErrUnrecordedSchema
+			}
+		}
+		for _, mig := range m.migrations {
+			if _, ok := applied[mig.Version]; ok {
+				continue
+			}
+			err := inTx(ctx, conn, func(tx *sql.Tx) error {
+				if _, err := tx.ExecContext(ctx, mig.Up); err != nil {
+					return err
+				}
+				_, err := tx.ExecContext(ctx, `
+					INSERT INTO schema_migrations(version, name, checksum, applied_at)
+					VALUES ($1, $2, $3, $4)`, mig.Version, mig.Name, mig.Checksum, time.Now())
+				return err
+			})
+			if err != nil {
+				return fmt.Errorf("migration %d_%s: %w", mig.Version, mig.Name, err)
+			}
+			done = append(done, mig)
+		}
+		return nil
+	})
+	return done, err
+}
+
+// Down reverts the last steps applied migrations, newest first, and returns
+// the ones reverted.
+func (m *Migrator) Down(ctx context.Context, steps int) ([]Migration, error) {
+	var done []Migration
+	err := m.locked(ctx, func(conn *sql.Conn) error {
+		applied, err := m.verify(ctx, conn)
+		if err != nil {
+			return err
+		}
+		for i := len(m.migrations) - 1; i >= 0 && len(done) < steps; i-- {
+			mig := m.migrations[i]
+			if _, ok := applied[mig.Version]; !ok {
+				continue
+			}
+			if mig.Down == "" {
+				return fmt.Errorf("migration %d_%s has no down file", mig.Version, mig.Name)
+			}
+			err := inTx(ctx, conn, func(tx *sql.Tx) error {
+				if _, err := tx.ExecContext(ctx, mig.Down); err != nil {
+					return err
+				}
+				_, err := tx.ExecContext(ctx, `DELETE FROM schema_migrations WHERE version = $1`, mig.Version)
+				return err
+			})
+			if err != nil {
+				return fmt.Errorf("revert migration %d_%s: %w", mig.Version, mig.Name, err)
+			}
+			done = append(done, mig)
+		}
+		return nil
+	})
+	return done, err
+}
+
+// Baseline records the first migration as applied without running it, for
+// a database set up from the last sql/schema.sql, which 0001 reproduces. It
+// refuses with ErrIncompleteSchema unless every table 0001 creates exists,
+// so a database from an older schema.sql is not taken for migrated. The
+// later migrations are left pending for Up.
+func (m *Migrator) Baseline(ctx context.Context) (Migration, error) {
+	if len(m.migrations) == 0 {
+		return Migration{}, errors.New("no migrations in this build")
+	}
+	first := m.migrations[0]
+	err := m.locked(ctx, func(conn *sql.Conn) error {
+		applied, err := m.verify(ctx, conn)
+		if err != nil {
+			return err
+		}
+		if len(applied) > 0 {
+			return errors.New("database already has applied migrations")
+		}
+		legacy, err := hasLegacySchema(ctx, conn)
+		if err != nil {
+			return err
+		}
+		if !legacy {
+			return errors.New("database has no schema to baseline, run `server migrate up`")
+		}
+		missing, err := missingTables(ctx, conn, createdTables(first.Up))
+		if err != nil {
+			return err
+		}
+		if len(missing) > 0 {
+			return fmt.Errorf("%w %d_%s, missing %s", ErrIncompleteSchema, first.Version, first.Name, strings.Join(missing, ", "))
+		}
+		_, err = conn.ExecContext(ctx, `
+			INSERT INTO schema_migrations(version, name, checksum, applied_at)
+			VALUES ($1, $2, $3, $4)`, first.Version, first.Name, first.Checksum, time.Now())
+		return err
+	})
+	return first, err
+}
+
+// Status lists every migration with the time it was applied, if it was.
+func (m *Migrator) Status(ctx context.Context) ([]MigrationStatus, error) {
+	var status []MigrationStatus
+	err := m.locked(ctx, func(conn *sql.Conn) error {
+		applied, err := m.verify(ctx, conn)
+		if err != nil {
+			return err
+		}
+		for _, mig := range m.migrations {
+			s := MigrationStatus{Migration: mig}
+			if at, ok := applied[mig.Version]; ok {
+				s.AppliedAt = &at
+			}
+			status = append(status, s)
+		}
+		return nil
+	})
+	return status, err
+}
+
+// Pending returns the migrations not applied yet, after checking the
+// applied ones against the files.
+func (m *Migrator) Pending(ctx context.Context) ([]Migration, error) {
+	status, err := m.Status(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var pending []Migration
+	for _, s := range status {
+		if s.AppliedAt == nil {
+			pending = append(pending, s.Migration)
+		}
+	}
+	return pending, nil
+}
+
+// locked runs fn on one connection holding the migration lock, after making
+// sure the schema_migrations table exists.
+func (m *Migrator) locked(ctx context.Context, fn func(conn *sql.Conn) error) error {
+	conn, err := m.db.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	if _, err := conn.ExecContext(ctx, `SELECT pg_advisory_lock($1)`, migrationLockKey); err != nil {
+		return fmt.Errorf("acquire migration lock: %w", err)
+	}
+	defer conn.ExecContext(context.WithoutCancel(ctx), `SELECT pg_advisory_unlock($1)`, migrationLockKey)
+
+	_, err = conn.ExecContext(ctx, `
+		CREATE TABLE IF NOT EXISTS schema_migrations
+		(
+		    version    BIGINT PRIMARY KEY,
+		    name       VARCHAR(255) NOT NULL,
+		    checksum   VARCHAR(64)  NOT NULL,
+		    applied_at TIMESTAMP    NOT NULL
+		)`)
+	if err != nil {
+		return err
+	}
+	return fn(conn)
+}
+
+// verify reads the applied migrations and checks each is still in the build
+// with the same content, returning when each was applied.
+func (m *Migrator) verify(ctx context.Context, conn *sql.Conn) (map[int64]time.Time, error) {
+	rows, err := conn.QueryContext(ctx, `SELECT version, name, checksum, applied_at FROM schema_migrations ORDER BY version`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	known := map[int64]Migration{}
+	for _, mig := range m.migrations {
+		known[mig.Version] = mig
+	}
+
+	applied := map[int64]time.Time{}
+	for rows.Next() {
+		var (
+			version        int64
+			name, checksum string
+			at             time.Time
+		)
+		if err := rows.Scan(&version, &name, &checksum, &at); err != nil {
+			return nil, err
+		}
+		mig, ok := known[version]
+		if !ok {
+			return nil, fmt.Errorf("%w: %d_%s", ErrUnknownMigration, version, name)
+		}
+		if mig.Checksum != checksum {
+			return nil, fmt.Errorf("%w: %d_%s", ErrChecksumMismatch, version, name)
+		}
+		applied[version] = at
+	}
+	return applied, rows.Err()
+}
+
+// hasLegacySchema reports whether the tables of the old sql/schema.sql
+// exist.
+func hasLegacySchema(ctx context.Context, conn *sql.Conn) (bool, error) {
+	var exists bool
+	err := conn.QueryRowContext(ctx, `SELECT to_regclass('employees') IS NOT NULL`).Scan(&exists)
+	return exists, err
+}
+
+// createdTables lists the tables the script creates, in order.
+func createdTables(script string) []string {
+	var tables []string
+	for _, m := range createTableExpr.FindAllStringSubmatch(script, -1) {
+		tables = append(tables, strings.ToLower(m[1]))
+	}
+	return tables
+}
+
+// missingTables returns the tables that do not exist.
+func missingTables(ctx context.Context, conn *sql.Conn, tables []string) ([]string, error) {
+	var missing []string
+	for _, t := range tables {
+		var exists bool
+		if err := conn.QueryRowContext(ctx, `SELECT to_regclass($1) IS NOT NULL`, t).Scan(&exists); err != nil {
+			return nil, err
+		}
+		if !exists {
+			missing = append(missing, t)
+		}
+	}
+	return missing, nil
+}
+
+func inTx(ctx context.Context, conn *sql.Conn, fn func(tx *sql.Tx) error) error {
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	if err := fn(tx); err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+	return tx.Commit()
+}
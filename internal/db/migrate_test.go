@@ -0,0 +1,134 @@
+package db
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"slices"
+	"testing"
+	"testing/fstest"
+)
+
+func TestCreatedTables(t *testing.T) {
+	tests := []struct {
+		name   string
+		script string
+		want   []string
+	}{
+		{
+			"tables in order",
+			"CREATE TABLE employees\n(\n    id BIGSERIAL\n);\ncreate table if not exists Payslips (id INT);",
+			[]string{"employees", "payslips"},
+		},
+		{"indexes and views are not tables", "CREATE INDEX idx ON employees(id); CREATE VIEW v AS SELECT 1;", nil},
+		{"altered tables are not created", "ALTER TABLE employees ADD COLUMN note TEXT;", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := createdTables(tt.script); !slices.Equal(got, tt.want) {
+				t.Errorf("createdTables() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCreatedTablesOfFirstMigration(t *testing.T) {
+	migrations, err := LoadMigrations(migrationFiles, "migrations")
+	if err != nil {
+		t.Fatalf("LoadMigrations() error = %v", err)
+	}
+	tables := createdTables(migrations[0].Up)
+	// Baseline must check the tables later versions of schema.sql added,
+	// not just the original employees and payslips.
+	for _, want := range []string{"employees", "payslips", "payslip_lines", "pph21_ter_rates", "bpjs_programs", "gl_mappings"} {
+		if !slices.Contains(tables, want) {
+			t.Errorf("createdTables(0001) = %v, missing %s", tables, want)
+		}
+	}
+}
+
+func TestLoadMigrations(t *testing.T) {
+	fsys := fstest.MapFS{
+		"m/0002_add_note.up.sql":   {Data: []byte("ALTER TABLE t ADD COLUMN note TEXT;")},
+		"m/0002_add_note.down.sql": {Data: []byte("ALTER TABLE t DROP COLUMN note;")},
+		"m/0010_index.up.sql":      {Data: []byte("CREATE INDEX i ON t(note);")},
+		"m/0001_initial.up.sql":    {Data: []byte("CREATE TABLE t (id INT);")},
+	}
+	got, err := LoadMigrations(fsys, "m")
+	if err != nil {
+		t.Fatalf("LoadMigrations() error = %v", err)
+	}
+	var versions []int64
+	for _, m := range got {
+		versions = append(versions, m.Version)
+	}
+	if want := []int64{1, 2, 10}; !slices.Equal(versions, want) {
+		t.Fatalf("LoadMigrations() versions = %v, want %v", versions, want)
+	}
+	if got[1].Name != "add_note" || got[1].Down != "ALTER TABLE t DROP COLUMN note;" || got[0].Down != "" {
+		t.Errorf("LoadMigrations() = %+v", got)
+	}
+	sum := sha256.Sum256([]byte("CREATE TABLE t (id INT);"))
+	if want := hex.EncodeToString(sum[:]); got[0].Checksum != want {
+		t.Errorf("checksum = %s, want %s", got[0].Checksum, want)
+	}
+}
+
+func TestLoadMigrationsChecksum(t *testing.T) {
+	load := func(up, down string) Migration {
+		t.Helper()
+		got, err := LoadMigrations(fstest.MapFS{
+			"m/0001_initial.up.sql":   {Data: []byte(up)},
+			"m/0001_initial.down.sql": {Data: []byte(down)},
+		}, "m")
+		if err != nil {
+			t.Fatalf("LoadMigrations() error = %v", err)
+		}
+		return got[0]
+	}
+	base := load("CREATE TABLE t (id INT);", "DROP TABLE t;")
+	if load("CREATE TABLE t (id INT);", "DROP TABLE IF EXISTS t;").Checksum != base.Checksum {
+		t.Error("changing the down file changed the checksum")
+	}
+	if load("CREATE TABLE t (id BIGINT);", "DROP TABLE t;").Checksum == base.Checksum {
+		t.Error("changing the up file kept the checksum")
+	}
+}
+
+func TestLoadMigrationsErrors(t *testing.T) {
+	tests := []struct {
+		name  string
+		files []string
+	}{
+		{"bad name", []string{"0001_Initial.up.sql"}},
+		{"no version", []string{"initial.up.sql"}},
+		{"not sql", []string{"0001_initial.up.txt"}},
+		{"no up file", []string{"0001_initial.down.sql"}},
+		{"two names", []string{"0001_initial.up.sql", "0001_first.down.sql"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fsys := fstest.MapFS{}
+			for _, f := range tt.files {
+				fsys["m/"+f] = &fstest.MapFile{Data: []byte("SELECT 1;")}
+			}
+			if _, err := LoadMigrations(fsys, "m"); err == nil {
+				t.Error("LoadMigrations() error = nil, want an error")
+			}
+		})
+	}
+}
+
+func TestEmbeddedMigrations(t *testing.T) {
+	migrations, err := LoadMigrations(migrationFiles, "migrations")
+	if err != nil {
+		t.Fatalf("LoadMigrations() error = %v", err)
+	}
+	for i, m := range migrations {
+		if m.Version != int64(i+1) {
+			t.Errorf("migration %d_%s, want version %d", m.Version, m.Name, i+1)
+		}
+		if m.Down == "" {
+			t.Errorf("migration %d_%s has no down file", m.Version, m.Name)
+		}
+	}
+}
@@ -0,0 +1,17 @@
+DROP TABLE bpjs_programs;
+DROP TABLE pph21_ter_rates;
+DROP TABLE pph21_brackets;
+DROP TABLE pph21_ptkp;
+DROP TABLE pph21_settings;
+DROP TABLE gl_mappings;
+DROP TABLE attendance_summaries;
+DROP TABLE employee_bank_accounts;
+DROP TABLE employee_components;
+DROP TABLE pay_components;
+DROP TABLE payslip_lines;
+DROP TABLE payslip_tax_lines;
+DROP TABLE payslips;
+DROP TABLE payroll_period_events;
+DROP TABLE payroll_periods;
+DROP TABLE employee_salaries;
+DROP TABLE employees;
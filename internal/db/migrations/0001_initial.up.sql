@@ -0,0 +1,387 @@
+CREATE TABLE employees
+(
+    id               SERIAL PRIMARY KEY,
+    code             VARCHAR(50) UNIQUE  NOT NULL,
+    full_name        VARCHAR(255)        NOT NULL,
+    email            VARCHAR(255) UNIQUE NOT NULL,
+    ptkp_status      VARCHAR(10)         NOT NULL DEFAULT 'TK/0',
+    npwp             VARCHAR(25)         NOT NULL DEFAULT '',
+    is_active        BOOLEAN             NOT NULL DEFAULT TRUE,
+    hire_date        DATE                NOT NULL,
+    termination_date DATE,
+    created_at       TIMESTAMP           NOT NULL,
+    updated_at       TIMESTAMP           NOT NULL
+);
+
+-- Compensation history. A row applies from effective_from until the next
+-- row of the same employee; the employee's current salary is the latest row
+-- already in effect, and payroll pays each row for the days it covers.
+CREATE TABLE employee_salaries
+(
+    id             SERIAL PRIMARY KEY,
+    employee_id    INTEGER      NOT NULL REFERENCES employees (id) ON DELETE CASCADE,
+    base_salary    BIGINT       NOT NULL,
+    allowance      BIGINT       NOT NULL DEFAULT 0,
+    effective_from DATE         NOT NULL,
+    reason         VARCHAR(255) NOT NULL,
+    approved_by    VARCHAR(255) NOT NULL DEFAULT '',
+    created_at     TIMESTAMP    NOT NULL,
+    UNIQUE (employee_id, effective_from)
+);
+
+-- status moves draft -> open -> locked -> closed; a locked or closed period
+-- can be reopened. Every transition is recorded in payroll_period_events.
+CREATE TABLE payroll_periods
+(
+    id         SERIAL PRIMARY KEY,
+    code       VARCHAR(50) UNIQUE NOT NULL,
+    start_date DATE               NOT NULL,
+    end_date   DATE               NOT NULL,
+    pay_date   DATE               NOT NULL,
+    status     VARCHAR(20)        NOT NULL DEFAULT 'draft',
+    created_at TIMESTAMP          NOT NULL,
+    updated_at TIMESTAMP          NOT NULL,
+    CHECK (end_date >= start_date)
+);
+
+CREATE TABLE payroll_period_events
+(
+    id                SERIAL PRIMARY KEY,
+    payroll_period_id INTEGER     NOT NULL REFERENCES payroll_periods (id),
+    action            VARCHAR(20) NOT NULL,
+    from_status       VARCHAR(20) NOT NULL,
+    to_status         VARCHAR(20) NOT NULL,
+    reason            TEXT        NOT NULL DEFAULT '',
+    created_at        TIMESTAMP   NOT NULL
+);
+
+CREATE TABLE payslips
+(
+    id                SERIAL PRIMARY KEY,
+    employee_id       INTEGER     NOT NULL REFERENCES employees (id),
+    payroll_period_id INTEGER     NOT NULL REFERENCES payroll_periods (id),
+    status            VARCHAR(20) NOT NULL DEFAULT 'draft',
+    taxable_income    BIGINT      NOT NULL DEFAULT 0,
+    income_tax        BIGINT      NOT NULL DEFAULT 0,
+    tax_method        VARCHAR(10) NOT NULL DEFAULT '',
+    proration_method  VARCHAR(20) NOT NULL DEFAULT '',
+    prorated_days     INTEGER     NOT NULL DEFAULT 0,
+    period_days       INTEGER     NOT NULL DEFAULT 0,
+    created_at        TIMESTAMP   NOT NULL,
+    updated_at        TIMESTAMP   NOT NULL,
+    UNIQUE (employee_id, payroll_period_id)
+);
+
+CREATE TABLE payslip_tax_lines
+(
+    id         SERIAL PRIMARY KEY,
+    payslip_id INTEGER      NOT NULL REFERENCES payslips (id) ON DELETE CASCADE,
+    line_no    INTEGER      NOT NULL,
+    code       VARCHAR(50)  NOT NULL,
+    label      VARCHAR(255) NOT NULL,
+    amount     BIGINT       NOT NULL
+);
+
+-- Every money component of a payslip. type is earning, deduction or
+-- employer_contribution; source records what produced the line (salary, tax,
+-- bpjs, adjustment, ...). Payslip totals are always summed from these rows.
+CREATE TABLE payslip_lines
+(
+    id         SERIAL PRIMARY KEY,
+    payslip_id INTEGER      NOT NULL REFERENCES payslips (id) ON DELETE CASCADE,
+    code       VARCHAR(50)  NOT NULL,
+    label      VARCHAR(255) NOT NULL,
+    type       VARCHAR(30)  NOT NULL CHECK (type IN ('earning', 'deduction', 'employer_contribution')),
+    amount     BIGINT       NOT NULL,
+    taxable    BOOLEAN      NOT NULL DEFAULT FALSE,
+    source     VARCHAR(30)  NOT NULL
+);
+
+-- Catalog of recurring pay components. formula is evaluated per employee
+-- and period; it can read employee attributes, attendance and the amounts of
+-- other components by their code.
+CREATE TABLE pay_components
+(
+    id         SERIAL PRIMARY KEY,
+    code       VARCHAR(50) UNIQUE NOT NULL,
+    name       VARCHAR(255)       NOT NULL,
+    type       VARCHAR(30)        NOT NULL CHECK (type IN ('earning', 'deduction')),
+    taxable    BOOLEAN            NOT NULL DEFAULT TRUE,
+    formula    TEXT               NOT NULL,
+    is_active  BOOLEAN            NOT NULL DEFAULT TRUE,
+    created_at TIMESTAMP          NOT NULL,
+    updated_at TIMESTAMP          NOT NULL
+);
+
+CREATE TABLE employee_components
+(
+    id               SERIAL PRIMARY KEY,
+    employee_id      INTEGER   NOT NULL REFERENCES employees (id) ON DELETE CASCADE,
+    component_id     INTEGER   NOT NULL REFERENCES pay_components (id),
+    amount_override  BIGINT,
+    formula_override TEXT,
+    created_at       TIMESTAMP NOT NULL,
+    updated_at       TIMESTAMP NOT NULL,
+    UNIQUE (employee_id, component_id)
+);
+
+-- Where an employee's net salary is paid. bank_code is the bank's 3 digit
+-- Indonesian clearing code.
+CREATE TABLE employee_bank_accounts
+(
+    employee_id    INTEGER PRIMARY KEY REFERENCES employees (id) ON DELETE CASCADE,
+    bank_code      VARCHAR(3)   NOT NULL,
+    account_number VARCHAR(20)  NOT NULL,
+    account_name   VARCHAR(255) NOT NULL,
+    updated_at     TIMESTAMP    NOT NULL
+);
+
+CREATE TABLE attendance_summaries
+(
+    employee_id  INTEGER     NOT NULL REFERENCES employees (id) ON DELETE CASCADE,
+    period_code  VARCHAR(50) NOT NULL,
+    working_days INTEGER     NOT NULL,
+    days_present INTEGER     NOT NULL,
+    updated_at   TIMESTAMP   NOT NULL,
+    PRIMARY KEY (employee_id, period_code)
+);
+
+-- Where payslip lines are posted in the general ledger, by line code (BASIC,
+-- PPH21, a BPJS program or a pay component code). Earnings are debited to
+-- expense_account, deductions credited to payable_account, and employer
+-- contributions debited to expense_account and credited to payable_account.
+-- NET_PAY holds the account net salaries are credited to until paid.
+CREATE TABLE gl_mappings
+(
+    code            VARCHAR(50) PRIMARY KEY,
+    description     VARCHAR(255) NOT NULL,
+    expense_account VARCHAR(50)  NOT NULL DEFAULT '',
+    payable_account VARCHAR(50)  NOT NULL DEFAULT '',
+    cost_center     VARCHAR(50)  NOT NULL DEFAULT '',
+    updated_at      TIMESTAMP    NOT NULL DEFAULT NOW()
+);
+
+INSERT INTO gl_mappings(code, description, expense_account, payable_account)
+VALUES ('BASIC', 'Salary expense', '6100', ''),
+       ('ALLOWANCE', 'Allowance expense', '6110', ''),
+       ('PPH21', 'PPh 21 payable', '', '2130'),
+       ('KES', 'BPJS Kesehatan', '6210', '2140'),
+       ('JHT', 'BPJS Ketenagakerjaan JHT', '6220', '2141'),
+       ('JP', 'BPJS Ketenagakerjaan JP', '6230', '2142'),
+       ('JKK', 'BPJS Ketenagakerjaan JKK', '6240', '2143'),
+       ('JKM', 'BPJS Ketenagakerjaan JKM', '6250', '2144'),
+       ('NET_PAY', 'Net salaries payable', '', '2110');
+
+-- PPh 21 rate tables. Every row carries the first tax year it applies to; a
+-- payroll run uses the latest effective_year that is not after the period's
+-- year, so a regulation change only needs a new set of rows.
+CREATE TABLE pph21_settings
+(
+    effective_year            INTEGER PRIMARY KEY,
+    position_cost_rate_bp     INTEGER NOT NULL,
+    position_cost_monthly_cap BIGINT  NOT NULL,
+    position_cost_annual_cap  BIGINT  NOT NULL,
+    non_npwp_surcharge_bp     INTEGER NOT NULL DEFAULT 0
+);
+
+CREATE TABLE pph21_ptkp
+(
+    effective_year INTEGER     NOT NULL,
+    status         VARCHAR(10) NOT NULL,
+    amount         BIGINT      NOT NULL,
+    ter_category   VARCHAR(1)  NOT NULL,
+    PRIMARY KEY (effective_year, status)
+);
+
+CREATE TABLE pph21_brackets
+(
+    effective_year INTEGER NOT NULL,
+    lower_bound    BIGINT  NOT NULL,
+    upper_bound    BIGINT,
+    rate_bp        INTEGER NOT NULL,
+    PRIMARY KEY (effective_year, lower_bound)
+);
+
+CREATE TABLE pph21_ter_rates
+(
+    effective_year INTEGER    NOT NULL,
+    category       VARCHAR(1) NOT NULL,
+    lower_bound    BIGINT     NOT NULL,
+    upper_bound    BIGINT,
+    rate_bp        INTEGER    NOT NULL,
+    PRIMARY KEY (effective_year, category, lower_bound)
+);
+
+-- 2024 rates: UU HPP brackets, PMK 101/2016 PTKP and the PP 58/2023 TER tables.
+INSERT INTO pph21_settings(effective_year, position_cost_rate_bp, position_cost_monthly_cap, position_cost_annual_cap, non_npwp_surcharge_bp)
+VALUES (2024, 500, 500000, 6000000, 2000);
+
+INSERT INTO pph21_ptkp(effective_year, status, amount, ter_category)
+VALUES (2024, 'TK/0', 54000000, 'A'),
+       (2024, 'TK/1', 58500000, 'A'),
+       (2024, 'TK/2', 63000000, 'B'),
+       (2024, 'TK/3', 67500000, 'B'),
+       (2024, 'K/0', 58500000, 'A'),
+       (2024, 'K/1', 63000000, 'B'),
+       (2024, 'K/2', 67500000, 'B'),
+       (2024, 'K/3', 72000000, 'C');
+
+INSERT INTO pph21_brackets(effective_year, lower_bound, upper_bound, rate_bp)
+VALUES (2024, 0, 60000000, 500),
+       (2024, 60000000, 250000000, 1500),
+       (2024, 250000000, 500000000, 2500),
+       (2024, 500000000, 5000000000, 3000),
+       (2024, 5000000000, NULL, 3500);
+
+INSERT INTO pph21_ter_rates(effective_year, category, lower_bound, upper_bound, rate_bp)
+VALUES (2024, 'A', 0, 5400000, 0),
+       (2024, 'A', 5400000, 5650000, 25),
+       (2024, 'A', 5650000, 5950000, 50),
+       (2024, 'A', 5950000, 6300000, 75),
+       (2024, 'A', 6300000, 6750000, 100),
+       (2024, 'A', 6750000, 7500000, 125),
+       (2024, 'A', 7500000, 8550000, 150),
+       (2024, 'A', 8550000, 9650000, 175),
+       (2024, 'A', 9650000, 10050000, 200),
+       (2024, 'A', 10050000, 10350000, 225),
+       (2024, 'A', 10350000, 10700000, 250),
+       (2024, 'A', 10700000, 11050000, 300),
+       (2024, 'A', 11050000, 11600000, 350),
+       (2024, 'A', 11600000, 12500000, 400),
+       (2024, 'A', 12500000, 13750000, 500),
+       (2024, 'A', 13750000, 15100000, 600),
+       (2024, 'A', 15100000, 16950000, 700),
+       (2024, 'A', 16950000, 19750000, 800),
+       (2024, 'A', 19750000, 24150000, 900),
+       (2024, 'A', 24150000, 26450000, 1000),
+       (2024, 'A', 26450000, 28000000, 1100),
+       (2024, 'A', 28000000, 30050000, 1200),
+       (2024, 'A', 30050000, 32400000, 1300),
+       (2024, 'A', 32400000, 35400000, 1400),
+       (2024, 'A', 35400000, 39100000, 1500),
+       (2024, 'A', 39100000, 43850000, 1600),
+       (2024, 'A', 43850000, 47800000, 1700),
+       (2024, 'A', 47800000, 51400000, 1800),
+       (2024, 'A', 51400000, 56300000, 1900),
+       (2024, 'A', 56300000, 62200000, 2000),
+       (2024, 'A', 62200000, 68600000, 2100),
+       (2024, 'A', 68600000, 77500000, 2200),
+       (2024, 'A', 77500000, 89000000, 2300),
+       (2024, 'A', 89000000, 103000000, 2400),
+       (2024, 'A', 103000000, 125000000, 2500),
+       (2024, 'A', 125000000, 157000000, 2600),
+       (2024, 'A', 157000000, 206000000, 2700),
+       (2024, 'A', 206000000, 337000000, 2800),
+       (2024, 'A', 337000000, 454000000, 2900),
+       (2024, 'A', 454000000, 550000000, 3000),
+       (2024, 'A', 550000000, 695000000, 3100),
+       (2024, 'A', 695000000, 910000000, 3200),
+       (2024, 'A', 910000000, 1400000000, 3300),
+       (2024, 'A', 1400000000, NULL, 3400),
+       (2024, 'B', 0, 6200000, 0),
+       (2024, 'B', 6200000, 6500000, 25),
+       (2024, 'B', 6500000, 6850000, 50),
+       (2024, 'B', 6850000, 7300000, 75),
+       (2024, 'B', 7300000, 9200000, 100),
+       (2024, 'B', 9200000, 10750000, 150),
+       (2024, 'B', 10750000, 11250000, 200),
+       (2024, 'B', 11250000, 11600000, 250),
+       (2024, 'B', 11600000, 12600000, 300),
+       (2024, 'B', 12600000, 13600000, 400),
+       (2024, 'B', 13600000, 14950000, 500),
+       (2024, 'B', 14950000, 16400000, 600),
+       (2024, 'B', 16400000, 18450000, 700),
+       (2024, 'B', 18450000, 21850000, 800),
+       (2024, 'B', 21850000, 26000000, 900),
+       (2024, 'B', 26000000, 27700000, 1000),
+       (2024, 'B', 27700000, 29350000, 1100),
+       (2024, 'B', 29350000, 31450000, 1200),
+       (2024, 'B', 31450000, 33950000, 1300),
+       (2024, 'B', 33950000, 37100000, 1400),
+       (2024, 'B', 37100000, 41100000, 1500),
+       (2024, 'B', 41100000, 45800000, 1600),
+       (2024, 'B', 45800000, 49500000, 1700),
+       (2024, 'B', 49500000, 53800000, 1800),
+       (2024, 'B', 53800000, 58500000, 1900),
+       (2024, 'B', 58500000, 64000000, 2000),
+       (2024, 'B', 64000000, 71000000, 2100),
+       (2024, 'B', 71000000, 80000000, 2200),
+       (2024, 'B', 80000000, 93000000, 2300),
+       (2024, 'B', 93000000, 109000000, 2400),
+       (2024, 'B', 109000000, 129000000, 2500),
+       (2024, 'B', 129000000, 163000000, 2600),
+       (2024, 'B', 163000000, 211000000, 2700),
+       (2024, 'B', 211000000, 374000000, 2800),
+       (2024, 'B', 374000000, 459000000, 2900),
+       (2024, 'B', 459000000, 555000000, 3000),
+       (2024, 'B', 555000000, 704000000, 3100),
+       (2024, 'B', 704000000, 957000000, 3200),
+       (2024, 'B', 957000000, 1405000000, 3300),
+       (2024, 'B', 1405000000, NULL, 3400),
+       (2024, 'C', 0, 6600000, 0),
+       (2024, 'C', 6600000, 6950000, 25),
+       (2024, 'C', 6950000, 7350000, 50),
+       (2024, 'C', 7350000, 7800000, 75),
+       (2024, 'C', 7800000, 8850000, 100),
+       (2024, 'C', 8850000, 9800000, 125),
+       (2024, 'C', 9800000, 10950000, 150),
+       (2024, 'C', 10950000, 11200000, 175),
+       (2024, 'C', 11200000, 12050000, 200),
+       (2024, 'C', 12050000, 12950000, 300),
+       (2024, 'C', 12950000, 14150000, 400),
+       (2024, 'C', 14150000, 15550000, 500),
+       (2024, 'C', 15550000, 17050000, 600),
+       (2024, 'C', 17050000, 19500000, 700),
+       (2024, 'C', 19500000, 22700000, 800),
+       (2024, 'C', 22700000, 26600000, 900),
+       (2024, 'C', 26600000, 28100000, 1000),
+       (2024, 'C', 28100000, 30100000, 1100),
+       (2024, 'C', 30100000, 32600000, 1200),
+       (2024, 'C', 32600000, 35400000, 1300),
+       (2024, 'C', 35400000, 38900000, 1400),
+       (2024, 'C', 38900000, 43000000, 1500),
+       (2024, 'C', 43000000, 47400000, 1600),
+       (2024, 'C', 47400000, 51200000, 1700),
+       (2024, 'C', 51200000, 55800000, 1800),
+       (2024, 'C', 55800000, 60400000, 1900),
+       (2024, 'C', 60400000, 66700000, 2000),
+       (2024, 'C', 66700000, 74500000, 2100),
+       (2024, 'C', 74500000, 83200000, 2200),
+       (2024, 'C', 83200000, 95600000, 2300),
+       (2024, 'C', 95600000, 110000000, 2400),
+       (2024, 'C', 110000000, 134000000, 2500),
+       (2024, 'C', 134000000, 169000000, 2600),
+       (2024, 'C', 169000000, 221000000, 2700),
+       (2024, 'C', 221000000, 390000000, 2800),
+       (2024, 'C', 390000000, 463000000, 2900),
+       (2024, 'C', 463000000, 561000000, 3000),
+       (2024, 'C', 561000000, 709000000, 3100),
+       (2024, 'C', 709000000, 965000000, 3200),
+       (2024, 'C', 965000000, 1419000000, 3300),
+       (2024, 'C', 1419000000, NULL, 3400);
+
+-- BPJS programs. A new row with a later effective_from replaces the previous
+-- rate or wage cap for that program from that date on. wage_cap of NULL means
+-- the whole wage is contributable. employer_taxable marks premiums that count
+-- as taxable income for PPh 21; employee_tax_deductible marks contributions
+-- that reduce it in the annual calculation.
+CREATE TABLE bpjs_programs
+(
+    code                    VARCHAR(20)  NOT NULL,
+    label                   VARCHAR(255) NOT NULL,
+    effective_from          DATE         NOT NULL,
+    employee_rate_bp        INTEGER      NOT NULL DEFAULT 0,
+    employer_rate_bp        INTEGER      NOT NULL DEFAULT 0,
+    wage_cap                BIGINT,
+    employer_taxable        BOOLEAN      NOT NULL DEFAULT FALSE,
+    employee_tax_deductible BOOLEAN      NOT NULL DEFAULT FALSE,
+    PRIMARY KEY (code, effective_from)
+);
+
+-- JKK is seeded at the lowest risk class (0.24%); adjust for the company's class.
+INSERT INTO bpjs_programs(code, label, effective_from, employee_rate_bp, employer_rate_bp, wage_cap, employer_taxable, employee_tax_deductible)
+VALUES ('KES', 'BPJS Kesehatan', '2020-01-01', 100, 400, 12000000, TRUE, FALSE),
+       ('JHT', 'BPJS Ketenagakerjaan JHT', '2015-07-01', 200, 370, NULL, FALSE, TRUE),
+       ('JP', 'BPJS Ketenagakerjaan JP', '2024-03-01', 100, 200, 10042300, FALSE, TRUE),
+       ('JKK', 'BPJS Ketenagakerjaan JKK', '2015-07-01', 0, 24, NULL, TRUE, FALSE),
+       ('JKM', 'BPJS Ketenagakerjaan JKM', '2015-07-01', 0, 30, NULL, TRUE, FALSE);
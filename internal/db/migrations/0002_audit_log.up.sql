@@ -0,0 +1,21 @@
+-- Append-only record of every change made through the API. Each entry's
+-- hash covers its content and the previous entry's hash, so editing or
+-- removing an entry breaks the chain from that point on. changes is JSON,
+-- not JSONB, to keep the exact bytes that were hashed.
+CREATE TABLE audit_log
+(
+    id          BIGSERIAL PRIMARY KEY,
+    occurred_at TIMESTAMP    NOT NULL,
+    actor       VARCHAR(255) NOT NULL,
+    action      VARCHAR(50)  NOT NULL,
+    entity      VARCHAR(50)  NOT NULL,
+    entity_id   VARCHAR(100) NOT NULL,
+    request_id  VARCHAR(100) NOT NULL,
+    changes     JSON         NOT NULL,
+    prev_hash   CHAR(64)     NOT NULL,
+    hash        CHAR(64)     NOT NULL UNIQUE
+);
+
+CREATE INDEX audit_log_entity_idx ON audit_log (entity, entity_id);
+CREATE INDEX audit_log_actor_idx ON audit_log (actor);
+CREATE INDEX audit_log_occurred_at_idx ON audit_log (occurred_at);
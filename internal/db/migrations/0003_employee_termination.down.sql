@@ -0,0 +1,4 @@
+ALTER TABLE employees
+    DROP COLUMN deleted_at,
+    DROP COLUMN final_pay,
+    DROP COLUMN termination_reason;
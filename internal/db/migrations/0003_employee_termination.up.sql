@@ -0,0 +1,7 @@
+-- Employees are no longer deleted, as payslips reference them: deleting
+-- sets deleted_at. final_pay is false when an employee's final pay is
+-- settled outside the regular run of the period they leave.
+ALTER TABLE employees
+    ADD COLUMN termination_reason VARCHAR(255) NOT NULL DEFAULT '',
+    ADD COLUMN final_pay          BOOLEAN      NOT NULL DEFAULT TRUE,
+    ADD COLUMN deleted_at         TIMESTAMP;
@@ -0,0 +1,6 @@
+DROP INDEX employees_full_name_idx;
+DROP INDEX employees_hire_date_idx;
+DROP INDEX employees_department_idx;
+
+ALTER TABLE employees
+    DROP COLUMN department;
@@ -0,0 +1,7 @@
+ALTER TABLE employees
+    ADD COLUMN department VARCHAR(100) NOT NULL DEFAULT '';
+
+-- Listing filters and keyset pagination.
+CREATE INDEX employees_department_idx ON employees (department);
+CREATE INDEX employees_hire_date_idx ON employees (hire_date, id);
+CREATE INDEX employees_full_name_idx ON employees (full_name, id);
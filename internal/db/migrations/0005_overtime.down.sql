@@ -0,0 +1,3 @@
+DELETE FROM gl_mappings WHERE code = 'OVERTIME';
+
+DROP TABLE overtime_entries;
@@ -0,0 +1,21 @@
+-- Overtime worked per employee and day, paid in the period period_code. A
+-- day is paid in a later period when it falls after the cutoff; day_type
+-- picks the statutory multipliers.
+CREATE TABLE overtime_entries
+(
+    id          SERIAL PRIMARY KEY,
+    employee_id INTEGER      NOT NULL REFERENCES employees (id) ON DELETE CASCADE,
+    period_code VARCHAR(50)  NOT NULL,
+    work_date   DATE         NOT NULL,
+    day_type    VARCHAR(20)  NOT NULL CHECK (day_type IN ('weekday', 'rest_day', 'public_holiday')),
+    minutes     INTEGER      NOT NULL CHECK (minutes > 0),
+    note        VARCHAR(255) NOT NULL DEFAULT '',
+    created_at  TIMESTAMP    NOT NULL,
+    updated_at  TIMESTAMP    NOT NULL,
+    UNIQUE (employee_id, work_date)
+);
+
+CREATE INDEX overtime_entries_period_code_idx ON overtime_entries (period_code);
+
+INSERT INTO gl_mappings(code, description, expense_account, payable_account)
+VALUES ('OVERTIME', 'Overtime expense', '6120', '');
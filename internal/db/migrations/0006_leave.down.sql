@@ -0,0 +1,4 @@
+DELETE FROM gl_mappings WHERE code = 'UNPAID_LEAVE';
+
+DROP TABLE leave_requests;
+DROP TABLE leave_types;
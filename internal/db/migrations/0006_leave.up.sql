@@ -0,0 +1,54 @@
+-- Leave types. A type with annual_days gives employees that many days a
+-- calendar year once they have eligible_after_months of service, by month
+-- in the year they become eligible. Up to carry_over_days unused days move
+-- to the next year and lapse carry_over_expiry_months into it, never if 0.
+-- Types without annual_days keep no balance. Days of unpaid types are
+-- deducted from salary.
+CREATE TABLE leave_types
+(
+    id                       SERIAL PRIMARY KEY,
+    code                     VARCHAR(50) UNIQUE NOT NULL,
+    name                     VARCHAR(255)       NOT NULL,
+    paid                     BOOLEAN            NOT NULL DEFAULT TRUE,
+    annual_days              INTEGER            NOT NULL DEFAULT 0 CHECK (annual_days >= 0),
+    eligible_after_months    INTEGER            NOT NULL DEFAULT 0 CHECK (eligible_after_months >= 0),
+    carry_over_days          INTEGER            NOT NULL DEFAULT 0 CHECK (carry_over_days >= 0),
+    carry_over_expiry_months INTEGER            NOT NULL DEFAULT 0 CHECK (carry_over_expiry_months >= 0),
+    is_active                BOOLEAN            NOT NULL DEFAULT TRUE,
+    created_at               TIMESTAMP          NOT NULL,
+    updated_at               TIMESTAMP          NOT NULL
+);
+
+INSERT INTO leave_types(code, name, paid, annual_days, eligible_after_months, carry_over_days,
+                        carry_over_expiry_months, created_at, updated_at)
+VALUES ('ANNUAL', 'Annual leave', TRUE, 12, 12, 6, 6, NOW(), NOW()),
+       ('SICK', 'Sick leave', TRUE, 0, 0, 0, 0, NOW(), NOW()),
+       ('MATERNITY', 'Maternity leave', TRUE, 0, 0, 0, 0, NOW(), NOW()),
+       ('UNPAID', 'Unpaid leave', FALSE, 0, 0, 0, 0, NOW(), NOW());
+
+-- days counts the working days from start_date to end_date, which are in
+-- the same year.
+CREATE TABLE leave_requests
+(
+    id            SERIAL PRIMARY KEY,
+    employee_id   INTEGER      NOT NULL REFERENCES employees (id) ON DELETE CASCADE,
+    leave_type_id INTEGER      NOT NULL REFERENCES leave_types (id),
+    start_date    DATE         NOT NULL,
+    end_date      DATE         NOT NULL CHECK (end_date >= start_date),
+    days          INTEGER      NOT NULL CHECK (days > 0),
+    reason        VARCHAR(255) NOT NULL DEFAULT '',
+    status        VARCHAR(20)  NOT NULL CHECK (status IN ('pending', 'approved', 'rejected', 'cancelled')),
+    requested_by  VARCHAR(255) NOT NULL,
+    decided_by    VARCHAR(255) NOT NULL DEFAULT '',
+    decision_note VARCHAR(255) NOT NULL DEFAULT '',
+    decided_at    TIMESTAMP,
+    created_at    TIMESTAMP    NOT NULL,
+    updated_at    TIMESTAMP    NOT NULL
+);
+
+CREATE INDEX leave_requests_employee_idx ON leave_requests (employee_id, start_date);
+CREATE INDEX leave_requests_status_idx ON leave_requests (status, start_date);
+
+-- Unpaid leave reduces the salary expense, so it is credited back to it.
+INSERT INTO gl_mappings(code, description, expense_account, payable_account)
+VALUES ('UNPAID_LEAVE', 'Unpaid leave', '', '6100');
@@ -0,0 +1,12 @@
+DELETE FROM gl_mappings WHERE code = 'THR';
+
+ALTER TABLE employees
+    DROP COLUMN religion;
+
+DELETE FROM payslips WHERE run_type <> 'regular';
+ALTER TABLE payslips
+    DROP CONSTRAINT payslips_employee_period_run_key;
+ALTER TABLE payslips
+    ADD CONSTRAINT payslips_employee_id_payroll_period_id_key UNIQUE (employee_id, payroll_period_id);
+ALTER TABLE payslips
+    DROP COLUMN run_type;
@@ -0,0 +1,16 @@
+-- A period holds one payslip per employee and run type: the regular run
+-- and off-cycle runs such as THR, which are paid and taxed in the same
+-- month.
+ALTER TABLE payslips
+    ADD COLUMN run_type VARCHAR(20) NOT NULL DEFAULT 'regular';
+ALTER TABLE payslips
+    DROP CONSTRAINT payslips_employee_id_payroll_period_id_key;
+ALTER TABLE payslips
+    ADD CONSTRAINT payslips_employee_period_run_key UNIQUE (employee_id, payroll_period_id, run_type);
+
+ALTER TABLE employees
+    ADD COLUMN religion VARCHAR(20) NOT NULL DEFAULT ''
+        CHECK (religion IN ('', 'islam', 'protestant', 'catholic', 'hindu', 'buddhist', 'confucian'));
+
+INSERT INTO gl_mappings(code, description, expense_account, payable_account)
+VALUES ('THR', 'THR expense', '6130', '');
@@ -0,0 +1,7 @@
+DELETE FROM gl_mappings WHERE code = 'LOAN';
+
+DELETE FROM payslip_lines WHERE loan_id IS NOT NULL;
+ALTER TABLE payslip_lines
+    DROP COLUMN loan_id;
+
+DROP TABLE loans;
@@ -0,0 +1,28 @@
+-- Loans and salary advances (kasbon) repaid by salary deductions. The
+-- payslip lines that deduct an installment reference the loan, so what is
+-- left to repay is worked out from the payslips. An early payoff settles
+-- the rest outside payroll from payoff_period_code on.
+CREATE TABLE loans
+(
+    id                 SERIAL PRIMARY KEY,
+    employee_id        INTEGER      NOT NULL REFERENCES employees (id) ON DELETE CASCADE,
+    kind               VARCHAR(20)  NOT NULL CHECK (kind IN ('kasbon', 'loan')),
+    principal          BIGINT       NOT NULL CHECK (principal > 0),
+    interest_rate_bp   BIGINT       NOT NULL DEFAULT 0 CHECK (interest_rate_bp >= 0),
+    installments       INTEGER      NOT NULL CHECK (installments > 0),
+    start_period_code  VARCHAR(50)  NOT NULL,
+    payoff_period_code VARCHAR(50),
+    note               VARCHAR(255) NOT NULL DEFAULT '',
+    created_at         TIMESTAMP    NOT NULL,
+    updated_at         TIMESTAMP    NOT NULL
+);
+
+CREATE INDEX loans_employee_id_idx ON loans (employee_id);
+
+ALTER TABLE payslip_lines
+    ADD COLUMN loan_id INTEGER REFERENCES loans (id);
+
+CREATE INDEX payslip_lines_loan_id_idx ON payslip_lines (loan_id);
+
+INSERT INTO gl_mappings(code, description, expense_account, payable_account)
+VALUES ('LOAN', 'Employee loan repayments', '', '1140');
@@ -0,0 +1,2 @@
+DROP TABLE payroll_run_events;
+DROP TABLE payroll_runs;
@@ -0,0 +1,47 @@
+-- Maker-checker approval of payroll runs. Each run type of a period is a
+-- run that starts as a draft when generated; it is submitted for review and
+-- approved by required_levels approvers, or rejected back for changes.
+-- Regenerating a run that changed its payslips returns it to draft. Every
+-- step is recorded in payroll_run_events, by review round.
+CREATE TABLE payroll_runs
+(
+    id                SERIAL PRIMARY KEY,
+    payroll_period_id INTEGER      NOT NULL REFERENCES payroll_periods (id),
+    run_type          VARCHAR(20)  NOT NULL,
+    status            VARCHAR(20)  NOT NULL DEFAULT 'draft'
+        CHECK (status IN ('draft', 'submitted', 'approved', 'rejected')),
+    round             INTEGER      NOT NULL DEFAULT 0,
+    total_net         BIGINT       NOT NULL DEFAULT 0,
+    required_levels   INTEGER      NOT NULL DEFAULT 0,
+    generated_by      VARCHAR(255) NOT NULL DEFAULT '',
+    submitted_by      VARCHAR(255) NOT NULL DEFAULT '',
+    created_at        TIMESTAMP    NOT NULL,
+    updated_at        TIMESTAMP    NOT NULL,
+    UNIQUE (payroll_period_id, run_type)
+);
+
+CREATE TABLE payroll_run_events
+(
+    id             SERIAL PRIMARY KEY,
+    payroll_run_id INTEGER      NOT NULL REFERENCES payroll_runs (id) ON DELETE CASCADE,
+    round          INTEGER      NOT NULL,
+    action         VARCHAR(20)  NOT NULL,
+    from_status    VARCHAR(20)  NOT NULL,
+    to_status      VARCHAR(20)  NOT NULL,
+    actor          VARCHAR(255) NOT NULL,
+    comment        TEXT         NOT NULL DEFAULT '',
+    created_at     TIMESTAMP    NOT NULL
+);
+
+CREATE INDEX payroll_run_events_run_id_idx ON payroll_run_events (payroll_run_id);
+
+-- Runs of periods already locked or closed were paid before approvals
+-- existed and count as approved; the others have to be reviewed.
+INSERT INTO payroll_runs(payroll_period_id, run_type, status, created_at, updated_at)
+SELECT DISTINCT ps.payroll_period_id,
+       ps.run_type,
+       CASE WHEN pp.status IN ('locked', 'closed') THEN 'approved' ELSE 'draft' END,
+       NOW(),
+       NOW()
+FROM payslips ps
+JOIN payroll_periods pp ON pp.id = ps.payroll_period_id;
@@ -0,0 +1,61 @@
+// Package approval decides how many approvals a payroll run needs before it
+// may be paid, by the run's total net pay.
+package approval
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Threshold requires Levels approvals of runs paying From or more.
+type Threshold struct {
+	From   int64
+	Levels int
+}
+
+// Policy is a list of thresholds by ascending From, the first from zero.
+type Policy []Threshold
+
+// DefaultPolicy needs one approval of every run.
+var DefaultPolicy = Policy{{From: 0, Levels: 1}}
+
+// ParsePolicy reads a policy written as comma separated from:levels pairs,
+// e.g. "0:1,100000000:2,500000000:3".
+func ParsePolicy(s string) (Policy, error) {
+	var p Policy
+	for _, part := range strings.Split(s, ",") {
+		from, levels, ok := strings.Cut(strings.TrimSpace(part), ":")
+		if !ok {
+			return nil, fmt.Errorf("threshold %q is not from:levels", part)
+		}
+		var t Threshold
+		var err error
+		if t.From, err = strconv.ParseInt(from, 10, 64); err != nil || t.From < 0 {
+			return nil, fmt.Errorf("threshold %q: amount must be a number of at least 0", part)
+		}
+		if t.Levels, err = strconv.Atoi(levels); err != nil || t.Levels < 1 {
+			return nil, fmt.Errorf("threshold %q: levels must be a number of at least 1", part)
+		}
+		if len(p) == 0 && t.From != 0 {
+			return nil, fmt.Errorf("the first threshold must start at 0, got %d", t.From)
+		}
+		if len(p) > 0 && t.From <= p[len(p)-1].From {
+			return nil, fmt.Errorf("thresholds must be in ascending order, %d follows %d", t.From, p[len(p)-1].From)
+		}
+		p = append(p, t)
+	}
+	return p, nil
+}
+
+// Levels is how many approvals a run paying total needs.
+func (p Policy) Levels(total int64) int {
+	levels := 1
+	for _, t := range p {
+		if total < t.From {
+			break
+		}
+		levels = t.Levels
+	}
+	return levels
+}
@@ -0,0 +1,62 @@
+package approval
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestParsePolicy(t *testing.T) {
+	tests := []struct {
+		s       string
+		want    Policy
+		wantErr bool
+	}{
+		{s: "0:1", want: Policy{{0, 1}}},
+		{s: "0:1,100000000:2,500000000:3", want: Policy{{0, 1}, {100000000, 2}, {500000000, 3}}},
+		{s: " 0:1 , 100000000:2 ", want: Policy{{0, 1}, {100000000, 2}}},
+		{s: "", wantErr: true},
+		{s: "0", wantErr: true},
+		{s: "0:1,", wantErr: true},
+		{s: "100:1", wantErr: true},
+		{s: "-1:1", wantErr: true},
+		{s: "0:0", wantErr: true},
+		{s: "0:x", wantErr: true},
+		{s: "x:1", wantErr: true},
+		{s: "0:1,500:2,500:3", wantErr: true},
+		{s: "0:1,500:2,100:3", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.s, func(t *testing.T) {
+			got, err := ParsePolicy(tt.s)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParsePolicy() error = %v, want error %v", err, tt.wantErr)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("ParsePolicy() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLevels(t *testing.T) {
+	p := Policy{{0, 1}, {100000000, 2}, {500000000, 3}}
+	tests := []struct {
+		total int64
+		want  int
+	}{
+		{0, 1},
+		{99999999, 1},
+		{100000000, 2},
+		{499999999, 2},
+		{500000000, 3},
+		{9000000000, 3},
+	}
+	for _, tt := range tests {
+		if got := p.Levels(tt.total); got != tt.want {
+			t.Errorf("Levels(%d) = %d, want %d", tt.total, got, tt.want)
+		}
+	}
+	if got := DefaultPolicy.Levels(9000000000); got != 1 {
+		t.Errorf("DefaultPolicy.Levels() = %d, want 1", got)
+	}
+}
@@ -0,0 +1,153 @@
+// Package audit builds the entries of the hash-chained audit log: the field
+// diff of a change, the hash linking an entry to the one before it, and the
+// check that a chain is intact.
+package audit
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"go-payroll-service/internal/payroll/model/domain"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// GenesisHash is the previous hash of the first entry.
+var GenesisHash = strings.Repeat("0", 64)
+
+// Change is a field's value before and after; Before is null for a field
+// that was created and After for one that was deleted.
+type Change struct {
+	Before any `json:"before"`
+	After  any `json:"after"`
+}
+
+// Diff returns the fields that differ between before and after as a JSON
+// object of Changes keyed by column name. Either may be nil, for creates
+// and deletes. Structs are compared by their db tagged fields, leaving out
+// the bookkeeping created_at and updated_at; anything else is recorded
+// whole under "value".
+func Diff(before, after any) (json.RawMessage, error) {
+	b, a := fields(before), fields(after)
+	changes := map[string]Change{}
+	for name, v := range b {
+		if w, ok := a[name]; !ok || !reflect.DeepEqual(v, w) {
+			changes[name] = Change{Before: v, After: a[name]}
+		}
+	}
+	for name, w := range a {
+		if _, ok := b[name]; !ok {
+			changes[name] = Change{After: w}
+		}
+	}
+	// Maps marshal with sorted keys, so equal diffs give equal bytes.
+	return json.Marshal(changes)
+}
+
+func fields(v any) map[string]any {
+	out := map[string]any{}
+	rv := reflect.ValueOf(v)
+	for rv.Kind() == reflect.Pointer {
+		if rv.IsNil() {
+			return out
+		}
+		rv = rv.Elem()
+	}
+	if !rv.IsValid() {
+		return out
+	}
+	if rv.Kind() != reflect.Struct {
+		out["value"] = normalize(rv.Interface())
+		return out
+	}
+	rt := rv.Type()
+	for i := range rt.NumField() {
+		name := rt.Field(i).Tag.Get("db")
+		if name == "" || name == "created_at" || name == "updated_at" {
+			continue
+		}
+		out[name] = normalize(rv.Field(i).Interface())
+	}
+	if len(out) == 0 {
+		out["value"] = normalize(rv.Interface())
+	}
+	return out
+}
+
+// normalize turns v into its JSON form, so values compare the way they are
+// stored.
+func normalize(v any) any {
+	raw, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	var out any
+	_ = json.Unmarshal(raw, &out)
+	return out
+}
+
+// Hash is the entry's hash given the hash of the entry before it. It covers
+// every field but the ID and the hashes themselves.
+func Hash(prevHash string, e domain.AuditEntry) string {
+	h := sha256.New()
+	for _, part := range []string{
+		prevHash,
+		e.OccurredAt.UTC().Format(time.RFC3339Nano),
+		e.Actor,
+		e.Action,
+		e.Entity,
+		e.EntityID,
+		e.RequestID,
+		string(e.Changes),
+	} {
+		// Length prefixes keep the boundaries between fields unambiguous.
+		fmt.Fprintf(h, "%d:%s;", len(part), part)
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+var ErrBrokenChain = errors.New("audit chain is broken")
+
+// ChainError names the first entry where the chain does not hold.
+type ChainError struct {
+	EntryID int64
+	Reason  string
+}
+
+func (e *ChainError) Error() string {
+	return fmt.Sprintf("%s at entry %d: %s", ErrBrokenChain, e.EntryID, e.Reason)
+}
+
+func (e *ChainError) Unwrap() error { return ErrBrokenChain }
+
+// Verify checks entries, in the order they were appended, starting from the
+// genesis hash: each must link to the one before it and hash to its Hash.
+func Verify(entries []domain.AuditEntry) error {
+	prev := GenesisHash
+	for _, e := range entries {
+		if e.PrevHash != prev {
+			return &ChainError{EntryID: e.ID, Reason: "does not link to the previous entry"}
+		}
+		if Hash(prev, e) != e.Hash {
+			return &ChainError{EntryID: e.ID, Reason: "content does not match its hash"}
+		}
+		prev = e.Hash
+	}
+	return nil
+}
+
+type requestIDKey struct{}
+
+// WithRequestID returns ctx carrying the ID of the request being served.
+func WithRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, id)
+}
+
+func RequestID(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey{}).(string)
+	return id
+}
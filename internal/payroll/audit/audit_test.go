@@ -0,0 +1,149 @@
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"go-payroll-service/internal/payroll/model/domain"
+	"testing"
+	"time"
+)
+
+// chain returns three entries linked from the genesis hash.
+func chain() []domain.AuditEntry {
+	at := time.Date(2024, time.June, 25, 9, 0, 0, 0, time.UTC)
+	entries := []domain.AuditEntry{
+		{ID: 1, OccurredAt: at, Actor: "siti", Action: "create", Entity: "employee", EntityID: "7", Changes: json.RawMessage(`{"name":{"before":null,"after":"Budi"}}`)},
+		{ID: 2, OccurredAt: at.Add(time.Minute), Actor: "siti", Action: "update", Entity: "employee", EntityID: "7", RequestID: "req-1", Changes: json.RawMessage(`{"name":{"before":"Budi","after":"Budi S."}}`)},
+		{ID: 3, OccurredAt: at.Add(time.Hour), Actor: "andi", Action: "approve", Entity: "payroll_run", EntityID: "12", Changes: json.RawMessage(`{}`)},
+	}
+	prev := GenesisHash
+	for i := range entries {
+		entries[i].PrevHash = prev
+		entries[i].Hash = Hash(prev, entries[i])
+		prev = entries[i].Hash
+	}
+	return entries
+}
+
+func TestVerify(t *testing.T) {
+	tests := []struct {
+		name   string
+		tamper func(e []domain.AuditEntry) []domain.AuditEntry
+		wantID int64 // zero when the chain holds
+	}{
+		{"intact", func(e []domain.AuditEntry) []domain.AuditEntry { return e }, 0},
+		{"empty", func(e []domain.AuditEntry) []domain.AuditEntry { return nil }, 0},
+		{"actor changed", func(e []domain.AuditEntry) []domain.AuditEntry { e[1].Actor = "andi"; return e }, 2},
+		{"changes edited", func(e []domain.AuditEntry) []domain.AuditEntry {
+			e[0].Changes = json.RawMessage(`{"name":{"before":null,"after":"Budi X"}}`)
+			return e
+		}, 1},
+		{"time moved", func(e []domain.AuditEntry) []domain.AuditEntry {
+			e[2].OccurredAt = e[2].OccurredAt.Add(time.Second)
+			return e
+		}, 3},
+		{"request ID cleared", func(e []domain.AuditEntry) []domain.AuditEntry { e[1].RequestID = ""; return e }, 2},
+		{"entry deleted", func(e []domain.AuditEntry) []domain.AuditEntry { return append(e[:1], e[2:]...) }, 3},
+		{"entries swapped", func(e []domain.AuditEntry) []domain.AuditEntry { e[1], e[2] = e[2], e[1]; return e }, 3},
+		{"first entry dropped", func(e []domain.AuditEntry) []domain.AuditEntry { return e[1:] }, 2},
+		{
+			// Rehashing an edited entry hides the edit from that entry but
+			// not from the one after it.
+			"edited entry rehashed",
+			func(e []domain.AuditEntry) []domain.AuditEntry {
+				e[1].Actor = "andi"
+				e[1].Hash = Hash(e[1].PrevHash, e[1])
+				return e
+			},
+			3,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Verify(tt.tamper(chain()))
+			if tt.wantID == 0 {
+				if err != nil {
+					t.Errorf("Verify() error = %v, want nil", err)
+				}
+				return
+			}
+			var cerr *ChainError
+			if !errors.As(err, &cerr) || !errors.Is(err, ErrBrokenChain) {
+				t.Fatalf("Verify() error = %v, want a *ChainError", err)
+			}
+			if cerr.EntryID != tt.wantID {
+				t.Errorf("Verify() broken at entry %d, want %d", cerr.EntryID, tt.wantID)
+			}
+		})
+	}
+}
+
+func TestHash(t *testing.T) {
+	e := chain()[0]
+	if Hash(GenesisHash, e) != e.Hash {
+		t.Error("Hash() is not deterministic")
+	}
+	// The ID and the hashes are not covered.
+	moved := e
+	moved.ID, moved.PrevHash, moved.Hash = 99, "x", "y"
+	if Hash(GenesisHash, moved) != e.Hash {
+		t.Error("Hash() depends on the ID or the stored hashes")
+	}
+	local := e
+	local.OccurredAt = e.OccurredAt.In(time.FixedZone("WIB", 7*3600))
+	if Hash(GenesisHash, local) != e.Hash {
+		t.Error("Hash() depends on the time zone of OccurredAt")
+	}
+	// Moving text across a field boundary must change the hash.
+	a, b := e, e
+	a.Actor, a.Action = "siti", "create"
+	b.Actor, b.Action = "sitic", "reate"
+	if Hash(GenesisHash, a) == Hash(GenesisHash, b) {
+		t.Error("Hash() is ambiguous across field boundaries")
+	}
+	if Hash(GenesisHash, e) == Hash(e.Hash, e) {
+		t.Error("Hash() ignores the previous hash")
+	}
+}
+
+func TestDiff(t *testing.T) {
+	type row struct {
+		ID        int64     `db:"id"`
+		Name      string    `db:"name"`
+		Note      *string   `db:"note"`
+		UpdatedAt time.Time `db:"updated_at"`
+	}
+	note := "probation"
+	tests := []struct {
+		name          string
+		before, after any
+		want          string
+	}{
+		{"create", nil, &row{ID: 1, Name: "Budi"}, `{"id":{"before":null,"after":1},"name":{"before":null,"after":"Budi"},"note":{"before":null,"after":null}}`},
+		{"delete", row{ID: 1, Name: "Budi"}, nil, `{"id":{"before":1,"after":null},"name":{"before":"Budi","after":null},"note":{"before":null,"after":null}}`},
+		{"update", row{ID: 1, Name: "Budi"}, row{ID: 1, Name: "Budi", Note: &note, UpdatedAt: time.Now()}, `{"note":{"before":null,"after":"probation"}}`},
+		{"unchanged", row{ID: 1, UpdatedAt: time.Now()}, row{ID: 1}, `{}`},
+		{"plain value", "draft", "submitted", `{"value":{"before":"draft","after":"submitted"}}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Diff(tt.before, tt.after)
+			if err != nil {
+				t.Fatalf("Diff() error = %v", err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("Diff() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRequestID(t *testing.T) {
+	if got := RequestID(context.Background()); got != "" {
+		t.Errorf("RequestID() = %q, want empty", got)
+	}
+	if got := RequestID(WithRequestID(context.Background(), "req-1")); got != "req-1" {
+		t.Errorf("RequestID() = %q, want req-1", got)
+	}
+}
@@ -0,0 +1,38 @@
+package bpjs
+
+import "go-payroll-service/internal/payroll/model/domain"
+
+// Contributions returns the employee and employer lines for every program
+// with a non-zero rate. The wage is capped per program before the rate is
+// applied.
+func Contributions(programs []domain.BPJSProgram, wage int64) []domain.PayslipLine {
+	var lines []domain.PayslipLine
+	for _, p := range programs {
+		base := wage
+		if p.WageCap > 0 && base > p.WageCap {
+			base = p.WageCap
+		}
+
+		if p.EmployeeRateBP > 0 {
+			lines = append(lines, domain.PayslipLine{
+				Code:    p.Code,
+				Label:   p.Label,
+				Type:    domain.LineTypeDeduction,
+				Amount:  base * p.EmployeeRateBP / 10000,
+				Taxable: p.EmployeeTaxDeductible,
+				Source:  domain.LineSourceBPJS,
+			})
+		}
+		if p.EmployerRateBP > 0 {
+			lines = append(lines, domain.PayslipLine{
+				Code:    p.Code,
+				Label:   p.Label,
+				Type:    domain.LineTypeEmployerContribution,
+				Amount:  base * p.EmployerRateBP / 10000,
+				Taxable: p.EmployerTaxable,
+				Source:  domain.LineSourceBPJS,
+			})
+		}
+	}
+	return lines
+}
@@ -0,0 +1,85 @@
+package bpjs
+
+import (
+	"go-payroll-service/internal/payroll/model/domain"
+	"slices"
+	"testing"
+)
+
+// programs are the rates and caps seeded by the initial migration.
+var programs = []domain.BPJSProgram{
+	{Code: "KES", Label: "BPJS Kesehatan", EmployeeRateBP: 100, EmployerRateBP: 400, WageCap: 12000000, EmployerTaxable: true},
+	{Code: "JHT", Label: "BPJS Ketenagakerjaan JHT", EmployeeRateBP: 200, EmployerRateBP: 370, EmployeeTaxDeductible: true},
+	{Code: "JP", Label: "BPJS Ketenagakerjaan JP", EmployeeRateBP: 100, EmployerRateBP: 200, WageCap: 10042300, EmployeeTaxDeductible: true},
+	{Code: "JKK", Label: "BPJS Ketenagakerjaan JKK", EmployerRateBP: 24, EmployerTaxable: true},
+	{Code: "JKM", Label: "BPJS Ketenagakerjaan JKM", EmployerRateBP: 30, EmployerTaxable: true},
+}
+
+// contribution is the part of a line the tests check.
+type contribution struct {
+	code    string
+	typ     string
+	amount  int64
+	taxable bool
+}
+
+func TestContributions(t *testing.T) {
+	const (
+		deduction = domain.LineTypeDeduction
+		employer  = domain.LineTypeEmployerContribution
+	)
+	tests := []struct {
+		name string
+		wage int64
+		want []contribution
+	}{
+		{
+			"below the caps",
+			5000000,
+			[]contribution{
+				{"KES", deduction, 50000, false},
+				{"KES", employer, 200000, true},
+				{"JHT", deduction, 100000, true},
+				{"JHT", employer, 185000, false},
+				{"JP", deduction, 50000, true},
+				{"JP", employer, 100000, false},
+				{"JKK", employer, 12000, true},
+				{"JKM", employer, 15000, true},
+			},
+		},
+		{
+			"above the caps",
+			15000000,
+			[]contribution{
+				{"KES", deduction, 120000, false},
+				{"KES", employer, 480000, true},
+				{"JHT", deduction, 300000, true},
+				{"JHT", employer, 555000, false},
+				{"JP", deduction, 100423, true},
+				{"JP", employer, 200846, false},
+				{"JKK", employer, 36000, true},
+				{"JKM", employer, 45000, true},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []contribution
+			for _, l := range Contributions(programs, tt.wage) {
+				if l.Source != domain.LineSourceBPJS {
+					t.Errorf("%s line source = %s, want %s", l.Code, l.Source, domain.LineSourceBPJS)
+				}
+				got = append(got, contribution{l.Code, l.Type, l.Amount, l.Taxable})
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("Contributions() =\n%v\nwant\n%v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestContributionsNoRates(t *testing.T) {
+	if got := Contributions([]domain.BPJSProgram{{Code: "JKP"}}, 5000000); len(got) != 0 {
+		t.Errorf("Contributions() = %v, want no lines", got)
+	}
+}
@@ -0,0 +1,191 @@
+package controller
+
+import (
+	"errors"
+	"go-payroll-service/internal/auth"
+	"go-payroll-service/internal/payroll/model/domain"
+	"go-payroll-service/internal/payroll/model/request"
+	"go-payroll-service/internal/payroll/model/response"
+	"go-payroll-service/internal/payroll/service"
+	"go-payroll-service/internal/payroll/util"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+const componentNotFound = "component not found"
+
+type ComponentController struct {
+	svc service.ComponentService
+}
+
+func NewComponentController(svc service.ComponentService) *ComponentController {
+	return &ComponentController{svc: svc}
+}
+
+func (h *ComponentController) RegisterRoutes(rg *gin.RouterGroup) {
+	r := rg.Group("/components")
+	r.GET("", auth.Require(auth.RoleHRAdmin, auth.RolePayrollOfficer), h.List)
+	r.POST("", auth.Require(auth.RolePayrollOfficer), h.Create)
+	r.GET("/:id", auth.Require(auth.RoleHRAdmin, auth.RolePayrollOfficer), h.GetById)
+	r.PUT("/:id", auth.Require(auth.RolePayrollOfficer), h.UpdateById)
+
+	e := rg.Group("/employees/:id/components")
+	e.GET("", auth.Require(auth.RoleHRAdmin, auth.RolePayrollOfficer), h.ListEmployeeComponents)
+	e.PUT("/:componentId", auth.Require(auth.RoleHRAdmin), h.AssignEmployeeComponent)
+	e.DELETE("/:componentId", auth.Require(auth.RoleHRAdmin), h.UnassignEmployeeComponent)
+}
+
+func (h *ComponentController) List(c *gin.Context) {
+	list, err := h.svc.List(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to list components"})
+		return
+	}
+
+	resp := response.ComponentListResponse{}
+	for _, comp := range list {
+		resp = append(resp, toComponentResponse(comp))
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+func (h *ComponentController) Create(c *gin.Context) {
+	var req request.CreateComponentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	comp, err := h.svc.Create(c.Request.Context(), req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, toComponentResponse(comp))
+}
+
+func (h *ComponentController) GetById(c *gin.Context) {
+	id, _ := strconv.ParseInt(c.Param("id"), 10, 64)
+	comp, err := h.svc.GetByID(c.Request.Context(), id)
+	if err != nil {
+		if errors.Is(err, util.ErrNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": componentNotFound})
+			return
+		}
+		c.JSON(http.StatusBadRequest, gin.H{"error": "failed to fetch component"})
+		return
+	}
+	c.JSON(http.StatusOK, toComponentResponse(comp))
+}
+
+func (h *ComponentController) UpdateById(c *gin.Context) {
+	id, _ := strconv.ParseInt(c.Param("id"), 10, 64)
+
+	var req request.UpdateComponentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	comp, err := h.svc.Update(c.Request.Context(), id, req)
+	if err != nil {
+		switch {
+		case errors.Is(err, util.ErrNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": componentNotFound})
+		case errors.Is(err, util.ErrInvalid):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusBadRequest, gin.H{"error": "failed to update component"})
+		}
+		return
+	}
+	c.JSON(http.StatusOK, toComponentResponse(comp))
+}
+
+func (h *ComponentController) ListEmployeeComponents(c *gin.Context) {
+	employeeID, _ := strconv.ParseInt(c.Param("id"), 10, 64)
+
+	list, err := h.svc.ListEmployeeComponents(c.Request.Context(), employeeID)
+	if err != nil {
+		if errors.Is(err, util.ErrNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": employeeNotFound})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to list employee components"})
+		return
+	}
+
+	resp := response.EmployeeComponentListResponse{}
+	for _, ec := range list {
+		resp = append(resp, toEmployeeComponentResponse(ec))
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+func (h *ComponentController) AssignEmployeeComponent(c *gin.Context) {
+	employeeID, _ := strconv.ParseInt(c.Param("id"), 10, 64)
+	componentID, _ := strconv.ParseInt(c.Param("componentId"), 10, 64)
+
+	var req request.AssignComponentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ec, err := h.svc.AssignEmployeeComponent(c.Request.Context(), employeeID, componentID, req)
+	if err != nil {
+		switch {
+		case errors.Is(err, util.ErrNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "employee or component not found"})
+		case errors.Is(err, util.ErrInvalid):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusBadRequest, gin.H{"error": "failed to assign component"})
+		}
+		return
+	}
+	c.JSON(http.StatusOK, toEmployeeComponentResponse(ec))
+}
+
+func (h *ComponentController) UnassignEmployeeComponent(c *gin.Context) {
+	employeeID, _ := strconv.ParseInt(c.Param("id"), 10, 64)
+	componentID, _ := strconv.ParseInt(c.Param("componentId"), 10, 64)
+
+	err := h.svc.UnassignEmployeeComponent(c.Request.Context(), employeeID, componentID)
+	if err != nil {
+		if errors.Is(err, util.ErrNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "component not assigned to employee"})
+			return
+		}
+		c.JSON(http.StatusBadRequest, gin.H{"error": "failed to unassign component"})
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+func toComponentResponse(comp domain.PayComponent) response.ComponentResponse {
+	return response.ComponentResponse{
+		ID:       comp.ID,
+		Code:     comp.Code,
+		Name:     comp.Name,
+		Type:     comp.Type,
+		Taxable:  comp.Taxable,
+		Formula:  comp.Formula,
+		IsActive: comp.IsActive,
+		CreateAt: comp.CreatedAt,
+		UpdateAt: comp.UpdatedAt,
+	}
+}
+
+func toEmployeeComponentResponse(ec domain.EmployeeComponent) response.EmployeeComponentResponse {
+	return response.EmployeeComponentResponse{
+		EmployeeID:      ec.EmployeeID,
+		Component:       toComponentResponse(ec.Component),
+		AmountOverride:  ec.AmountOverride,
+		FormulaOverride: ec.FormulaOverride,
+		Formula:         ec.Formula(),
+		UpdateAt:        ec.UpdatedAt,
+	}
+}
@@ -0,0 +1,112 @@
+package controller
+
+import (
+	"bytes"
+	"errors"
+	"go-payroll-service/internal/auth"
+	"go-payroll-service/internal/payroll/disbursement"
+	"go-payroll-service/internal/payroll/model/domain"
+	"go-payroll-service/internal/payroll/model/request"
+	"go-payroll-service/internal/payroll/model/response"
+	"go-payroll-service/internal/payroll/service"
+	"go-payroll-service/internal/payroll/util"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+type DisbursementController struct {
+	svc service.DisbursementService
+}
+
+func NewDisbursementController(svc service.DisbursementService) *DisbursementController {
+	return &DisbursementController{svc: svc}
+}
+
+func (h *DisbursementController) RegisterRoutes(rg *gin.RouterGroup) {
+	rg.GET("/employees/:id/bank-account", auth.Require(auth.RoleHRAdmin), h.GetBankAccount)
+	rg.PUT("/employees/:id/bank-account", auth.Require(auth.RoleHRAdmin), h.SaveBankAccount)
+	rg.GET("/payroll/periods/:code/disbursement", auth.Require(auth.RolePayrollOfficer, auth.RoleFinanceViewer), h.Export)
+}
+
+func (h *DisbursementController) GetBankAccount(c *gin.Context) {
+	employeeID, _ := strconv.ParseInt(c.Param("id"), 10, 64)
+
+	acc, err := h.svc.GetBankAccount(c.Request.Context(), employeeID)
+	if err != nil {
+		if errors.Is(err, util.ErrNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "bank account not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch bank account"})
+		return
+	}
+	c.JSON(http.StatusOK, toBankAccountResponse(acc))
+}
+
+func (h *DisbursementController) SaveBankAccount(c *gin.Context) {
+	employeeID, _ := strconv.ParseInt(c.Param("id"), 10, 64)
+
+	var req request.SaveBankAccountRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	acc, err := h.svc.SaveBankAccount(c.Request.Context(), employeeID, req)
+	if err != nil {
+		if errors.Is(err, util.ErrNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": employeeNotFound})
+			return
+		}
+		c.JSON(http.StatusBadRequest, gin.H{"error": "failed to save bank account"})
+		return
+	}
+	c.JSON(http.StatusOK, toBankAccountResponse(acc))
+}
+
+// Export downloads the bank file of ?format= for the period's ?run_type=,
+// the regular run by default. The control
+// totals are repeated in the X-Control-Count and X-Control-Total headers,
+// and employees left out with a zero net salary are listed in
+// X-Skipped-Employees.
+func (h *DisbursementController) Export(c *gin.Context) {
+	var buf bytes.Buffer
+	file, err := h.svc.Export(c.Request.Context(), c.Param("code"), queryRunType(c), c.Query("format"), &buf)
+	if err != nil {
+		var invalid *disbursement.ValidationError
+		switch {
+		case errors.As(err, &invalid):
+			c.JSON(http.StatusUnprocessableEntity, gin.H{"error": "bank details are incomplete", "problems": invalid.Problems})
+		case errors.Is(err, util.ErrNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "no payslips found for period"})
+		case errors.Is(err, util.ErrInvalid):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		case errors.Is(err, util.ErrPeriodUnlocked), errors.Is(err, util.ErrRunNotApproved):
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to export disbursement"})
+		}
+		return
+	}
+
+	c.Header("Content-Disposition", `attachment; filename="`+file.Name+`"`)
+	c.Header("X-Control-Count", strconv.Itoa(file.Control.Count))
+	c.Header("X-Control-Total", strconv.FormatInt(file.Control.Amount, 10))
+	if len(file.Skipped) > 0 {
+		c.Header("X-Skipped-Employees", strings.Join(file.Skipped, ","))
+	}
+	c.Data(http.StatusOK, file.ContentType, buf.Bytes())
+}
+
+func toBankAccountResponse(acc domain.BankAccount) response.BankAccountResponse {
+	return response.BankAccountResponse{
+		EmployeeID:    acc.EmployeeID,
+		BankCode:      acc.BankCode,
+		AccountNumber: acc.AccountNumber,
+		AccountName:   acc.AccountName,
+		UpdateAt:      acc.UpdatedAt,
+	}
+}
@@ -2,17 +2,24 @@ package controller
 
 import (
 	"errors"
+	"go-payroll-service/internal/auth"
+	"go-payroll-service/internal/payroll/model/domain"
 	"go-payroll-service/internal/payroll/model/request"
 	"go-payroll-service/internal/payroll/model/response"
 	"go-payroll-service/internal/payroll/service"
 	"go-payroll-service/internal/payroll/util"
+	"io"
 	"net/http"
 	"strconv"
 
 	"github.com/gin-gonic/gin"
 )
 
-const employeeNotFound = "employee not found"
+const (
+	employeeNotFound = "employee not found"
+	// maxImportFileSize caps the employee import upload.
+	maxImportFileSize = 10 << 20
+)
 
 type EmployeeController struct {
 	svc service.EmployeeService
@@ -24,39 +31,42 @@ func NewEmployeeController(svc service.EmployeeService) *EmployeeController {
 
 func (h *EmployeeController) RegisterRoutes(rg *gin.RouterGroup) {
 	r := rg.Group("/employees")
-	r.GET("", h.List)
-	r.POST("", h.Create)
-	r.GET("/:id", h.GetById)
-	r.PUT("/:id", h.UpdateById)
-	r.DELETE("/:id", h.DeleteById)
+	r.GET("", auth.Require(auth.RoleHRAdmin, auth.RolePayrollOfficer), h.List)
+	r.POST("", auth.Require(auth.RoleHRAdmin), h.Create)
+	r.POST("/import", auth.Require(auth.RoleHRAdmin), h.Import)
+	r.GET("/:id", auth.Require(auth.RoleHRAdmin, auth.RolePayrollOfficer), h.GetById)
+	r.PUT("/:id", auth.Require(auth.RoleHRAdmin), h.UpdateById)
+	r.POST("/:id/terminate", auth.Require(auth.RoleHRAdmin), h.Terminate)
+	r.DELETE("/:id", auth.Require(auth.RoleHRAdmin), h.DeleteById)
 }
 
 func (h *EmployeeController) List(c *gin.Context) {
-	emps, err := h.svc.List(c.Request.Context())
-	if err != nil {
+	var req request.ListEmployeesRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
 
-	var resp response.EmployeeListResponse
-	for _, e := range emps {
-		resp = append(resp, response.EmployeeResponse{
-			ID:         e.ID,
-			Code:       e.Code,
-			FullName:   e.FullName,
-			BaseSalary: e.BaseSalary,
-			Allowance:  e.Allowance,
-			IsActive:   e.IsActive,
-			HireDate:   e.HireDate,
-			CreateAt:   e.CreatedAt,
-			UpdateAt:   e.UpdatedAt,
-		})
+	page, err := h.svc.List(c.Request.Context(), req)
+	if err != nil {
+		if errors.Is(err, util.ErrInvalid) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to list employees"})
+		return
 	}
 
-	if len(resp) == 0 {
-		c.JSON(http.StatusNoContent, gin.H{"data": resp})
+	resp := response.EmployeePageResponse{
+		Data:  response.EmployeeListResponse{},
+		Total: page.Total,
+	}
+	for _, e := range page.Employees {
+		resp.Data = append(resp.Data, toEmployeeResponse(e))
+	}
+	if page.Next != nil {
+		resp.NextCursor = page.Next.Encode()
 	}
-
 	c.JSON(http.StatusOK, resp)
 }
 
@@ -73,17 +83,77 @@ func (h *EmployeeController) Create(c *gin.Context) {
 		return
 	}
 
-	resp := response.EmployeeResponse{
-		ID:         e.ID,
-		Code:       e.Code,
-		FullName:   e.FullName,
-		Email:      e.Email,
-		BaseSalary: e.BaseSalary,
-		Allowance:  e.Allowance,
-		IsActive:   e.IsActive,
-		HireDate:   e.HireDate,
-		CreateAt:   e.CreatedAt,
-		UpdateAt:   e.UpdatedAt,
+	c.JSON(http.StatusOK, toEmployeeResponse(e))
+}
+
+// Import takes the file as the multipart field "file". A file rejected for
+// its invalid rows is answered with 422 and the same per-row report.
+func (h *EmployeeController) Import(c *gin.Context) {
+	var req request.ImportEmployeesRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	fh, err := c.FormFile("file")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "file is required"})
+		return
+	}
+	if fh.Size > maxImportFileSize {
+		c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": "file is larger than 10 MB"})
+		return
+	}
+	f, err := fh.Open()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "failed to read file"})
+		return
+	}
+	defer f.Close()
+	data, err := io.ReadAll(io.LimitReader(f, maxImportFileSize))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "failed to read file"})
+		return
+	}
+	req.FileName = fh.Filename
+
+	result, err := h.svc.Import(c.Request.Context(), data, req)
+	if err != nil {
+		if errors.Is(err, util.ErrInvalid) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to import employees"})
+		return
+	}
+
+	resp := response.EmployeeImportResponse{
+		Format:   result.Format,
+		DryRun:   result.DryRun,
+		Rejected: result.Rejected,
+		Total:    len(result.Rows),
+		Invalid:  result.Invalid(),
+		Imported: result.Imported(),
+		Rows:     []response.EmployeeImportRowResponse{},
+	}
+	for _, row := range result.Rows {
+		status := "imported"
+		switch {
+		case !row.Valid():
+			status = "invalid"
+		case row.EmployeeID == 0:
+			status = "valid"
+		}
+		resp.Rows = append(resp.Rows, response.EmployeeImportRowResponse{
+			Line:       row.Line,
+			Code:       row.Code,
+			Status:     status,
+			Errors:     row.Errors,
+			EmployeeID: row.EmployeeID,
+		})
+	}
+	if result.Rejected {
+		c.JSON(http.StatusUnprocessableEntity, resp)
+		return
 	}
 	c.JSON(http.StatusOK, resp)
 }
@@ -100,19 +170,7 @@ func (h *EmployeeController) GetById(c *gin.Context) {
 		return
 	}
 
-	resp := response.EmployeeResponse{
-		ID:         e.ID,
-		Code:       e.Code,
-		FullName:   e.FullName,
-		Email:      e.Email,
-		BaseSalary: e.BaseSalary,
-		Allowance:  e.Allowance,
-		IsActive:   e.IsActive,
-		HireDate:   e.HireDate,
-		CreateAt:   e.CreatedAt,
-		UpdateAt:   e.UpdatedAt,
-	}
-	c.JSON(http.StatusOK, resp)
+	c.JSON(http.StatusOK, toEmployeeResponse(e))
 }
 
 func (h *EmployeeController) UpdateById(c *gin.Context) {
@@ -126,27 +184,42 @@ func (h *EmployeeController) UpdateById(c *gin.Context) {
 
 	e, err := h.svc.Update(c.Request.Context(), id, req)
 	if err != nil {
-		if errors.Is(err, util.ErrNotFound) {
+		switch {
+		case errors.Is(err, util.ErrNotFound):
 			c.JSON(http.StatusNotFound, gin.H{"error": employeeNotFound})
-			return
+		case errors.Is(err, util.ErrInvalid):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusBadRequest, gin.H{"error": "failed to update employee"})
 		}
-		c.JSON(http.StatusBadRequest, gin.H{"error": "failed to update employee"})
 		return
 	}
 
-	resp := response.EmployeeResponse{
-		ID:         e.ID,
-		Code:       e.Code,
-		FullName:   e.FullName,
-		Email:      e.Email,
-		BaseSalary: e.BaseSalary,
-		Allowance:  e.Allowance,
-		IsActive:   e.IsActive,
-		HireDate:   e.HireDate,
-		CreateAt:   e.CreatedAt,
-		UpdateAt:   e.UpdatedAt,
+	c.JSON(http.StatusOK, toEmployeeResponse(e))
+}
+
+func (h *EmployeeController) Terminate(c *gin.Context) {
+	id, _ := strconv.ParseInt(c.Param("id"), 10, 64)
+
+	var req request.TerminateEmployeeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
 	}
-	c.JSON(http.StatusOK, resp)
+
+	e, err := h.svc.Terminate(c.Request.Context(), id, req)
+	if err != nil {
+		switch {
+		case errors.Is(err, util.ErrNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": employeeNotFound})
+		case errors.Is(err, util.ErrInvalid):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to terminate employee"})
+		}
+		return
+	}
+	c.JSON(http.StatusOK, toEmployeeResponse(e))
 }
 
 func (h *EmployeeController) DeleteById(c *gin.Context) {
@@ -163,3 +236,26 @@ func (h *EmployeeController) DeleteById(c *gin.Context) {
 	}
 	c.Status(http.StatusNoContent)
 }
+
+func toEmployeeResponse(e domain.Employee) response.EmployeeResponse {
+	return response.EmployeeResponse{
+		ID:                e.ID,
+		Code:              e.Code,
+		FullName:          e.FullName,
+		Email:             e.Email,
+		Department:        e.Department,
+		Religion:          e.Religion,
+		BaseSalary:        e.BaseSalary,
+		Allowance:         e.Allowance,
+		PTKPStatus:        e.PTKPStatus,
+		NPWP:              e.NPWP,
+		IsActive:          e.IsActive,
+		HireDate:          e.HireDate,
+		TerminationDate:   e.TerminationDate,
+		TerminationReason: e.TerminationReason,
+		FinalPay:          e.FinalPay,
+		DeletedAt:         e.DeletedAt,
+		CreateAt:          e.CreatedAt,
+		UpdateAt:          e.UpdatedAt,
+	}
+}
@@ -0,0 +1,280 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"go-payroll-service/internal/auth"
+	"go-payroll-service/internal/payroll/model/domain"
+	"go-payroll-service/internal/payroll/model/request"
+	"go-payroll-service/internal/payroll/model/response"
+	"go-payroll-service/internal/payroll/service"
+	"go-payroll-service/internal/payroll/util"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+type LeaveController struct {
+	svc service.LeaveService
+}
+
+func NewLeaveController(svc service.LeaveService) *LeaveController {
+	return &LeaveController{svc: svc}
+}
+
+func (h *LeaveController) RegisterRoutes(rg *gin.RouterGroup) {
+	t := rg.Group("/leave/types")
+	t.GET("", auth.Require(auth.RoleHRAdmin, auth.RolePayrollOfficer, auth.RoleEmployee), h.ListTypes)
+	t.POST("", auth.Require(auth.RoleHRAdmin), h.CreateType)
+	t.PUT("/:id", auth.Require(auth.RoleHRAdmin), h.UpdateType)
+
+	r := rg.Group("/leave/requests", auth.Require(auth.RoleHRAdmin))
+	r.GET("", h.ListRequests)
+	r.POST("/:id/approve", h.Approve)
+	r.POST("/:id/reject", h.Reject)
+
+	e := rg.Group("/employees/:id/leave", auth.Require(auth.RoleHRAdmin))
+	e.POST("", h.Request)
+	e.GET("/balances", h.Balances)
+}
+
+func (h *LeaveController) ListTypes(c *gin.Context) {
+	list, err := h.svc.ListTypes(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to list leave types"})
+		return
+	}
+
+	resp := response.LeaveTypeListResponse{}
+	for _, t := range list {
+		resp = append(resp, toLeaveTypeResponse(t))
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+func (h *LeaveController) CreateType(c *gin.Context) {
+	var req request.CreateLeaveTypeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	t, err := h.svc.CreateType(c.Request.Context(), req)
+	if err != nil {
+		if errors.Is(err, util.ErrInvalid) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to create leave type"})
+		return
+	}
+	c.JSON(http.StatusCreated, toLeaveTypeResponse(t))
+}
+
+func (h *LeaveController) UpdateType(c *gin.Context) {
+	id, _ := strconv.ParseInt(c.Param("id"), 10, 64)
+
+	var req request.UpdateLeaveTypeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	t, err := h.svc.UpdateType(c.Request.Context(), id, req)
+	if err != nil {
+		if errors.Is(err, util.ErrNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "leave type not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to update leave type"})
+		return
+	}
+	c.JSON(http.StatusOK, toLeaveTypeResponse(t))
+}
+
+func (h *LeaveController) ListRequests(c *gin.Context) {
+	var req request.ListLeaveRequestsRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	list, err := h.svc.ListRequests(c.Request.Context(), req)
+	if err != nil {
+		if errors.Is(err, util.ErrInvalid) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to list leave requests"})
+		return
+	}
+	c.JSON(http.StatusOK, toLeaveRequestListResponse(list))
+}
+
+// Request files leave on the employee's behalf.
+func (h *LeaveController) Request(c *gin.Context) {
+	employeeID, _ := strconv.ParseInt(c.Param("id"), 10, 64)
+	requestLeave(c, h.svc, employeeID)
+}
+
+func (h *LeaveController) Approve(c *gin.Context) {
+	h.decide(c, h.svc.Approve)
+}
+
+func (h *LeaveController) Reject(c *gin.Context) {
+	h.decide(c, h.svc.Reject)
+}
+
+func (h *LeaveController) decide(c *gin.Context, decide func(context.Context, int64, request.DecideLeaveRequest) (domain.LeaveRequest, error)) {
+	id, _ := strconv.ParseInt(c.Param("id"), 10, 64)
+
+	var req request.DecideLeaveRequest
+	if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	lr, err := decide(c.Request.Context(), id, req)
+	if err != nil {
+		leaveRequestError(c, err, "failed to decide leave request")
+		return
+	}
+	c.JSON(http.StatusOK, toLeaveRequestResponse(lr))
+}
+
+func (h *LeaveController) Balances(c *gin.Context) {
+	employeeID, _ := strconv.ParseInt(c.Param("id"), 10, 64)
+	leaveBalances(c, h.svc, employeeID)
+}
+
+// requestLeave files a leave request for the employee from the JSON body.
+func requestLeave(c *gin.Context, svc service.LeaveService, employeeID int64) {
+	var req request.CreateLeaveRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	lr, err := svc.Request(c.Request.Context(), employeeID, req)
+	if err != nil {
+		leaveRequestError(c, err, "failed to request leave")
+		return
+	}
+	c.JSON(http.StatusCreated, toLeaveRequestResponse(lr))
+}
+
+// leaveBalances writes the employee's balances for ?year=, the current year
+// by default.
+func leaveBalances(c *gin.Context, svc service.LeaveService, employeeID int64) {
+	year, ok := queryYear(c)
+	if !ok {
+		return
+	}
+
+	list, err := svc.Balances(c.Request.Context(), employeeID, year)
+	if err != nil {
+		if errors.Is(err, util.ErrNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": employeeNotFound})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to compute leave balances"})
+		return
+	}
+
+	resp := response.LeaveBalanceListResponse{}
+	for _, b := range list {
+		resp = append(resp, toLeaveBalanceResponse(b))
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+// queryYear reads ?year=, the current year if absent. It writes the error
+// response and returns false if the year is not a number.
+func queryYear(c *gin.Context) (int, bool) {
+	v := c.Query("year")
+	if v == "" {
+		return time.Now().Year(), true
+	}
+	year, err := strconv.Atoi(v)
+	if err != nil || year < 1 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "year must be a number"})
+		return 0, false
+	}
+	return year, true
+}
+
+func leaveRequestError(c *gin.Context, err error, failure string) {
+	switch {
+	case errors.Is(err, util.ErrNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "employee, leave type or leave request not found"})
+	case errors.Is(err, util.ErrInvalid):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, util.ErrTransition), errors.Is(err, util.ErrPeriodClosed):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": failure})
+	}
+}
+
+func toLeaveTypeResponse(t domain.LeaveType) response.LeaveTypeResponse {
+	return response.LeaveTypeResponse{
+		ID:                    t.ID,
+		Code:                  t.Code,
+		Name:                  t.Name,
+		Paid:                  t.Paid,
+		AnnualDays:            t.AnnualDays,
+		EligibleAfterMonths:   t.EligibleAfterMonths,
+		CarryOverDays:         t.CarryOverDays,
+		CarryOverExpiryMonths: t.CarryOverExpiryMonths,
+		IsActive:              t.IsActive,
+		CreateAt:              t.CreatedAt,
+		UpdateAt:              t.UpdatedAt,
+	}
+}
+
+func toLeaveRequestResponse(lr domain.LeaveRequest) response.LeaveRequestResponse {
+	return response.LeaveRequestResponse{
+		ID:           lr.ID,
+		EmployeeID:   lr.EmployeeID,
+		LeaveType:    lr.LeaveType.Code,
+		StartDate:    lr.StartDate.Format("2006-01-02"),
+		EndDate:      lr.EndDate.Format("2006-01-02"),
+		Days:         lr.Days,
+		Reason:       lr.Reason,
+		Status:       lr.Status,
+		RequestedBy:  lr.RequestedBy,
+		DecidedBy:    lr.DecidedBy,
+		DecisionNote: lr.DecisionNote,
+		DecidedAt:    lr.DecidedAt,
+		CreateAt:     lr.CreatedAt,
+	}
+}
+
+func toLeaveRequestListResponse(list []domain.LeaveRequest) response.LeaveRequestListResponse {
+	resp := response.LeaveRequestListResponse{}
+	for _, lr := range list {
+		resp = append(resp, toLeaveRequestResponse(lr))
+	}
+	return resp
+}
+
+func toLeaveBalanceResponse(b domain.LeaveBalance) response.LeaveBalanceResponse {
+	resp := response.LeaveBalanceResponse{
+		LeaveType:   b.LeaveType.Code,
+		Year:        b.Year,
+		Tracked:     b.LeaveType.Tracked(),
+		Entitlement: b.Entitlement,
+		CarriedOver: b.CarriedOver,
+		Expired:     b.Expired,
+		Used:        b.Used,
+		Pending:     b.Pending,
+	}
+	if resp.Tracked {
+		available := b.Available()
+		resp.Available = &available
+	}
+	return resp
+}
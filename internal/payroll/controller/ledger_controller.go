@@ -0,0 +1,148 @@
+package controller
+
+import (
+	"bytes"
+	"errors"
+	"go-payroll-service/internal/auth"
+	"go-payroll-service/internal/payroll/ledger"
+	"go-payroll-service/internal/payroll/model/domain"
+	"go-payroll-service/internal/payroll/model/request"
+	"go-payroll-service/internal/payroll/model/response"
+	"go-payroll-service/internal/payroll/service"
+	"go-payroll-service/internal/payroll/util"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type LedgerController struct {
+	svc service.LedgerService
+}
+
+func NewLedgerController(svc service.LedgerService) *LedgerController {
+	return &LedgerController{svc: svc}
+}
+
+func (h *LedgerController) RegisterRoutes(rg *gin.RouterGroup) {
+	r := rg.Group("/gl/mappings")
+	r.GET("", auth.Require(auth.RolePayrollOfficer, auth.RoleFinanceViewer), h.ListMappings)
+	r.PUT("/:code", auth.Require(auth.RolePayrollOfficer), h.SaveMapping)
+	r.DELETE("/:code", auth.Require(auth.RolePayrollOfficer), h.DeleteMapping)
+
+	rg.GET("/payroll/periods/:code/journal", auth.Require(auth.RolePayrollOfficer, auth.RoleFinanceViewer), h.Journal)
+}
+
+func (h *LedgerController) ListMappings(c *gin.Context) {
+	list, err := h.svc.ListMappings(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to list GL mappings"})
+		return
+	}
+
+	resp := response.GLMappingListResponse{}
+	for _, m := range list {
+		resp = append(resp, toGLMappingResponse(m))
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+func (h *LedgerController) SaveMapping(c *gin.Context) {
+	var req request.SaveGLMappingRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	m, err := h.svc.SaveMapping(c.Request.Context(), c.Param("code"), req)
+	if err != nil {
+		if errors.Is(err, util.ErrInvalid) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusBadRequest, gin.H{"error": "failed to save GL mapping"})
+		return
+	}
+	c.JSON(http.StatusOK, toGLMappingResponse(m))
+}
+
+func (h *LedgerController) DeleteMapping(c *gin.Context) {
+	err := h.svc.DeleteMapping(c.Request.Context(), c.Param("code"))
+	if err != nil {
+		if errors.Is(err, util.ErrNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "GL mapping not found"})
+			return
+		}
+		c.JSON(http.StatusBadRequest, gin.H{"error": "failed to delete GL mapping"})
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// Journal returns the period's journal as JSON, or as CSV with ?format=csv.
+func (h *LedgerController) Journal(c *gin.Context) {
+	format := c.DefaultQuery("format", "json")
+	if format != "json" && format != "csv" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "format must be json or csv"})
+		return
+	}
+
+	j, err := h.svc.Journal(c.Request.Context(), c.Param("code"))
+	if err != nil {
+		var unmapped *ledger.UnmappedError
+		switch {
+		case errors.Is(err, util.ErrNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "no payslips found for period"})
+		case errors.Is(err, util.ErrPeriodNotClosed):
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		case errors.As(err, &unmapped):
+			c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error(), "unmapped_codes": unmapped.Codes})
+		case errors.Is(err, ledger.ErrUnbalanced):
+			c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to build journal"})
+		}
+		return
+	}
+
+	if format == "csv" {
+		var buf bytes.Buffer
+		if err := ledger.WriteCSV(&buf, j); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to build journal"})
+			return
+		}
+		c.Header("Content-Disposition", `attachment; filename="journal-`+j.PeriodCode+`.csv"`)
+		c.Data(http.StatusOK, "text/csv", buf.Bytes())
+		return
+	}
+
+	debit, credit := j.Totals()
+	resp := response.JournalResponse{
+		PeriodCode:  j.PeriodCode,
+		Date:        j.Date.Format("2006-01-02"),
+		Reference:   j.Reference(),
+		TotalDebit:  debit,
+		TotalCredit: credit,
+		Entries:     []response.JournalEntryResponse{},
+	}
+	for _, e := range j.Entries {
+		resp.Entries = append(resp.Entries, response.JournalEntryResponse{
+			Account:     e.Account,
+			CostCenter:  e.CostCenter,
+			Description: e.Description,
+			Debit:       e.Debit,
+			Credit:      e.Credit,
+		})
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+func toGLMappingResponse(m domain.GLMapping) response.GLMappingResponse {
+	return response.GLMappingResponse{
+		Code:           m.Code,
+		Description:    m.Description,
+		ExpenseAccount: m.ExpenseAccount,
+		PayableAccount: m.PayableAccount,
+		CostCenter:     m.CostCenter,
+		UpdateAt:       m.UpdatedAt,
+	}
+}
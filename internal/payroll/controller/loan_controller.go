@@ -0,0 +1,154 @@
+package controller
+
+import (
+	"errors"
+	"go-payroll-service/internal/auth"
+	"go-payroll-service/internal/payroll/model/domain"
+	"go-payroll-service/internal/payroll/model/request"
+	"go-payroll-service/internal/payroll/model/response"
+	"go-payroll-service/internal/payroll/service"
+	"go-payroll-service/internal/payroll/util"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type LoanController struct {
+	svc service.LoanService
+}
+
+func NewLoanController(svc service.LoanService) *LoanController {
+	return &LoanController{svc: svc}
+}
+
+func (h *LoanController) RegisterRoutes(rg *gin.RouterGroup) {
+	e := rg.Group("/employees/:id/loans")
+	e.GET("", auth.Require(auth.RoleHRAdmin, auth.RolePayrollOfficer, auth.RoleFinanceViewer), h.List)
+	e.POST("", auth.Require(auth.RoleHRAdmin, auth.RolePayrollOfficer), h.Create)
+
+	r := rg.Group("/loans")
+	r.GET("/:id", auth.Require(auth.RoleHRAdmin, auth.RolePayrollOfficer, auth.RoleFinanceViewer), h.Get)
+	r.POST("/:id/payoff", auth.Require(auth.RoleHRAdmin, auth.RolePayrollOfficer), h.PayOff)
+}
+
+func (h *LoanController) List(c *gin.Context) {
+	employeeID, _ := strconv.ParseInt(c.Param("id"), 10, 64)
+	listLoans(c, h.svc, employeeID)
+}
+
+func (h *LoanController) Get(c *gin.Context) {
+	id, _ := strconv.ParseInt(c.Param("id"), 10, 64)
+
+	b, err := h.svc.Get(c.Request.Context(), id)
+	if err != nil {
+		if errors.Is(err, util.ErrNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "loan not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch loan"})
+		return
+	}
+	c.JSON(http.StatusOK, toLoanResponse(b))
+}
+
+func (h *LoanController) Create(c *gin.Context) {
+	employeeID, _ := strconv.ParseInt(c.Param("id"), 10, 64)
+
+	var req request.CreateLoanRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	b, err := h.svc.Create(c.Request.Context(), employeeID, req)
+	if err != nil {
+		loanError(c, err, "employee or payroll period not found", "failed to create loan")
+		return
+	}
+	c.JSON(http.StatusCreated, toLoanResponse(b))
+}
+
+func (h *LoanController) PayOff(c *gin.Context) {
+	id, _ := strconv.ParseInt(c.Param("id"), 10, 64)
+
+	var req request.PayOffLoanRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	b, err := h.svc.PayOff(c.Request.Context(), id, req)
+	if err != nil {
+		loanError(c, err, "loan or payroll period not found", "failed to pay off loan")
+		return
+	}
+	c.JSON(http.StatusOK, toLoanResponse(b))
+}
+
+// listLoans answers with the employee's loans; /me/loans shares it.
+func listLoans(c *gin.Context, svc service.LoanService, employeeID int64) {
+	list, err := svc.List(c.Request.Context(), employeeID)
+	if err != nil {
+		if errors.Is(err, util.ErrNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": employeeNotFound})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to list loans"})
+		return
+	}
+
+	resp := response.LoanListResponse{}
+	for _, b := range list {
+		resp = append(resp, toLoanResponse(b))
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+func loanError(c *gin.Context, err error, notFound, failed string) {
+	switch {
+	case errors.Is(err, util.ErrNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": notFound})
+	case errors.Is(err, util.ErrInvalid):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, util.ErrPeriodClosed), errors.Is(err, util.ErrPeriodNotOpen), errors.Is(err, util.ErrTransition):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": failed})
+	}
+}
+
+func toLoanResponse(b domain.LoanBalance) response.LoanResponse {
+	resp := response.LoanResponse{
+		ID:               b.ID,
+		EmployeeID:       b.EmployeeID,
+		Kind:             b.Kind,
+		Principal:        b.Principal,
+		InterestRateBP:   b.InterestRateBP,
+		Installments:     b.Installments,
+		StartPeriodCode:  b.StartPeriodCode,
+		PayoffPeriodCode: b.PayoffPeriodCode,
+		Note:             b.Note,
+		Status:           b.Status,
+		Total:            b.Total,
+		Installment:      b.Installment,
+		Repaid:           b.Repaid,
+		PaidOff:          b.PaidOff,
+		Outstanding:      b.Outstanding,
+		Schedule:         b.Schedule,
+		CreateAt:         b.CreatedAt,
+		UpdateAt:         b.UpdatedAt,
+	}
+	if resp.Schedule == nil {
+		resp.Schedule = []int64{}
+	}
+	for _, rp := range b.Repayments {
+		resp.Repayments = append(resp.Repayments, response.LoanRepaymentResponse{
+			PayslipID:  rp.PayslipID,
+			PeriodCode: rp.PeriodCode,
+			Status:     rp.Status,
+			Amount:     rp.Amount,
+		})
+	}
+	return resp
+}
@@ -0,0 +1,149 @@
+package controller
+
+import (
+	"bytes"
+	"errors"
+	"go-payroll-service/internal/auth"
+	"go-payroll-service/internal/payroll/model/request"
+	"go-payroll-service/internal/payroll/model/response"
+	"go-payroll-service/internal/payroll/service"
+	"go-payroll-service/internal/payroll/util"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// MeController serves the authenticated employee their own data. The
+// employee is always the one the token names, never a request parameter.
+type MeController struct {
+	employees service.EmployeeService
+	payroll   service.PayrollService
+	documents service.PayslipDocumentService
+	leave     service.LeaveService
+	loans     service.LoanService
+}
+
+func NewMeController(employees service.EmployeeService, payroll service.PayrollService, documents service.PayslipDocumentService,
+	leave service.LeaveService, loans service.LoanService) *MeController {
+	return &MeController{employees: employees, payroll: payroll, documents: documents, leave: leave, loans: loans}
+}
+
+func (h *MeController) RegisterRoutes(rg *gin.RouterGroup) {
+	r := rg.Group("/me", auth.Require(auth.RoleEmployee), requireEmployeeLink)
+	r.GET("", h.Profile)
+	r.GET("/payslips", h.Payslips)
+	r.GET("/payslips/:periodCode/pdf", h.PayslipDocument)
+	r.GET("/ytd", h.YTD)
+	r.GET("/leave", h.LeaveRequests)
+	r.POST("/leave", h.RequestLeave)
+	r.POST("/leave/:requestId/cancel", h.CancelLeave)
+	r.GET("/leave/balances", h.LeaveBalances)
+	r.GET("/loans", h.Loans)
+}
+
+// requireEmployeeLink rejects tokens that carry the employee role but no
+// employee_id.
+func requireEmployeeLink(c *gin.Context) {
+	if auth.CurrentPrincipal(c).EmployeeID == 0 {
+		c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "token is not linked to an employee"})
+		return
+	}
+	c.Next()
+}
+
+func (h *MeController) Profile(c *gin.Context) {
+	e, err := h.employees.GetByID(c.Request.Context(), auth.CurrentPrincipal(c).EmployeeID)
+	if err != nil {
+		if errors.Is(err, util.ErrNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": employeeNotFound})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch employee"})
+		return
+	}
+	c.JSON(http.StatusOK, toEmployeeResponse(e))
+}
+
+func (h *MeController) Payslips(c *gin.Context) {
+	list, err := h.payroll.ListEmployeePayslips(c.Request.Context(), auth.CurrentPrincipal(c).EmployeeID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to list payslips"})
+		return
+	}
+
+	resp := response.PayslipListResponse{}
+	for _, p := range list {
+		resp = append(resp, toPayslipResponse(p))
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+func (h *MeController) PayslipDocument(c *gin.Context) {
+	var buf bytes.Buffer
+	name, err := h.documents.RenderPayslip(c.Request.Context(), c.Param("periodCode"), queryRunType(c), auth.CurrentPrincipal(c).EmployeeID, true, &buf)
+	if err != nil {
+		if errors.Is(err, util.ErrNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "payslip not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to render payslip"})
+		return
+	}
+	c.Header("Content-Disposition", `attachment; filename="`+name+`"`)
+	c.Data(http.StatusOK, "application/pdf", buf.Bytes())
+}
+
+// YTD totals the caller's payslips of ?year=, the current year by default.
+func (h *MeController) YTD(c *gin.Context) {
+	year, ok := queryYear(c)
+	if !ok {
+		return
+	}
+
+	ytd, err := h.payroll.PayslipYTD(c.Request.Context(), auth.CurrentPrincipal(c).EmployeeID, year)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to total payslips"})
+		return
+	}
+	c.JSON(http.StatusOK, response.PayslipYTDResponse{
+		Year:       year,
+		Earnings:   ytd.Earnings,
+		Deductions: ytd.Deductions,
+		IncomeTax:  ytd.IncomeTax,
+		NetSalary:  ytd.NetSalary(),
+	})
+}
+
+func (h *MeController) LeaveRequests(c *gin.Context) {
+	req := request.ListLeaveRequestsRequest{EmployeeID: auth.CurrentPrincipal(c).EmployeeID}
+	list, err := h.leave.ListRequests(c.Request.Context(), req)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to list leave requests"})
+		return
+	}
+	c.JSON(http.StatusOK, toLeaveRequestListResponse(list))
+}
+
+func (h *MeController) RequestLeave(c *gin.Context) {
+	requestLeave(c, h.leave, auth.CurrentPrincipal(c).EmployeeID)
+}
+
+func (h *MeController) CancelLeave(c *gin.Context) {
+	id, _ := strconv.ParseInt(c.Param("requestId"), 10, 64)
+
+	lr, err := h.leave.Cancel(c.Request.Context(), auth.CurrentPrincipal(c).EmployeeID, id)
+	if err != nil {
+		leaveRequestError(c, err, "failed to cancel leave request")
+		return
+	}
+	c.JSON(http.StatusOK, toLeaveRequestResponse(lr))
+}
+
+func (h *MeController) LeaveBalances(c *gin.Context) {
+	leaveBalances(c, h.leave, auth.CurrentPrincipal(c).EmployeeID)
+}
+
+func (h *MeController) Loans(c *gin.Context) {
+	listLoans(c, h.loans, auth.CurrentPrincipal(c).EmployeeID)
+}
@@ -0,0 +1,108 @@
+package controller
+
+import (
+	"errors"
+	"go-payroll-service/internal/auth"
+	"go-payroll-service/internal/payroll/model/domain"
+	"go-payroll-service/internal/payroll/model/request"
+	"go-payroll-service/internal/payroll/model/response"
+	"go-payroll-service/internal/payroll/service"
+	"go-payroll-service/internal/payroll/util"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type OvertimeController struct {
+	svc service.OvertimeService
+}
+
+func NewOvertimeController(svc service.OvertimeService) *OvertimeController {
+	return &OvertimeController{svc: svc}
+}
+
+func (h *OvertimeController) RegisterRoutes(rg *gin.RouterGroup) {
+	r := rg.Group("/employees/:id/overtime")
+	r.GET("", auth.Require(auth.RoleHRAdmin, auth.RolePayrollOfficer), h.List)
+	r.PUT("", auth.Require(auth.RoleHRAdmin, auth.RolePayrollOfficer), h.Record)
+	r.DELETE("/:entryId", auth.Require(auth.RoleHRAdmin, auth.RolePayrollOfficer), h.Delete)
+}
+
+func (h *OvertimeController) List(c *gin.Context) {
+	employeeID, _ := strconv.ParseInt(c.Param("id"), 10, 64)
+
+	list, err := h.svc.List(c.Request.Context(), employeeID, c.Query("period_code"))
+	if err != nil {
+		if errors.Is(err, util.ErrNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": employeeNotFound})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to list overtime"})
+		return
+	}
+
+	resp := response.OvertimeListResponse{}
+	for _, o := range list {
+		resp = append(resp, toOvertimeResponse(o))
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+func (h *OvertimeController) Record(c *gin.Context) {
+	employeeID, _ := strconv.ParseInt(c.Param("id"), 10, 64)
+
+	var req request.RecordOvertimeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	o, err := h.svc.Record(c.Request.Context(), employeeID, req)
+	if err != nil {
+		switch {
+		case errors.Is(err, util.ErrNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "employee or payroll period not found"})
+		case errors.Is(err, util.ErrInvalid):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		case errors.Is(err, util.ErrPeriodClosed):
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to record overtime"})
+		}
+		return
+	}
+	c.JSON(http.StatusOK, toOvertimeResponse(o))
+}
+
+func (h *OvertimeController) Delete(c *gin.Context) {
+	employeeID, _ := strconv.ParseInt(c.Param("id"), 10, 64)
+	entryID, _ := strconv.ParseInt(c.Param("entryId"), 10, 64)
+
+	if err := h.svc.Delete(c.Request.Context(), employeeID, entryID); err != nil {
+		switch {
+		case errors.Is(err, util.ErrNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "overtime entry not found"})
+		case errors.Is(err, util.ErrPeriodClosed):
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to delete overtime"})
+		}
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+func toOvertimeResponse(o domain.OvertimeEntry) response.OvertimeResponse {
+	return response.OvertimeResponse{
+		ID:         o.ID,
+		EmployeeID: o.EmployeeID,
+		PeriodCode: o.PeriodCode,
+		WorkDate:   o.WorkDate.Format("2006-01-02"),
+		DayType:    o.DayType,
+		Minutes:    o.Minutes,
+		Note:       o.Note,
+		CreateAt:   o.CreatedAt,
+		UpdateAt:   o.UpdatedAt,
+	}
+}
@@ -2,10 +2,13 @@ package controller
 
 import (
 	"errors"
+	"go-payroll-service/internal/auth"
+	"go-payroll-service/internal/payroll/model/domain"
 	"go-payroll-service/internal/payroll/model/request"
 	"go-payroll-service/internal/payroll/model/response"
 	"go-payroll-service/internal/payroll/service"
 	"go-payroll-service/internal/payroll/util"
+	"math"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
@@ -21,8 +24,11 @@ func NewPayrollController(svc service.PayrollService) *PayrollController {
 
 func (h *PayrollController) RegisterRoutes(rg *gin.RouterGroup) {
 	r := rg.Group("/payroll")
-	r.POST("/generate", h.Generate)
-	r.GET("/payslips/:periodCode", h.ListPayslips)
+	r.POST("/generate", auth.Require(auth.RolePayrollOfficer), h.Generate)
+	r.POST("/thr", auth.Require(auth.RolePayrollOfficer), h.GenerateTHR)
+	r.POST("/off-cycle", auth.Require(auth.RolePayrollOfficer), h.GenerateOffCycle)
+	r.GET("/payslips/:periodCode", auth.Require(auth.RolePayrollOfficer, auth.RoleFinanceViewer, auth.RoleEmployee), h.ListPayslips)
+	r.PUT("/attendance", auth.Require(auth.RoleHRAdmin, auth.RolePayrollOfficer), h.RecordAttendance)
 }
 
 func (h *PayrollController) Generate(c *gin.Context) {
@@ -33,23 +39,95 @@ func (h *PayrollController) Generate(c *gin.Context) {
 		return
 	}
 
-	count, err := h.svc.GeneratePayroll(c.Request.Context(), req)
+	summary, err := h.svc.GeneratePayroll(c.Request.Context(), req)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to generate payroll"})
+		generateError(c, err)
 		return
 	}
+	c.JSON(http.StatusOK, toGeneratePayrollResponse(req.PeriodCode, domain.RunTypeRegular, summary))
+}
 
-	resp := response.GeneratePayrollResponse{
-		PeriodCode:   req.PeriodCode,
-		TotalPayslip: count,
+func (h *PayrollController) GenerateTHR(c *gin.Context) {
+	var req request.GenerateTHRRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
 	}
-	c.JSON(http.StatusOK, resp)
+
+	summary, err := h.svc.GenerateTHR(c.Request.Context(), req)
+	if err != nil {
+		generateError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, toGeneratePayrollResponse(req.PeriodCode, domain.RunTypeTHR, summary))
 }
 
+func (h *PayrollController) GenerateOffCycle(c *gin.Context) {
+	var req request.GenerateOffCycleRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	summary, err := h.svc.GenerateOffCycle(c.Request.Context(), req)
+	if err != nil {
+		generateError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, toGeneratePayrollResponse(req.PeriodCode, req.RunType, summary))
+}
+
+func generateError(c *gin.Context, err error) {
+	var runErr *util.PayrollRunError
+	switch {
+	case errors.Is(err, util.ErrNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": periodNotFound})
+	case errors.Is(err, util.ErrInvalid):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, util.ErrPeriodClosed), errors.Is(err, util.ErrPeriodNotOpen):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case errors.As(err, &runErr):
+		status, reason := http.StatusInternalServerError, "failed to save payslip"
+		if errors.Is(err, util.ErrCalculation) {
+			status, reason = http.StatusUnprocessableEntity, runErr.Err.Error()
+		}
+		c.JSON(status, gin.H{
+			"error":         "failed to generate payroll, no payslips were changed",
+			"period_code":   runErr.PeriodCode,
+			"employee_id":   runErr.EmployeeID,
+			"employee_code": runErr.EmployeeCode,
+			"reason":        reason,
+		})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to generate payroll"})
+	}
+}
+
+func toGeneratePayrollResponse(periodCode, runType string, summary domain.PayrollRunSummary) response.GeneratePayrollResponse {
+	return response.GeneratePayrollResponse{
+		PeriodCode:   periodCode,
+		RunType:      runType,
+		TotalPayslip: summary.Total(),
+		Created:      summary.Created,
+		Updated:      summary.Updated,
+		Unchanged:    summary.Unchanged,
+		Removed:      summary.Removed,
+	}
+}
+
+// queryRunType is the run type of ?run_type=, the regular run by default.
+func queryRunType(c *gin.Context) string {
+	return c.DefaultQuery("run_type", domain.RunTypeRegular)
+}
+
+// ListPayslips lists the period's payslips of ?run_type=, or of every run
+// type if it is not given. Employees only see their own published payslips.
 func (h *PayrollController) ListPayslips(c *gin.Context) {
 	periodCode := c.Param("periodCode")
 
-	list, err := h.svc.ListPayslips(c.Request.Context(), periodCode)
+	list, err := h.svc.ListPayslips(c.Request.Context(), periodCode, c.Query("run_type"))
 
 	if err != nil {
 		if errors.Is(err, util.ErrNotFound) {
@@ -60,18 +138,90 @@ func (h *PayrollController) ListPayslips(c *gin.Context) {
 		return
 	}
 
-	var resp response.PayslipListResponse
+	principal := auth.CurrentPrincipal(c)
+	resp := response.PayslipListResponse{}
 	for _, p := range list {
-		resp = append(resp, response.PayslipResponse{
-			ID:           p.ID,
-			EmployeeID:   p.EmployeeID,
-			EmployeeName: p.EmployeeName,
-			PeriodCode:   p.PeriodCode,
-			BaseSalary:   p.BaseSalary,
-			Allowance:    p.Allowance,
-			Deduction:    p.Deduction,
-			NetSalary:    p.NetSalary,
-		})
+		if !canSeePayslip(principal, p.EmployeeID) || !isPayrollStaff(principal) && !p.Published() {
+			continue
+		}
+		resp = append(resp, toPayslipResponse(p))
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+func (h *PayrollController) RecordAttendance(c *gin.Context) {
+	var req request.RecordAttendanceRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	a, err := h.svc.RecordAttendance(c.Request.Context(), req)
+	if err != nil {
+		if errors.Is(err, util.ErrNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "employee or payroll period not found"})
+			return
+		}
+		if errors.Is(err, util.ErrPeriodClosed) {
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to record attendance"})
+		return
+	}
+
+	resp := response.AttendanceResponse{
+		EmployeeID:  a.EmployeeID,
+		PeriodCode:  a.PeriodCode,
+		WorkingDays: a.WorkingDays,
+		DaysPresent: a.DaysPresent,
+		UpdateAt:    a.UpdatedAt,
 	}
 	c.JSON(http.StatusOK, resp)
 }
+
+func toPayslipResponse(p domain.PayslipWithEmployee) response.PayslipResponse {
+	var taxLines []response.PayslipTaxLineResponse
+	for _, l := range p.TaxLines {
+		taxLines = append(taxLines, response.PayslipTaxLineResponse{
+			Code:   l.Code,
+			Label:  l.Label,
+			Amount: l.Amount,
+		})
+	}
+	var lines []response.PayslipLineResponse
+	for _, l := range p.Lines {
+		lines = append(lines, response.PayslipLineResponse{
+			Code:    l.Code,
+			Label:   l.Label,
+			Type:    l.Type,
+			Amount:  l.Amount,
+			Taxable: l.Taxable,
+			Source:  l.Source,
+			LoanID:  l.LoanID,
+		})
+	}
+	return response.PayslipResponse{
+		ID:              p.ID,
+		EmployeeID:      p.EmployeeID,
+		EmployeeName:    p.EmployeeName,
+		PeriodCode:      p.PeriodCode,
+		RunType:         p.RunType,
+		Status:          p.Status,
+		TotalEarnings:   p.Total(domain.LineTypeEarning),
+		TotalDeductions: p.Total(domain.LineTypeDeduction),
+		NetSalary:       p.NetSalary(),
+		EmployerCost:    p.Total(domain.LineTypeEmployerContribution),
+		TaxableIncome:   p.TaxableIncome,
+		IncomeTax:       p.IncomeTax,
+		TaxMethod:       p.TaxMethod,
+		Proration: response.ProrationResponse{
+			Method:     p.ProrationMethod,
+			Days:       p.ProratedDays,
+			PeriodDays: p.PeriodDays,
+			Factor:     math.Round(p.ProrationFactor()*10000) / 10000,
+		},
+		Lines:    lines,
+		TaxLines: taxLines,
+	}
+}
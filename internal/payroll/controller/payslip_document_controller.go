@@ -0,0 +1,79 @@
+package controller
+
+import (
+	"bytes"
+	"errors"
+	"go-payroll-service/internal/auth"
+	"go-payroll-service/internal/payroll/service"
+	"go-payroll-service/internal/payroll/util"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type PayslipDocumentController struct {
+	svc service.PayslipDocumentService
+}
+
+func NewPayslipDocumentController(svc service.PayslipDocumentService) *PayslipDocumentController {
+	return &PayslipDocumentController{svc: svc}
+}
+
+func (h *PayslipDocumentController) RegisterRoutes(rg *gin.RouterGroup) {
+	r := rg.Group("/payroll/payslips/:periodCode")
+	r.GET("/pdf", auth.Require(auth.RolePayrollOfficer, auth.RoleFinanceViewer), h.PeriodBundle)
+	r.GET("/employees/:employeeId/pdf", auth.Require(auth.RolePayrollOfficer, auth.RoleFinanceViewer, auth.RoleEmployee), h.Payslip)
+}
+
+func (h *PayslipDocumentController) Payslip(c *gin.Context) {
+	employeeID, _ := strconv.ParseInt(c.Param("employeeId"), 10, 64)
+	principal := auth.CurrentPrincipal(c)
+	if !canSeePayslip(principal, employeeID) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "not allowed"})
+		return
+	}
+
+	var buf bytes.Buffer
+	name, err := h.svc.RenderPayslip(c.Request.Context(), c.Param("periodCode"), queryRunType(c), employeeID, !isPayrollStaff(principal), &buf)
+	if err != nil {
+		if errors.Is(err, util.ErrNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "payslip not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to render payslip"})
+		return
+	}
+	c.Header("Content-Disposition", `attachment; filename="`+name+`"`)
+	c.Data(http.StatusOK, "application/pdf", buf.Bytes())
+}
+
+func (h *PayslipDocumentController) PeriodBundle(c *gin.Context) {
+	var buf bytes.Buffer
+	name, err := h.svc.RenderPeriod(c.Request.Context(), c.Param("periodCode"), queryRunType(c), &buf)
+	if err != nil {
+		if errors.Is(err, util.ErrNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "no payslips found for period"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to render payslips"})
+		return
+	}
+	c.Header("Content-Disposition", `attachment; filename="`+name+`"`)
+	c.Data(http.StatusOK, "application/zip", buf.Bytes())
+}
+
+// canSeePayslip reports whether the caller may read the payslips of the
+// employee: payroll and finance staff see everyone's, employees their own.
+func canSeePayslip(p auth.Principal, employeeID int64) bool {
+	if isPayrollStaff(p) {
+		return true
+	}
+	return p.HasAny(auth.RoleEmployee) && p.EmployeeID != 0 && p.EmployeeID == employeeID
+}
+
+// isPayrollStaff reports whether the caller works on payroll and so also
+// sees payslips of runs not approved yet; employees see published ones only.
+func isPayrollStaff(p auth.Principal) bool {
+	return p.HasAny(auth.RolePayrollOfficer, auth.RoleFinanceViewer)
+}
@@ -0,0 +1,128 @@
+package controller
+
+import (
+	"errors"
+	"go-payroll-service/internal/auth"
+	"go-payroll-service/internal/payroll/model/domain"
+	"go-payroll-service/internal/payroll/model/request"
+	"go-payroll-service/internal/payroll/model/response"
+	"go-payroll-service/internal/payroll/service"
+	"go-payroll-service/internal/payroll/util"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+const periodNotFound = "payroll period not found"
+
+type PeriodController struct {
+	svc service.PeriodService
+}
+
+func NewPeriodController(svc service.PeriodService) *PeriodController {
+	return &PeriodController{svc: svc}
+}
+
+func (h *PeriodController) RegisterRoutes(rg *gin.RouterGroup) {
+	r := rg.Group("/payroll/periods")
+	r.GET("", auth.Require(auth.RolePayrollOfficer, auth.RoleFinanceViewer), h.List)
+	r.POST("", auth.Require(auth.RolePayrollOfficer), h.Create)
+	r.GET("/:code", auth.Require(auth.RolePayrollOfficer, auth.RoleFinanceViewer), h.GetByCode)
+	r.POST("/:code/open", auth.Require(auth.RolePayrollOfficer), h.transition(service.PeriodActionOpen))
+	r.POST("/:code/lock", auth.Require(auth.RolePayrollOfficer), h.transition(service.PeriodActionLock))
+	r.POST("/:code/close", auth.Require(auth.RolePayrollOfficer), h.transition(service.PeriodActionClose))
+	r.POST("/:code/reopen", auth.Require(auth.RolePayrollOfficer), h.transition(service.PeriodActionReopen))
+}
+
+func (h *PeriodController) List(c *gin.Context) {
+	list, err := h.svc.List(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to list payroll periods"})
+		return
+	}
+
+	resp := response.PeriodListResponse{}
+	for _, p := range list {
+		resp = append(resp, toPeriodResponse(p))
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+func (h *PeriodController) Create(c *gin.Context) {
+	var req request.CreatePeriodRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	p, err := h.svc.Create(c.Request.Context(), req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, toPeriodResponse(p))
+}
+
+func (h *PeriodController) GetByCode(c *gin.Context) {
+	p, err := h.svc.GetByCode(c.Request.Context(), c.Param("code"))
+	if err != nil {
+		if errors.Is(err, util.ErrNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": periodNotFound})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch payroll period"})
+		return
+	}
+	c.JSON(http.StatusOK, toPeriodResponse(p))
+}
+
+func (h *PeriodController) transition(action string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req request.PeriodTransitionRequest
+		if c.Request.ContentLength > 0 {
+			if err := c.ShouldBindJSON(&req); err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+				return
+			}
+		}
+
+		p, err := h.svc.Transition(c.Request.Context(), c.Param("code"), action, req)
+		if err != nil {
+			switch {
+			case errors.Is(err, util.ErrNotFound):
+				c.JSON(http.StatusNotFound, gin.H{"error": periodNotFound})
+			case errors.Is(err, util.ErrTransition):
+				c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+			case errors.Is(err, util.ErrInvalid):
+				c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			default:
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to " + action + " payroll period"})
+			}
+			return
+		}
+		c.JSON(http.StatusOK, toPeriodResponse(p))
+	}
+}
+
+func toPeriodResponse(p domain.PayrollPeriod) response.PeriodResponse {
+	resp := response.PeriodResponse{
+		ID:        p.ID,
+		Code:      p.Code,
+		StartDate: p.StartDate.Format("2006-01-02"),
+		EndDate:   p.EndDate.Format("2006-01-02"),
+		PayDate:   p.PayDate.Format("2006-01-02"),
+		Status:    p.Status,
+		CreateAt:  p.CreatedAt,
+		UpdateAt:  p.UpdatedAt,
+	}
+	for _, ev := range p.Events {
+		resp.Events = append(resp.Events, response.PeriodEventResponse{
+			Action:     ev.Action,
+			FromStatus: ev.FromStatus,
+			ToStatus:   ev.ToStatus,
+			Reason:     ev.Reason,
+			CreateAt:   ev.CreatedAt,
+		})
+	}
+	return resp
+}
@@ -0,0 +1,125 @@
+package controller
+
+import (
+	"errors"
+	"go-payroll-service/internal/auth"
+	"go-payroll-service/internal/payroll/model/domain"
+	"go-payroll-service/internal/payroll/model/request"
+	"go-payroll-service/internal/payroll/model/response"
+	"go-payroll-service/internal/payroll/service"
+	"go-payroll-service/internal/payroll/util"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+const runNotFound = "payroll run not found"
+
+type RunController struct {
+	svc service.RunService
+}
+
+func NewRunController(svc service.RunService) *RunController {
+	return &RunController{svc: svc}
+}
+
+func (h *RunController) RegisterRoutes(rg *gin.RouterGroup) {
+	r := rg.Group("/payroll/runs/:periodCode")
+	r.GET("", auth.Require(auth.RolePayrollOfficer, auth.RoleFinanceViewer, auth.RolePayrollApprover), h.List)
+	r.GET("/:runType", auth.Require(auth.RolePayrollOfficer, auth.RoleFinanceViewer, auth.RolePayrollApprover), h.Get)
+	r.POST("/:runType/submit", auth.Require(auth.RolePayrollOfficer), h.review(domain.RunActionSubmit))
+	r.POST("/:runType/approve", auth.Require(auth.RolePayrollApprover), h.review(domain.RunActionApprove))
+	r.POST("/:runType/reject", auth.Require(auth.RolePayrollApprover), h.review(domain.RunActionReject))
+}
+
+func (h *RunController) List(c *gin.Context) {
+	list, err := h.svc.List(c.Request.Context(), c.Param("periodCode"))
+	if err != nil {
+		if errors.Is(err, util.ErrNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": periodNotFound})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to list payroll runs"})
+		return
+	}
+
+	resp := response.PayrollRunListResponse{}
+	for _, run := range list {
+		resp = append(resp, toPayrollRunResponse(run))
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+func (h *RunController) Get(c *gin.Context) {
+	run, err := h.svc.Get(c.Request.Context(), c.Param("periodCode"), c.Param("runType"))
+	if err != nil {
+		if errors.Is(err, util.ErrNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": runNotFound})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch payroll run"})
+		return
+	}
+	c.JSON(http.StatusOK, toPayrollRunResponse(run))
+}
+
+func (h *RunController) review(action string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req request.ReviewRunRequest
+		if c.Request.ContentLength > 0 {
+			if err := c.ShouldBindJSON(&req); err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+				return
+			}
+		}
+
+		run, err := h.svc.Review(c.Request.Context(), c.Param("periodCode"), c.Param("runType"), action, req)
+		if err != nil {
+			switch {
+			case errors.Is(err, util.ErrNotFound):
+				c.JSON(http.StatusNotFound, gin.H{"error": runNotFound})
+			case errors.Is(err, util.ErrInvalid):
+				c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			case errors.Is(err, util.ErrForbidden):
+				c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+			case errors.Is(err, util.ErrTransition), errors.Is(err, util.ErrPeriodClosed):
+				c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+			default:
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to " + action + " payroll run"})
+			}
+			return
+		}
+		c.JSON(http.StatusOK, toPayrollRunResponse(run))
+	}
+}
+
+func toPayrollRunResponse(run domain.PayrollRun) response.PayrollRunResponse {
+	resp := response.PayrollRunResponse{
+		PeriodCode:     run.PeriodCode,
+		RunType:        run.RunType,
+		Status:         run.Status,
+		Round:          run.Round,
+		TotalNet:       run.TotalNet,
+		RequiredLevels: run.RequiredLevels,
+		Approvers:      run.Approvers(),
+		GeneratedBy:    run.GeneratedBy,
+		SubmittedBy:    run.SubmittedBy,
+		CreateAt:       run.CreatedAt,
+		UpdateAt:       run.UpdatedAt,
+	}
+	if resp.Approvers == nil {
+		resp.Approvers = []string{}
+	}
+	for _, ev := range run.Events {
+		resp.Events = append(resp.Events, response.PayrollRunEventResponse{
+			Round:      ev.Round,
+			Action:     ev.Action,
+			FromStatus: ev.FromStatus,
+			ToStatus:   ev.ToStatus,
+			Actor:      ev.Actor,
+			Comment:    ev.Comment,
+			CreateAt:   ev.CreatedAt,
+		})
+	}
+	return resp
+}
@@ -0,0 +1,86 @@
+package controller
+
+import (
+	"errors"
+	"go-payroll-service/internal/auth"
+	"go-payroll-service/internal/payroll/model/domain"
+	"go-payroll-service/internal/payroll/model/request"
+	"go-payroll-service/internal/payroll/model/response"
+	"go-payroll-service/internal/payroll/service"
+	"go-payroll-service/internal/payroll/util"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type SalaryController struct {
+	svc service.SalaryService
+}
+
+func NewSalaryController(svc service.SalaryService) *SalaryController {
+	return &SalaryController{svc: svc}
+}
+
+func (h *SalaryController) RegisterRoutes(rg *gin.RouterGroup) {
+	r := rg.Group("/employees/:id/salaries")
+	r.GET("", auth.Require(auth.RoleHRAdmin), h.History)
+	r.POST("", auth.Require(auth.RoleHRAdmin), h.Schedule)
+}
+
+func (h *SalaryController) History(c *gin.Context) {
+	employeeID, _ := strconv.ParseInt(c.Param("id"), 10, 64)
+
+	list, err := h.svc.History(c.Request.Context(), employeeID)
+	if err != nil {
+		if errors.Is(err, util.ErrNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": employeeNotFound})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to list salary history"})
+		return
+	}
+
+	resp := response.SalaryListResponse{}
+	for _, rec := range list {
+		resp = append(resp, toSalaryResponse(rec))
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+func (h *SalaryController) Schedule(c *gin.Context) {
+	employeeID, _ := strconv.ParseInt(c.Param("id"), 10, 64)
+
+	var req request.ScheduleSalaryRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	rec, err := h.svc.Schedule(c.Request.Context(), employeeID, req)
+	if err != nil {
+		switch {
+		case errors.Is(err, util.ErrNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": employeeNotFound})
+		case errors.Is(err, util.ErrInvalid):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusBadRequest, gin.H{"error": "failed to schedule salary change"})
+		}
+		return
+	}
+	c.JSON(http.StatusOK, toSalaryResponse(rec))
+}
+
+func toSalaryResponse(rec domain.SalaryRecord) response.SalaryResponse {
+	return response.SalaryResponse{
+		ID:            rec.ID,
+		EmployeeID:    rec.EmployeeID,
+		BaseSalary:    rec.BaseSalary,
+		Allowance:     rec.Allowance,
+		EffectiveFrom: rec.EffectiveFrom.Format("2006-01-02"),
+		Reason:        rec.Reason,
+		ApprovedBy:    rec.ApprovedBy,
+		CreateAt:      rec.CreatedAt,
+	}
+}
@@ -0,0 +1,88 @@
+package disbursement
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+)
+
+const bcaBankCode = "014"
+
+// BCA is a fixed-width payroll file for BCA, which only credits BCA
+// accounts from a BCA account. Records end in CRLF; positions are 1-based:
+//
+//	header   1 "0" | 2-11 debtor account | 12-19 pay date YYYYMMDD |
+//	         20-24 payment count | 25-41 total amount | 42-81 company name
+//	detail   1 "1" | 2-11 account | 12-28 amount | 29-63 account name |
+//	         64-83 reference
+//	trailer  1 "9" | 2-6 payment count | 7-23 total amount
+//
+// Numbers are zero padded on the left, amounts carry two implied decimals,
+// and text is upper case ASCII padded with spaces on the right; names
+// longer than their field are cut.
+type BCA struct{}
+
+func (BCA) Format() string        { return "bca" }
+func (BCA) ContentType() string   { return "text/plain" }
+func (BCA) FileExtension() string { return "txt" }
+
+func (BCA) Validate(b Batch) []Problem {
+	var problems []Problem
+	if b.Debtor.BankCode != bcaBankCode || len(b.Debtor.Number) != 10 {
+		problems = append(problems, Problem{Message: "debtor must be a 10 digit BCA account"})
+	}
+	if !isASCII(b.Debtor.Name) {
+		problems = append(problems, Problem{Message: "debtor name has non-ASCII characters"})
+	}
+	if b.ControlTotal().Count > 99999 {
+		problems = append(problems, Problem{Message: "more than 99999 payments in one file"})
+	}
+	for _, p := range b.Payments {
+		if p.Account.BankCode != bcaBankCode {
+			problems = append(problems, Problem{p.EmployeeCode, "account is not at BCA"})
+		}
+		if len(p.Account.Number) != 10 {
+			problems = append(problems, Problem{p.EmployeeCode, "BCA account number must be 10 digits"})
+		}
+		if !isASCII(p.Account.Name) {
+			problems = append(problems, Problem{p.EmployeeCode, "account name has non-ASCII characters"})
+		}
+		if len(p.Reference) > 20 || !isASCII(p.Reference) {
+			problems = append(problems, Problem{p.EmployeeCode, "reference must be at most 20 ASCII characters"})
+		}
+	}
+	return problems
+}
+
+func (BCA) Export(w io.Writer, b Batch) error {
+	bw := bufio.NewWriter(w)
+	total := b.ControlTotal()
+
+	fmt.Fprintf(bw, "0%s%s%05d%017d%s\r\n",
+		b.Debtor.Number, b.PayDate.Format("20060102"), total.Count, total.Amount*100, fixedText(b.Debtor.Name, 40))
+	for _, p := range b.Payments {
+		fmt.Fprintf(bw, "1%s%017d%s%s\r\n",
+			p.Account.Number, p.Amount*100, fixedText(p.Account.Name, 35), fixedText(p.Reference, 20))
+	}
+	fmt.Fprintf(bw, "9%05d%017d\r\n", total.Count, total.Amount*100)
+	return bw.Flush()
+}
+
+// fixedText upper-cases s and cuts or pads it to width.
+func fixedText(s string, width int) string {
+	s = strings.ToUpper(s)
+	if len(s) > width {
+		return s[:width]
+	}
+	return s + strings.Repeat(" ", width-len(s))
+}
+
+func isASCII(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] < 0x20 || s[i] > 0x7e {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,32 @@
+package disbursement
+
+import (
+	"encoding/csv"
+	"io"
+	"strconv"
+)
+
+// CSV is a generic comma separated file, one payment per row, closed by a
+// control total row.
+type CSV struct{}
+
+func (CSV) Format() string        { return "csv" }
+func (CSV) ContentType() string   { return "text/csv" }
+func (CSV) FileExtension() string { return "csv" }
+
+func (CSV) Validate(Batch) []Problem { return nil }
+
+func (CSV) Export(w io.Writer, b Batch) error {
+	cw := csv.NewWriter(w)
+	_ = cw.Write([]string{"reference", "employee_code", "account_name", "bank_code", "account_number", "amount", "currency"})
+	for _, p := range b.Payments {
+		_ = cw.Write([]string{
+			p.Reference, p.EmployeeCode, p.Account.Name, p.Account.BankCode, p.Account.Number,
+			strconv.FormatInt(p.Amount, 10), "IDR",
+		})
+	}
+	total := b.ControlTotal()
+	_ = cw.Write([]string{"TOTAL", strconv.Itoa(total.Count), "", "", "", strconv.FormatInt(total.Amount, 10), "IDR"})
+	cw.Flush()
+	return cw.Error()
+}
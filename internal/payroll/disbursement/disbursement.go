@@ -0,0 +1,175 @@
+package disbursement
+
+import (
+	"fmt"
+	"io"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Account is a bank account payments are made from or to. BankCode is the
+// Indonesian clearing code of the bank, e.g. "014" for BCA.
+type Account struct {
+	Name     string
+	BankCode string
+	Number   string
+}
+
+// Payment is one credit transfer of a batch.
+type Payment struct {
+	EmployeeCode string
+	EmployeeName string
+	Account      Account
+	Amount       int64
+	// Reference identifies the payment on both sides, unique in the batch.
+	Reference string
+}
+
+// Batch is a period's net salaries, paid from the company's account.
+type Batch struct {
+	ID         string
+	PeriodCode string
+	PayDate    time.Time
+	CreatedAt  time.Time
+	Debtor     Account
+	Payments   []Payment
+	// Skipped are the employees whose net salary is zero, who are left out
+	// of the file as there is nothing to transfer.
+	Skipped []string
+}
+
+// ControlTotal is the number of payments and their sum, which bank formats
+// repeat in a header or trailer so the bank can check the file is complete.
+type ControlTotal struct {
+	Count  int
+	Amount int64
+}
+
+func (b Batch) ControlTotal() ControlTotal {
+	var t ControlTotal
+	for _, p := range b.Payments {
+		t.Count++
+		t.Amount += p.Amount
+	}
+	return t
+}
+
+// File describes an exported batch.
+type File struct {
+	Name        string
+	ContentType string
+	Control     ControlTotal
+	Skipped     []string
+}
+
+// Exporter writes a batch in one bank file format.
+type Exporter interface {
+	// Format is the name the format is requested by.
+	Format() string
+	ContentType() string
+	FileExtension() string
+	// Validate reports what the format cannot carry, on top of the checks
+	// every batch goes through.
+	Validate(b Batch) []Problem
+	Export(w io.Writer, b Batch) error
+}
+
+// Registry holds the available exporters by format.
+type Registry struct {
+	exporters map[string]Exporter
+}
+
+func NewRegistry(exporters ...Exporter) *Registry {
+	r := &Registry{exporters: map[string]Exporter{}}
+	for _, e := range exporters {
+		r.exporters[e.Format()] = e
+	}
+	return r
+}
+
+func (r *Registry) Get(format string) (Exporter, bool) {
+	e, ok := r.exporters[format]
+	return e, ok
+}
+
+func (r *Registry) Formats() []string {
+	formats := make([]string, 0, len(r.exporters))
+	for f := range r.exporters {
+		formats = append(formats, f)
+	}
+	slices.Sort(formats)
+	return formats
+}
+
+// Problem is a reason a payment cannot be exported.
+type Problem struct {
+	EmployeeCode string `json:"employee_code,omitempty"`
+	Message      string `json:"message"`
+}
+
+// ValidationError lists every problem found in a batch, so they can all be
+// fixed before trying again.
+type ValidationError struct {
+	Problems []Problem
+}
+
+func (e *ValidationError) Error() string {
+	msgs := make([]string, len(e.Problems))
+	for i, p := range e.Problems {
+		if p.EmployeeCode != "" {
+			msgs[i] = p.EmployeeCode + ": " + p.Message
+		} else {
+			msgs[i] = p.Message
+		}
+	}
+	return "disbursement batch is invalid: " + strings.Join(msgs, "; ")
+}
+
+var (
+	accountNumberExpr = regexp.MustCompile(`^[0-9]{5,20}$`)
+	bankCodeExpr      = regexp.MustCompile(`^[0-9]{3}$`)
+)
+
+// Validate checks b with the checks every format needs and then the
+// exporter's own, returning a *ValidationError if any fail.
+func Validate(e Exporter, b Batch) error {
+	var problems []Problem
+	problems = append(problems, checkAccount("", "debtor", b.Debtor)...)
+
+	refs := map[string]bool{}
+	for _, p := range b.Payments {
+		problems = append(problems, checkAccount(p.EmployeeCode, "bank account", p.Account)...)
+		if p.Amount < 0 {
+			problems = append(problems, Problem{p.EmployeeCode, fmt.Sprintf("net salary %d is negative", p.Amount)})
+		}
+		if refs[p.Reference] {
+			problems = append(problems, Problem{p.EmployeeCode, fmt.Sprintf("duplicate reference %q", p.Reference)})
+		}
+		refs[p.Reference] = true
+	}
+	if len(b.Payments) == 0 {
+		problems = append(problems, Problem{Message: "batch has no payments"})
+	}
+
+	problems = append(problems, e.Validate(b)...)
+	if len(problems) > 0 {
+		return &ValidationError{Problems: problems}
+	}
+	return nil
+}
+
+func checkAccount(employeeCode, what string, a Account) []Problem {
+	var problems []Problem
+	if strings.TrimSpace(a.Name) == "" {
+		problems = append(problems, Problem{employeeCode, what + " has no account holder name"})
+	}
+	if !bankCodeExpr.MatchString(a.BankCode) {
+		problems = append(problems, Problem{employeeCode, fmt.Sprintf("%s bank code %q is not 3 digits", what, a.BankCode)})
+	}
+	if !accountNumberExpr.MatchString(a.Number) {
+		problems = append(problems, Problem{employeeCode, fmt.Sprintf("%s number %q is not 5 to 20 digits", what, a.Number)})
+	}
+	return problems
+}
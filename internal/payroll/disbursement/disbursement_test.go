@@ -0,0 +1,135 @@
+package disbursement
+
+import (
+	"bytes"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+)
+
+func batch(payments ...Payment) Batch {
+	return Batch{
+		ID:         "B1",
+		PeriodCode: "2024-06",
+		PayDate:    time.Date(2024, time.June, 25, 0, 0, 0, 0, time.UTC),
+		Debtor:     Account{Name: "PT Contoh", BankCode: "014", Number: "1234567890"},
+		Payments:   payments,
+	}
+}
+
+func payment(code string, amount int64) Payment {
+	return Payment{
+		EmployeeCode: code,
+		Account:      Account{Name: "Budi", BankCode: "014", Number: "0987654321"},
+		Amount:       amount,
+		Reference:    "PS-" + code,
+	}
+}
+
+func TestValidate(t *testing.T) {
+	badAccount := payment("E2", 100)
+	badAccount.Account = Account{Name: " ", BankCode: "14", Number: "12-34"}
+	duplicate := payment("E3", 100)
+	duplicate.Reference = "PS-E1"
+	otherBank := payment("E4", 100)
+	otherBank.Account.BankCode = "008"
+
+	tests := []struct {
+		name     string
+		exporter Exporter
+		batch    Batch
+		want     []Problem
+	}{
+		{"valid", CSV{}, batch(payment("E1", 5000000)), nil},
+		{"zero amount", CSV{}, batch(payment("E1", 0)), nil},
+		{"negative amount", CSV{}, batch(payment("E1", -1)), []Problem{{"E1", "net salary -1 is negative"}}},
+		{"no payments", CSV{}, batch(), []Problem{{Message: "batch has no payments"}}},
+		{
+			"bad account",
+			CSV{},
+			batch(badAccount),
+			[]Problem{
+				{"E2", "bank account has no account holder name"},
+				{"E2", `bank account bank code "14" is not 3 digits`},
+				{"E2", `bank account number "12-34" is not 5 to 20 digits`},
+			},
+		},
+		{
+			"duplicate reference",
+			CSV{},
+			batch(payment("E1", 100), duplicate),
+			[]Problem{{"E3", `duplicate reference "PS-E1"`}},
+		},
+		{"exporter's own checks", BCA{}, batch(otherBank), []Problem{{"E4", "account is not at BCA"}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Validate(tt.exporter, tt.batch)
+			if tt.want == nil {
+				if err != nil {
+					t.Fatalf("Validate() error = %v, want nil", err)
+				}
+				return
+			}
+			var verr *ValidationError
+			if !errors.As(err, &verr) {
+				t.Fatalf("Validate() error = %v, want a *ValidationError", err)
+			}
+			if !slices.Equal(verr.Problems, tt.want) {
+				t.Errorf("Validate() problems = %v, want %v", verr.Problems, tt.want)
+			}
+		})
+	}
+}
+
+func TestControlTotal(t *testing.T) {
+	got := batch(payment("E1", 5000000), payment("E2", 0), payment("E3", 7250000)).ControlTotal()
+	if want := (ControlTotal{Count: 3, Amount: 12250000}); got != want {
+		t.Errorf("ControlTotal() = %+v, want %+v", got, want)
+	}
+}
+
+func TestExport(t *testing.T) {
+	b := batch(payment("E1", 5000000), payment("E2", 7250000))
+	tests := []struct {
+		exporter Exporter
+		want     string
+	}{
+		{
+			CSV{},
+			"reference,employee_code,account_name,bank_code,account_number,amount,currency\n" +
+				"PS-E1,E1,Budi,014,0987654321,5000000,IDR\n" +
+				"PS-E2,E2,Budi,014,0987654321,7250000,IDR\n" +
+				"TOTAL,2,,,,12250000,IDR\n",
+		},
+		{
+			BCA{},
+			"0" + "1234567890" + "20240625" + "00002" + "00000001225000000" + fixedText("PT Contoh", 40) + "\r\n" +
+				"1" + "0987654321" + "00000000500000000" + fixedText("Budi", 35) + fixedText("PS-E1", 20) + "\r\n" +
+				"1" + "0987654321" + "00000000725000000" + fixedText("Budi", 35) + fixedText("PS-E2", 20) + "\r\n" +
+				"9" + "00002" + "00000001225000000" + "\r\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.exporter.Format(), func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := tt.exporter.Export(&buf, b); err != nil {
+				t.Fatalf("Export() error = %v", err)
+			}
+			if got := buf.String(); got != tt.want {
+				t.Errorf("Export() =\n%q\nwant\n%q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRegistry(t *testing.T) {
+	r := NewRegistry(CSV{}, BCA{}, Pain001{})
+	if got, want := r.Formats(), []string{"bca", "csv", "pain001"}; !slices.Equal(got, want) {
+		t.Errorf("Formats() = %v, want %v", got, want)
+	}
+	if _, ok := r.Get("mt940"); ok {
+		t.Error("Get(mt940) found an exporter")
+	}
+}
@@ -0,0 +1,140 @@
+package disbursement
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"unicode/utf8"
+)
+
+const pain001Namespace = "urn:iso:std:iso:20022:tech:xsd:pain.001.001.03"
+
+// Pain001 is an ISO 20022 customer credit transfer initiation
+// (pain.001.001.03) with one payment information block for the batch.
+// Accounts are identified by number and banks by their national clearing
+// code, as Indonesian accounts have no IBAN.
+type Pain001 struct{}
+
+func (Pain001) Format() string        { return "pain001" }
+func (Pain001) ContentType() string   { return "application/xml" }
+func (Pain001) FileExtension() string { return "xml" }
+
+// Validate enforces the schema's length limits: 35 characters for
+// identifiers and 70 for names.
+func (Pain001) Validate(b Batch) []Problem {
+	var problems []Problem
+	if utf8.RuneCountInString(b.ID) > 35 {
+		problems = append(problems, Problem{Message: "batch id is longer than 35 characters"})
+	}
+	for _, p := range b.Payments {
+		if utf8.RuneCountInString(p.Reference) > 35 {
+			problems = append(problems, Problem{p.EmployeeCode, "reference is longer than 35 characters"})
+		}
+		if utf8.RuneCountInString(p.Account.Name) > 70 {
+			problems = append(problems, Problem{p.EmployeeCode, "account name is longer than 70 characters"})
+		}
+	}
+	return problems
+}
+
+func (Pain001) Export(w io.Writer, b Batch) error {
+	total := b.ControlTotal()
+	doc := painDocument{
+		Xmlns: pain001Namespace,
+		Initiation: painInitiation{
+			GroupHeader: painGroupHeader{
+				MsgID:        b.ID,
+				CreDtTm:      b.CreatedAt.Format("2006-01-02T15:04:05"),
+				NbOfTxs:      total.Count,
+				CtrlSum:      painAmount(total.Amount),
+				InitgPtyName: b.Debtor.Name,
+			},
+			PaymentInfo: painPaymentInfo{
+				PmtInfID:    b.ID,
+				PmtMtd:      "TRF",
+				NbOfTxs:     total.Count,
+				CtrlSum:     painAmount(total.Amount),
+				CtgyPurp:    "SALA",
+				ReqdExctnDt: b.PayDate.Format("2006-01-02"),
+				DbtrName:    b.Debtor.Name,
+				DbtrAcct:    b.Debtor.Number,
+				DbtrAgt:     b.Debtor.BankCode,
+				ChrgBr:      "SLEV",
+			},
+		},
+	}
+	for _, p := range b.Payments {
+		doc.Initiation.PaymentInfo.Transactions = append(doc.Initiation.PaymentInfo.Transactions, painTransaction{
+			EndToEndID: p.Reference,
+			Amount:     painInstructedAmount{Ccy: "IDR", Value: painAmount(p.Amount)},
+			CdtrAgt:    p.Account.BankCode,
+			CdtrName:   p.Account.Name,
+			CdtrAcct:   p.Account.Number,
+			Purpose:    "SALA",
+			Remittance: fmt.Sprintf("Salary %s %s", b.PeriodCode, p.EmployeeCode),
+		})
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(doc); err != nil {
+		return err
+	}
+	return enc.Close()
+}
+
+// painAmount writes whole rupiah with the two decimals of IDR.
+func painAmount(amount int64) string {
+	return fmt.Sprintf("%d.00", amount)
+}
+
+type painDocument struct {
+	XMLName    xml.Name       `xml:"Document"`
+	Xmlns      string         `xml:"xmlns,attr"`
+	Initiation painInitiation `xml:"CstmrCdtTrfInitn"`
+}
+
+type painInitiation struct {
+	GroupHeader painGroupHeader `xml:"GrpHdr"`
+	PaymentInfo painPaymentInfo `xml:"PmtInf"`
+}
+
+type painGroupHeader struct {
+	MsgID        string `xml:"MsgId"`
+	CreDtTm      string `xml:"CreDtTm"`
+	NbOfTxs      int    `xml:"NbOfTxs"`
+	CtrlSum      string `xml:"CtrlSum"`
+	InitgPtyName string `xml:"InitgPty>Nm"`
+}
+
+type painPaymentInfo struct {
+	PmtInfID     string            `xml:"PmtInfId"`
+	PmtMtd       string            `xml:"PmtMtd"`
+	NbOfTxs      int               `xml:"NbOfTxs"`
+	CtrlSum      string            `xml:"CtrlSum"`
+	CtgyPurp     string            `xml:"PmtTpInf>CtgyPurp>Cd"`
+	ReqdExctnDt  string            `xml:"ReqdExctnDt"`
+	DbtrName     string            `xml:"Dbtr>Nm"`
+	DbtrAcct     string            `xml:"DbtrAcct>Id>Othr>Id"`
+	DbtrAgt      string            `xml:"DbtrAgt>FinInstnId>ClrSysMmbId>MmbId"`
+	ChrgBr       string            `xml:"ChrgBr"`
+	Transactions []painTransaction `xml:"CdtTrfTxInf"`
+}
+
+type painTransaction struct {
+	EndToEndID string               `xml:"PmtId>EndToEndId"`
+	Amount     painInstructedAmount `xml:"Amt>InstdAmt"`
+	CdtrAgt    string               `xml:"CdtrAgt>FinInstnId>ClrSysMmbId>MmbId"`
+	CdtrName   string               `xml:"Cdtr>Nm"`
+	CdtrAcct   string               `xml:"CdtrAcct>Id>Othr>Id"`
+	Purpose    string               `xml:"Purp>Cd"`
+	Remittance string               `xml:"RmtInf>Ustrd"`
+}
+
+type painInstructedAmount struct {
+	Ccy   string `xml:"Ccy,attr"`
+	Value string `xml:",chardata"`
+}
@@ -0,0 +1,285 @@
+package document
+
+import (
+	"archive/zip"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+
+	"github.com/go-pdf/fpdf"
+)
+
+const (
+	pageMargin = 15.0
+	rowHeight  = 6.0
+	dateFormat = "02 Jan 2006"
+)
+
+// Payslip is everything printed on one payslip document.
+type Payslip struct {
+	PeriodCode string
+	// Run names the off-cycle run the payslip belongs to, e.g. "THR"; empty
+	// for the regular run.
+	Run         string
+	PeriodStart time.Time
+	PeriodEnd   time.Time
+	PayDate     time.Time
+
+	EmployeeCode string
+	EmployeeName string
+	NPWP         string
+	PTKPStatus   string
+
+	Earnings              []Line
+	Deductions            []Line
+	EmployerContributions []Line
+	TotalEarnings         int64
+	TotalDeductions       int64
+	NetSalary             int64
+
+	TaxMethod       string
+	ProrationFactor float64
+	YTD             YTD
+}
+
+type Line struct {
+	Label  string
+	Amount int64
+}
+
+// YTD are the year-to-date totals up to and including the payslip's period.
+type YTD struct {
+	GrossIncome int64
+	IncomeTax   int64
+	NetSalary   int64
+}
+
+// Renderer draws payslips as PDF documents with a Template's branding.
+type Renderer struct {
+	tmpl compiled
+}
+
+func NewRenderer(t Template) (*Renderer, error) {
+	c, err := compile(t)
+	if err != nil {
+		return nil, err
+	}
+	return &Renderer{tmpl: c}, nil
+}
+
+// FileName is the template's file name for p.
+func (r *Renderer) FileName(p Payslip) (string, error) {
+	return execute(r.tmpl.fileName, p)
+}
+
+// Render writes p as a single-page PDF to w.
+func (r *Renderer) Render(w io.Writer, p Payslip) error {
+	title, err := execute(r.tmpl.title, p)
+	if err != nil {
+		return fmt.Errorf("payslip title: %w", err)
+	}
+	footer, err := execute(r.tmpl.footer, p)
+	if err != nil {
+		return fmt.Errorf("payslip footer: %w", err)
+	}
+
+	pdf := fpdf.New("P", "mm", "A4", "")
+	pdf.SetMargins(pageMargin, pageMargin, pageMargin)
+	pdf.SetTitle(title, true)
+	pdf.SetCreator(r.tmpl.CompanyName, true)
+	tr := pdf.UnicodeTranslatorFromDescriptor("")
+	pdf.SetFooterFunc(func() {
+		pdf.SetY(-pageMargin - rowHeight)
+		pdf.SetFont("Helvetica", "I", 8)
+		pdf.SetTextColor(110, 110, 110)
+		pdf.MultiCell(0, 4, tr(footer), "", "C", false)
+	})
+	pdf.AddPage()
+
+	d := drawer{pdf: pdf, tr: tr, accent: r.tmpl.accent}
+	d.header(r.tmpl.Template, title)
+	d.employee(p)
+	d.lines(p)
+	d.summary(p)
+	if err := pdf.Error(); err != nil {
+		return err
+	}
+	return pdf.Output(w)
+}
+
+// RenderBundle writes a zip archive with one PDF per payslip to w.
+func (r *Renderer) RenderBundle(w io.Writer, payslips []Payslip) error {
+	zw := zip.NewWriter(w)
+	for _, p := range payslips {
+		name, err := r.FileName(p)
+		if err != nil {
+			return fmt.Errorf("payslip file name: %w", err)
+		}
+		f, err := zw.Create(name)
+		if err != nil {
+			return err
+		}
+		if err := r.Render(f, p); err != nil {
+			return fmt.Errorf("render payslip of %s: %w", p.EmployeeCode, err)
+		}
+	}
+	return zw.Close()
+}
+
+type drawer struct {
+	pdf    *fpdf.Fpdf
+	tr     func(string) string
+	accent [3]int
+}
+
+func (d drawer) header(t Template, title string) {
+	pdf := d.pdf
+	x := pageMargin
+	if t.LogoPath != "" {
+		pdf.ImageOptions(t.LogoPath, pageMargin, pageMargin, 0, 16, false, fpdf.ImageOptions{ReadDpi: true}, 0, "")
+		x += 30
+	}
+	pdf.SetXY(x, pageMargin)
+	pdf.SetFont("Helvetica", "B", 15)
+	pdf.SetTextColor(0, 0, 0)
+	pdf.CellFormat(0, 8, d.tr(t.CompanyName), "", 2, "L", false, 0, "")
+	if t.CompanyAddress != "" {
+		pdf.SetFont("Helvetica", "", 9)
+		pdf.MultiCell(0, 4, d.tr(t.CompanyAddress), "", "L", false)
+	}
+
+	y := max(pdf.GetY(), pageMargin+16) + 3
+	pdf.SetDrawColor(d.accent[0], d.accent[1], d.accent[2])
+	pdf.SetLineWidth(0.6)
+	pdf.Line(pageMargin, y, 210-pageMargin, y)
+
+	pdf.SetXY(pageMargin, y+4)
+	pdf.SetFont("Helvetica", "B", 12)
+	pdf.SetTextColor(d.accent[0], d.accent[1], d.accent[2])
+	pdf.CellFormat(0, 8, d.tr(title), "", 1, "C", false, 0, "")
+	pdf.Ln(2)
+}
+
+func (d drawer) employee(p Payslip) {
+	pdf := d.pdf
+	rows := [][4]string{
+		{"Employee", fmt.Sprintf("%s (%s)", p.EmployeeName, p.EmployeeCode), "Period", p.PeriodStart.Format(dateFormat) + " - " + p.PeriodEnd.Format(dateFormat)},
+		{"NPWP", orDash(p.NPWP), "Pay date", p.PayDate.Format(dateFormat)},
+		{"PTKP status", p.PTKPStatus, "Days paid", strconv.FormatFloat(p.ProrationFactor*100, 'f', 2, 64) + "%"},
+	}
+	pdf.SetTextColor(0, 0, 0)
+	for _, row := range rows {
+		pdf.SetFont("Helvetica", "", 9)
+		pdf.CellFormat(28, 5, row[0], "", 0, "L", false, 0, "")
+		pdf.SetFont("Helvetica", "B", 9)
+		pdf.CellFormat(62, 5, d.tr(row[1]), "", 0, "L", false, 0, "")
+		pdf.SetFont("Helvetica", "", 9)
+		pdf.CellFormat(28, 5, row[2], "", 0, "L", false, 0, "")
+		pdf.SetFont("Helvetica", "B", 9)
+		pdf.CellFormat(0, 5, d.tr(row[3]), "", 1, "L", false, 0, "")
+	}
+	pdf.Ln(4)
+}
+
+// lines draws earnings and deductions side by side.
+func (d drawer) lines(p Payslip) {
+	pdf := d.pdf
+	half := (210 - 2*pageMargin - 4) / 2
+	labelW, amountW := half-32, 32.0
+	left, right := pageMargin, pageMargin+half+4
+
+	pdf.SetFillColor(d.accent[0], d.accent[1], d.accent[2])
+	pdf.SetTextColor(255, 255, 255)
+	pdf.SetFont("Helvetica", "B", 9)
+	y := pdf.GetY()
+	pdf.SetXY(left, y)
+	pdf.CellFormat(half, rowHeight, "Earnings", "", 0, "L", true, 0, "")
+	pdf.SetXY(right, y)
+	pdf.CellFormat(half, rowHeight, "Deductions", "", 1, "L", true, 0, "")
+
+	pdf.SetTextColor(0, 0, 0)
+	pdf.SetFont("Helvetica", "", 9)
+	y = pdf.GetY()
+	n := max(len(p.Earnings), len(p.Deductions))
+	for i := range n {
+		rowY := y + float64(i)*rowHeight
+		if i < len(p.Earnings) {
+			pdf.SetXY(left, rowY)
+			pdf.CellFormat(labelW, rowHeight, d.tr(p.Earnings[i].Label), "B", 0, "L", false, 0, "")
+			pdf.CellFormat(amountW, rowHeight, FormatRupiah(p.Earnings[i].Amount), "B", 0, "R", false, 0, "")
+		}
+		if i < len(p.Deductions) {
+			pdf.SetXY(right, rowY)
+			pdf.CellFormat(labelW, rowHeight, d.tr(p.Deductions[i].Label), "B", 0, "L", false, 0, "")
+			pdf.CellFormat(amountW, rowHeight, FormatRupiah(p.Deductions[i].Amount), "B", 0, "R", false, 0, "")
+		}
+	}
+
+	totalY := y + float64(n)*rowHeight
+	pdf.SetFont("Helvetica", "B", 9)
+	pdf.SetXY(left, totalY)
+	pdf.CellFormat(labelW, rowHeight, "Total earnings", "", 0, "L", false, 0, "")
+	pdf.CellFormat(amountW, rowHeight, FormatRupiah(p.TotalEarnings), "", 0, "R", false, 0, "")
+	pdf.SetXY(right, totalY)
+	pdf.CellFormat(labelW, rowHeight, "Total deductions", "", 0, "L", false, 0, "")
+	pdf.CellFormat(amountW, rowHeight, FormatRupiah(p.TotalDeductions), "", 1, "R", false, 0, "")
+	pdf.Ln(3)
+
+	pdf.SetFillColor(235, 240, 245)
+	pdf.SetFont("Helvetica", "B", 11)
+	pdf.CellFormat(210-2*pageMargin-40, 9, "Net salary", "", 0, "L", true, 0, "")
+	pdf.CellFormat(40, 9, FormatRupiah(p.NetSalary), "", 1, "R", true, 0, "")
+	pdf.Ln(5)
+}
+
+// summary draws employer contributions and the year-to-date totals.
+func (d drawer) summary(p Payslip) {
+	pdf := d.pdf
+	table := func(title string, rows []Line) {
+		pdf.SetTextColor(d.accent[0], d.accent[1], d.accent[2])
+		pdf.SetFont("Helvetica", "B", 9)
+		pdf.CellFormat(0, rowHeight, d.tr(title), "", 1, "L", false, 0, "")
+		pdf.SetTextColor(0, 0, 0)
+		pdf.SetFont("Helvetica", "", 9)
+		for _, l := range rows {
+			pdf.CellFormat(80, 5, d.tr(l.Label), "", 0, "L", false, 0, "")
+			pdf.CellFormat(40, 5, FormatRupiah(l.Amount), "", 1, "R", false, 0, "")
+		}
+		pdf.Ln(3)
+	}
+
+	if len(p.EmployerContributions) > 0 {
+		table("Paid by the employer (not deducted)", p.EmployerContributions)
+	}
+	table(fmt.Sprintf("Year to date %d", p.PeriodEnd.Year()), []Line{
+		{Label: "Gross income", Amount: p.YTD.GrossIncome},
+		{Label: "PPh 21", Amount: p.YTD.IncomeTax},
+		{Label: "Net salary", Amount: p.YTD.NetSalary},
+	})
+}
+
+func orDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
+
+// FormatRupiah formats an amount as "Rp 1.234.567".
+func FormatRupiah(amount int64) string {
+	sign := ""
+	if amount < 0 {
+		sign = "-"
+		amount = -amount
+	}
+	digits := strconv.FormatInt(amount, 10)
+	out := make([]byte, 0, len(digits)+len(digits)/3)
+	for i := range len(digits) {
+		if i > 0 && (len(digits)-i)%3 == 0 {
+			out = append(out, '.')
+		}
+		out = append(out, digits[i])
+	}
+	return sign + "Rp " + string(out)
+}
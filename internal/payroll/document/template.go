@@ -0,0 +1,107 @@
+package document
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"text/template"
+)
+
+// Template brands payslip documents. Title, Footer and FileName are
+// text/template sources executed with the Payslip being rendered, e.g.
+// "Payslip {{.PeriodCode}} - {{.EmployeeName}}".
+type Template struct {
+	CompanyName    string `json:"company_name"`
+	CompanyAddress string `json:"company_address"`
+	// LogoPath is a PNG or JPEG file drawn at the top left of the header.
+	LogoPath string `json:"logo_path"`
+	// AccentColor is a #RRGGBB colour for rules and table headings.
+	AccentColor string `json:"accent_color"`
+	Title       string `json:"title"`
+	Footer      string `json:"footer"`
+	FileName    string `json:"file_name"`
+}
+
+func DefaultTemplate() Template {
+	return Template{
+		CompanyName: "Payroll",
+		AccentColor: "#1F4E79",
+		Title:       "PAYSLIP {{.PeriodCode}}{{with .Run}} {{.}}{{end}}",
+		Footer:      "This payslip is generated electronically and is valid without a signature.",
+		FileName:    "payslip-{{.PeriodCode}}{{with .Run}}-{{.}}{{end}}-{{.EmployeeCode}}.pdf",
+	}
+}
+
+// LoadTemplate reads a JSON template from path. Fields left out keep their
+// DefaultTemplate value.
+func LoadTemplate(path string) (Template, error) {
+	t := DefaultTemplate()
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return Template{}, err
+	}
+	if err := json.Unmarshal(raw, &t); err != nil {
+		return Template{}, fmt.Errorf("parse payslip template %s: %w", path, err)
+	}
+	return t, nil
+}
+
+// compiled is a Template with its text fields parsed.
+type compiled struct {
+	Template
+	accent   [3]int
+	title    *template.Template
+	footer   *template.Template
+	fileName *template.Template
+}
+
+func compile(t Template) (compiled, error) {
+	c := compiled{Template: t}
+
+	var err error
+	if c.accent, err = parseColor(t.AccentColor); err != nil {
+		return compiled{}, err
+	}
+	for _, f := range []struct {
+		name string
+		src  string
+		dst  **template.Template
+	}{
+		{"title", t.Title, &c.title},
+		{"footer", t.Footer, &c.footer},
+		{"file_name", t.FileName, &c.fileName},
+	} {
+		tmpl, err := template.New(f.name).Option("missingkey=error").Parse(f.src)
+		if err != nil {
+			return compiled{}, fmt.Errorf("payslip template %s: %w", f.name, err)
+		}
+		*f.dst = tmpl
+	}
+	return c, nil
+}
+
+func execute(t *template.Template, p Payslip) (string, error) {
+	var sb strings.Builder
+	if err := t.Execute(&sb, p); err != nil {
+		return "", err
+	}
+	return sb.String(), nil
+}
+
+func parseColor(hex string) ([3]int, error) {
+	s := strings.TrimPrefix(hex, "#")
+	if len(s) != 6 {
+		return [3]int{}, fmt.Errorf("accent colour %q is not #RRGGBB", hex)
+	}
+	var rgb [3]int
+	for i := range rgb {
+		v, err := strconv.ParseUint(s[i*2:i*2+2], 16, 8)
+		if err != nil {
+			return [3]int{}, fmt.Errorf("accent colour %q is not #RRGGBB", hex)
+		}
+		rgb[i] = int(v)
+	}
+	return rgb, nil
+}
@@ -0,0 +1,507 @@
+package formula
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Formulas are small arithmetic expressions over named numeric variables,
+// for example `days_present * 25000` or `min(base_salary * 0.1, 1000000)`.
+// The language has numbers, variables, + - * /, comparisons, && || !,
+// parentheses and a fixed set of functions. There is no way to reach
+// anything outside the variables handed to Eval.
+
+const (
+	maxLength = 1000
+	maxDepth  = 50
+)
+
+var (
+	ErrSyntax          = errors.New("syntax error")
+	ErrUnknownVariable = errors.New("unknown variable")
+	ErrUnknownFunction = errors.New("unknown function")
+	ErrDivisionByZero  = errors.New("division by zero")
+	ErrCycle           = errors.New("dependency cycle")
+)
+
+type Expr struct {
+	src  string
+	root node
+	vars []string
+}
+
+// Parse compiles a formula. The returned Expr can be evaluated many times.
+func Parse(src string) (*Expr, error) {
+	if len(src) > maxLength {
+		return nil, fmt.Errorf("%w: formula longer than %d characters", ErrSyntax, maxLength)
+	}
+	toks, err := tokenize(src)
+	if err != nil {
+		return nil, err
+	}
+	p := &parser{toks: toks}
+	root, err := p.parseExpr(0, 0)
+	if err != nil {
+		return nil, err
+	}
+	if t := p.peek(); t.kind != tokEOF {
+		return nil, fmt.Errorf("%w: unexpected %q at position %d", ErrSyntax, t.text, t.pos)
+	}
+
+	seen := map[string]bool{}
+	var vars []string
+	collectVars(root, seen, &vars)
+	sort.Strings(vars)
+
+	return &Expr{src: src, root: root, vars: vars}, nil
+}
+
+func (e *Expr) String() string {
+	return e.src
+}
+
+// Variables lists the names the formula reads, sorted.
+func (e *Expr) Variables() []string {
+	return e.vars
+}
+
+// Eval computes the formula. Every variable the formula reads must be
+// present in vars.
+func (e *Expr) Eval(vars map[string]float64) (float64, error) {
+	return e.root.eval(vars)
+}
+
+// Order returns the keys of exprs so that every formula comes after the
+// formulas it reads. References to names that are not keys of exprs are
+// ignored. A cycle is reported with the names involved.
+func Order(exprs map[string]*Expr) ([]string, error) {
+	names := make([]string, 0, len(exprs))
+	for name := range exprs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	const (
+		unvisited = iota
+		visiting
+		done
+	)
+	state := map[string]int{}
+	var order, stack []string
+
+	var visit func(name string) error
+	visit = func(name string) error {
+		switch state[name] {
+		case done:
+			return nil
+		case visiting:
+			start := 0
+			for i, n := range stack {
+				if n == name {
+					start = i
+				}
+			}
+			path := append(append([]string{}, stack[start:]...), name)
+			return fmt.Errorf("%w: %s", ErrCycle, strings.Join(path, " -> "))
+		}
+		state[name] = visiting
+		stack = append(stack, name)
+		for _, dep := range exprs[name].vars {
+			if _, ok := exprs[dep]; !ok {
+				continue
+			}
+			if err := visit(dep); err != nil {
+				return err
+			}
+		}
+		stack = stack[:len(stack)-1]
+		state[name] = done
+		order = append(order, name)
+		return nil
+	}
+
+	for _, name := range names {
+		if err := visit(name); err != nil {
+			return nil, err
+		}
+	}
+	return order, nil
+}
+
+func collectVars(n node, seen map[string]bool, out *[]string) {
+	switch n := n.(type) {
+	case varNode:
+		if !seen[string(n)] {
+			seen[string(n)] = true
+			*out = append(*out, string(n))
+		}
+	case unaryNode:
+		collectVars(n.x, seen, out)
+	case binaryNode:
+		collectVars(n.x, seen, out)
+		collectVars(n.y, seen, out)
+	case callNode:
+		for _, a := range n.args {
+			collectVars(a, seen, out)
+		}
+	}
+}
+
+// --- evaluation ---
+
+type node interface {
+	eval(vars map[string]float64) (float64, error)
+}
+
+type numNode float64
+
+func (n numNode) eval(map[string]float64) (float64, error) {
+	return float64(n), nil
+}
+
+type varNode string
+
+func (n varNode) eval(vars map[string]float64) (float64, error) {
+	v, ok := vars[string(n)]
+	if !ok {
+		return 0, fmt.Errorf("%w %q", ErrUnknownVariable, string(n))
+	}
+	return v, nil
+}
+
+type unaryNode struct {
+	op string
+	x  node
+}
+
+func (n unaryNode) eval(vars map[string]float64) (float64, error) {
+	x, err := n.x.eval(vars)
+	if err != nil {
+		return 0, err
+	}
+	switch n.op {
+	case "-":
+		return -x, nil
+	case "!":
+		return boolean(x == 0), nil
+	}
+	return x, nil
+}
+
+type binaryNode struct {
+	op   string
+	x, y node
+}
+
+func (n binaryNode) eval(vars map[string]float64) (float64, error) {
+	x, err := n.x.eval(vars)
+	if err != nil {
+		return 0, err
+	}
+	// && and || short-circuit so guards like `working_days > 0 && ...` work.
+	switch n.op {
+	case "&&":
+		if x == 0 {
+			return 0, nil
+		}
+	case "||":
+		if x != 0 {
+			return 1, nil
+		}
+	}
+	y, err := n.y.eval(vars)
+	if err != nil {
+		return 0, err
+	}
+	switch n.op {
+	case "+":
+		return x + y, nil
+	case "-":
+		return x - y, nil
+	case "*":
+		return x * y, nil
+	case "/":
+		if y == 0 {
+			return 0, ErrDivisionByZero
+		}
+		return x / y, nil
+	case "<":
+		return boolean(x < y), nil
+	case "<=":
+		return boolean(x <= y), nil
+	case ">":
+		return boolean(x > y), nil
+	case ">=":
+		return boolean(x >= y), nil
+	case "==":
+		return boolean(x == y), nil
+	case "!=":
+		return boolean(x != y), nil
+	case "&&", "||":
+		return boolean(y != 0), nil
+	}
+	return 0, fmt.Errorf("%w: unknown operator %q", ErrSyntax, n.op)
+}
+
+type callNode struct {
+	name string
+	args []node
+}
+
+var functions = map[string]struct {
+	arity int // -1 means one or more
+	fn    func(args []float64) float64
+}{
+	"min": {-1, func(a []float64) float64 {
+		m := a[0]
+		for _, v := range a[1:] {
+			m = math.Min(m, v)
+		}
+		return m
+	}},
+	"max": {-1, func(a []float64) float64 {
+		m := a[0]
+		for _, v := range a[1:] {
+			m = math.Max(m, v)
+		}
+		return m
+	}},
+	"round": {1, func(a []float64) float64 { return math.Round(a[0]) }},
+	"floor": {1, func(a []float64) float64 { return math.Floor(a[0]) }},
+	"ceil":  {1, func(a []float64) float64 { return math.Ceil(a[0]) }},
+	"abs":   {1, func(a []float64) float64 { return math.Abs(a[0]) }},
+}
+
+func (n callNode) eval(vars map[string]float64) (float64, error) {
+	// if() evaluates only the branch it takes.
+	if n.name == "if" {
+		cond, err := n.args[0].eval(vars)
+		if err != nil {
+			return 0, err
+		}
+		if cond != 0 {
+			return n.args[1].eval(vars)
+		}
+		return n.args[2].eval(vars)
+	}
+
+	args := make([]float64, len(n.args))
+	for i, a := range n.args {
+		v, err := a.eval(vars)
+		if err != nil {
+			return 0, err
+		}
+		args[i] = v
+	}
+	return functions[n.name].fn(args), nil
+}
+
+func boolean(b bool) float64 {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+// --- parsing ---
+
+const (
+	tokEOF = iota
+	tokNum
+	tokIdent
+	tokOp
+)
+
+type token struct {
+	kind int
+	text string
+	pos  int
+}
+
+func tokenize(src string) ([]token, error) {
+	var toks []token
+	i := 0
+	for i < len(src) {
+		c := src[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			i++
+		case isDigit(c) || c == '.':
+			start := i
+			for i < len(src) && (isDigit(src[i]) || src[i] == '.' || src[i] == '_') {
+				i++
+			}
+			toks = append(toks, token{tokNum, src[start:i], start})
+		case isIdentStart(c):
+			start := i
+			for i < len(src) && (isIdentStart(src[i]) || isDigit(src[i])) {
+				i++
+			}
+			toks = append(toks, token{tokIdent, src[start:i], start})
+		default:
+			if i+1 < len(src) {
+				two := src[i : i+2]
+				switch two {
+				case "<=", ">=", "==", "!=", "&&", "||":
+					toks = append(toks, token{tokOp, two, i})
+					i += 2
+					continue
+				}
+			}
+			if strings.IndexByte("+-*/()<>!,", c) < 0 {
+				return nil, fmt.Errorf("%w: unexpected character %q at position %d", ErrSyntax, c, i)
+			}
+			toks = append(toks, token{tokOp, string(c), i})
+			i++
+		}
+	}
+	return append(toks, token{tokEOF, "end of formula", len(src)}), nil
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+func isIdentStart(c byte) bool {
+	return c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+var precedence = map[string]int{
+	"||": 1,
+	"&&": 2,
+	"==": 3, "!=": 3,
+	"<": 4, "<=": 4, ">": 4, ">=": 4,
+	"+": 5, "-": 5,
+	"*": 6, "/": 6,
+}
+
+type parser struct {
+	toks []token
+	pos  int
+}
+
+func (p *parser) peek() token {
+	return p.toks[p.pos]
+}
+
+func (p *parser) next() token {
+	t := p.toks[p.pos]
+	if t.kind != tokEOF {
+		p.pos++
+	}
+	return t
+}
+
+func (p *parser) expect(text string) error {
+	t := p.next()
+	if t.kind != tokOp || t.text != text {
+		return fmt.Errorf("%w: expected %q at position %d, found %q", ErrSyntax, text, t.pos, t.text)
+	}
+	return nil
+}
+
+func (p *parser) parseExpr(minPrec, depth int) (node, error) {
+	if depth > maxDepth {
+		return nil, fmt.Errorf("%w: formula nested too deeply", ErrSyntax)
+	}
+	left, err := p.parseUnary(depth)
+	if err != nil {
+		return nil, err
+	}
+	for {
+		t := p.peek()
+		prec, ok := precedence[t.text]
+		if t.kind != tokOp || !ok || prec <= minPrec {
+			return left, nil
+		}
+		p.next()
+		right, err := p.parseExpr(prec, depth+1)
+		if err != nil {
+			return nil, err
+		}
+		left = binaryNode{op: t.text, x: left, y: right}
+	}
+}
+
+func (p *parser) parseUnary(depth int) (node, error) {
+	t := p.peek()
+	if t.kind == tokOp && (t.text == "-" || t.text == "+" || t.text == "!") {
+		p.next()
+		x, err := p.parseUnary(depth + 1)
+		if err != nil {
+			return nil, err
+		}
+		return unaryNode{op: t.text, x: x}, nil
+	}
+	return p.parsePrimary(depth)
+}
+
+func (p *parser) parsePrimary(depth int) (node, error) {
+	t := p.next()
+	switch t.kind {
+	case tokNum:
+		v, err := strconv.ParseFloat(strings.ReplaceAll(t.text, "_", ""), 64)
+		if err != nil {
+			return nil, fmt.Errorf("%w: bad number %q at position %d", ErrSyntax, t.text, t.pos)
+		}
+		return numNode(v), nil
+
+	case tokIdent:
+		if p.peek().text != "(" {
+			return varNode(t.text), nil
+		}
+		p.next()
+		var args []node
+		if p.peek().text != ")" {
+			for {
+				a, err := p.parseExpr(0, depth+1)
+				if err != nil {
+					return nil, err
+				}
+				args = append(args, a)
+				if p.peek().text != "," {
+					break
+				}
+				p.next()
+			}
+		}
+		if err := p.expect(")"); err != nil {
+			return nil, err
+		}
+		return newCall(t, args)
+
+	case tokOp:
+		if t.text == "(" {
+			x, err := p.parseExpr(0, depth+1)
+			if err != nil {
+				return nil, err
+			}
+			if err := p.expect(")"); err != nil {
+				return nil, err
+			}
+			return x, nil
+		}
+	}
+	return nil, fmt.Errorf("%w: unexpected %q at position %d", ErrSyntax, t.text, t.pos)
+}
+
+func newCall(t token, args []node) (node, error) {
+	if t.text == "if" {
+		if len(args) != 3 {
+			return nil, fmt.Errorf("%w: if() takes 3 arguments at position %d", ErrSyntax, t.pos)
+		}
+		return callNode{name: t.text, args: args}, nil
+	}
+	f, ok := functions[t.text]
+	if !ok {
+		return nil, fmt.Errorf("%w %q at position %d", ErrUnknownFunction, t.text, t.pos)
+	}
+	if (f.arity < 0 && len(args) == 0) || (f.arity >= 0 && len(args) != f.arity) {
+		return nil, fmt.Errorf("%w: wrong number of arguments to %s() at position %d", ErrSyntax, t.text, t.pos)
+	}
+	return callNode{name: t.text, args: args}, nil
+}
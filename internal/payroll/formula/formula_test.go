@@ -0,0 +1,161 @@
+package formula
+
+import (
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestEval(t *testing.T) {
+	tests := []struct {
+		src  string
+		vars map[string]float64
+		want float64
+	}{
+		{"days_present * 25000", map[string]float64{"days_present": 20}, 500000},
+		{"min(base_salary * 0.1, 1000000)", map[string]float64{"base_salary": 15000000}, 1000000},
+		{"min(base_salary * 0.1, 1000000)", map[string]float64{"base_salary": 5000000}, 500000},
+		{"1 + 2 * 3", nil, 7},
+		{"(1 + 2) * 3", nil, 9},
+		{"10 - 4 - 3", nil, 3},
+		{"12 / 4 / 3", nil, 1},
+		{"-x + 5", map[string]float64{"x": 2}, 3},
+		{"!(x > 1)", map[string]float64{"x": 2}, 0},
+		{"x >= 2 && x != 3", map[string]float64{"x": 2}, 1},
+		{"x < 1 || x == 2", map[string]float64{"x": 2}, 1},
+		{"max(1, 7, 3)", nil, 7},
+		{"round(2.5) + floor(1.9) + ceil(1.1) + abs(-1)", nil, 3 + 1 + 2 + 1},
+		{"if(years_of_service >= 5, 500000, 0)", map[string]float64{"years_of_service": 6}, 500000},
+		{"if(years_of_service >= 5, 500000, 0)", map[string]float64{"years_of_service": 4}, 0},
+		// Guards short-circuit, so the division is never evaluated.
+		{"working_days > 0 && days_present / working_days >= 0.9", map[string]float64{"working_days": 0, "days_present": 0}, 0},
+		{"if(working_days == 0, 0, days_present / working_days)", map[string]float64{"working_days": 0, "days_present": 0}, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.src, func(t *testing.T) {
+			e, err := Parse(tt.src)
+			if err != nil {
+				t.Fatalf("Parse() error = %v", err)
+			}
+			got, err := e.Eval(tt.vars)
+			if err != nil {
+				t.Fatalf("Eval() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("Eval() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		src  string
+		want error
+	}{
+		{"", ErrSyntax},
+		{"1 +", ErrSyntax},
+		{"(1 + 2", ErrSyntax},
+		{"1 2", ErrSyntax},
+		{"base_salary; drop", ErrSyntax},
+		{"min()", ErrSyntax},
+		{"round(1, 2)", ErrSyntax},
+		{"if(1, 2)", ErrSyntax},
+		{"exec(1)", ErrUnknownFunction},
+		{strings.Repeat("1+", 600) + "1", ErrSyntax},
+		{strings.Repeat("(", 60) + "1" + strings.Repeat(")", 60), ErrSyntax},
+	}
+	for _, tt := range tests {
+		name := tt.src
+		if len(name) > 20 {
+			name = name[:20] + "..."
+		}
+		t.Run(name, func(t *testing.T) {
+			if _, err := Parse(tt.src); !errors.Is(err, tt.want) {
+				t.Errorf("Parse() error = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestEvalErrors(t *testing.T) {
+	tests := []struct {
+		src  string
+		vars map[string]float64
+		want error
+	}{
+		{"days_present * 25000", nil, ErrUnknownVariable},
+		{"base_salary / working_days", map[string]float64{"base_salary": 1, "working_days": 0}, ErrDivisionByZero},
+	}
+	for _, tt := range tests {
+		t.Run(tt.src, func(t *testing.T) {
+			e, err := Parse(tt.src)
+			if err != nil {
+				t.Fatalf("Parse() error = %v", err)
+			}
+			if _, err := e.Eval(tt.vars); !errors.Is(err, tt.want) {
+				t.Errorf("Eval() error = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestVariables(t *testing.T) {
+	e, err := Parse("min(base_salary * 0.1, allowance) + base_salary")
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	if got, want := e.Variables(), []string{"allowance", "base_salary"}; !slices.Equal(got, want) {
+		t.Errorf("Variables() = %v, want %v", got, want)
+	}
+}
+
+func TestOrder(t *testing.T) {
+	tests := []struct {
+		name    string
+		exprs   map[string]string
+		want    []string
+		wantErr error
+	}{
+		{
+			name:  "dependencies first",
+			exprs: map[string]string{"a": "b + c", "b": "c * 2", "c": "base_salary"},
+			want:  []string{"c", "b", "a"},
+		},
+		{
+			name:  "independent by name",
+			exprs: map[string]string{"meal": "days_present * 25000", "transport": "days_present * 30000"},
+			want:  []string{"meal", "transport"},
+		},
+		{
+			name:    "cycle",
+			exprs:   map[string]string{"a": "b", "b": "c", "c": "a"},
+			wantErr: ErrCycle,
+		},
+		{
+			name:    "self reference",
+			exprs:   map[string]string{"a": "a + 1"},
+			wantErr: ErrCycle,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			exprs := map[string]*Expr{}
+			for name, src := range tt.exprs {
+				e, err := Parse(src)
+				if err != nil {
+					t.Fatalf("Parse(%q) error = %v", src, err)
+				}
+				exprs[name] = e
+			}
+			got, err := Order(exprs)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Order() error = %v, want %v", err, tt.wantErr)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("Order() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,117 @@
+package importer
+
+import (
+	"fmt"
+	"go-payroll-service/internal/payroll/model/request"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+)
+
+// EmployeeColumns are the columns of an employee import file, in the order
+// a template lists them. code, full_name, email, base_salary and hire_date
+// are required.
+var EmployeeColumns = []string{
+	"code", "full_name", "email", "department", "religion", "base_salary", "allowance", "ptkp_status", "npwp", "hire_date",
+}
+
+// excelEpoch is day 0 of Excel's 1900 date system, as dates are serial
+// numbers in XLSX files.
+var excelEpoch = time.Date(1899, time.December, 30, 0, 0, 0, 0, time.UTC)
+
+// requestValidator checks requests against the same binding tags gin checks
+// JSON bodies against, naming fields by their JSON names.
+var requestValidator = func() *validator.Validate {
+	v := validator.New()
+	v.SetTagName("binding")
+	v.RegisterTagNameFunc(func(f reflect.StructField) string {
+		return strings.Split(f.Tag.Get("json"), ",")[0]
+	})
+	return v
+}()
+
+// ParseEmployee turns a row into a create request, returning every problem
+// with the row rather than the first.
+func ParseEmployee(row Row) (request.CreateEmployeeRequest, []string) {
+	var problems []string
+	req := request.CreateEmployeeRequest{
+		Code:       row.Get("code"),
+		FullName:   row.Get("full_name"),
+		Email:      row.Get("email"),
+		Department: row.Get("department"),
+		Religion:   strings.ToLower(row.Get("religion")),
+		PTKPStatus: strings.ToUpper(row.Get("ptkp_status")),
+		NPWP:       row.Get("npwp"),
+	}
+
+	var err error
+	if req.BaseSalary, err = parseAmount(row.Get("base_salary")); err != nil {
+		problems = append(problems, "base_salary: "+err.Error())
+	}
+	if req.Allowance, err = parseAmount(row.Get("allowance")); err != nil {
+		problems = append(problems, "allowance: "+err.Error())
+	}
+	if v := row.Get("hire_date"); v == "" {
+		problems = append(problems, "hire_date: is required")
+	} else if req.HireDate, err = parseDate(v); err != nil {
+		problems = append(problems, "hire_date: "+err.Error())
+	}
+
+	if err := requestValidator.Struct(req); err != nil {
+		if errs, ok := err.(validator.ValidationErrors); ok {
+			for _, fe := range errs {
+				// A value that did not parse is already reported.
+				if !slices.ContainsFunc(problems, func(p string) bool { return strings.HasPrefix(p, fe.Field()+":") }) {
+					problems = append(problems, fieldProblem(fe))
+				}
+			}
+		} else {
+			problems = append(problems, err.Error())
+		}
+	}
+	return req, problems
+}
+
+func fieldProblem(fe validator.FieldError) string {
+	switch fe.Tag() {
+	case "required":
+		return fe.Field() + ": is required"
+	case "email":
+		return fe.Field() + ": is not an email address"
+	case "oneof":
+		return fe.Field() + ": must be one of " + fe.Param()
+	}
+	return fmt.Sprintf("%s: fails %s", fe.Field(), fe.Tag())
+}
+
+// parseAmount reads a whole rupiah amount; spreadsheets may store it as a
+// float. Blank is zero.
+func parseAmount(s string) (int64, error) {
+	if s == "" {
+		return 0, nil
+	}
+	if n, err := strconv.ParseInt(s, 10, 64); err == nil {
+		return n, nil
+	}
+	f, err := strconv.ParseFloat(s, 64)
+	if err != nil || f != float64(int64(f)) {
+		return 0, fmt.Errorf("%q is not a whole amount", s)
+	}
+	return int64(f), nil
+}
+
+// parseDate reads YYYY-MM-DD, or an Excel date serial number.
+func parseDate(s string) (time.Time, error) {
+	if t, err := time.Parse("2006-01-02", s); err == nil {
+		return t, nil
+	}
+	serial, err := strconv.ParseFloat(s, 64)
+	if err != nil || serial < 1 {
+		return time.Time{}, fmt.Errorf("%q is not a YYYY-MM-DD date", s)
+	}
+	return excelEpoch.AddDate(0, 0, int(serial)), nil
+}
@@ -0,0 +1,58 @@
+package importer
+
+import (
+	"maps"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestParseEmployee(t *testing.T) {
+	valid := map[string]string{
+		"code": "E1", "full_name": "Budi Santoso", "email": "budi@example.com", "religion": "Islam",
+		"base_salary": "10000000", "allowance": "2000000.0", "ptkp_status": "k/1", "hire_date": "2024-07-01",
+	}
+	with := func(changes map[string]string) map[string]string {
+		out := maps.Clone(valid)
+		maps.Copy(out, changes)
+		return out
+	}
+	tests := []struct {
+		name         string
+		fields       map[string]string
+		wantProblems []string
+	}{
+		{"valid", valid, nil},
+		{"excel date serial", with(map[string]string{"hire_date": "45474"}), nil},
+		{"missing required", with(map[string]string{"code": "", "email": " ", "hire_date": ""}), []string{"hire_date: is required", "code: is required", "email: is required"}},
+		{
+			"bad values",
+			with(map[string]string{"email": "budi", "religion": "other", "ptkp_status": "K/4"}),
+			[]string{"email: is not an email address", "religion: must be one of islam protestant catholic hindu buddhist confucian", "ptkp_status: must be one of TK/0 TK/1 TK/2 TK/3 K/0 K/1 K/2 K/3"},
+		},
+		{
+			// An amount that does not parse is reported once, not again as
+			// missing.
+			"unparsable values",
+			with(map[string]string{"base_salary": "10jt", "allowance": "1.5", "hire_date": "01/07/2024"}),
+			[]string{`base_salary: "10jt" is not a whole amount`, `allowance: "1.5" is not a whole amount`, `hire_date: "01/07/2024" is not a YYYY-MM-DD date`},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, problems := ParseEmployee(Row{Line: 2, Fields: tt.fields})
+			if !slices.Equal(problems, tt.wantProblems) {
+				t.Fatalf("ParseEmployee() problems = %q, want %q", problems, tt.wantProblems)
+			}
+			if tt.wantProblems != nil {
+				return
+			}
+			if req.Code != "E1" || req.Religion != "islam" || req.PTKPStatus != "K/1" || req.BaseSalary != 10000000 || req.Allowance != 2000000 {
+				t.Errorf("ParseEmployee() = %+v", req)
+			}
+			if want := time.Date(2024, time.July, 1, 0, 0, 0, 0, time.UTC); !req.HireDate.Equal(want) {
+				t.Errorf("ParseEmployee() hire date = %v, want %v", req.HireDate, want)
+			}
+		})
+	}
+}
@@ -0,0 +1,136 @@
+// Package importer reads tabular files of records to import, CSV or XLSX,
+// into rows keyed by their header, and turns employee rows into create
+// requests.
+package importer
+
+import (
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+const (
+	FormatCSV  = "csv"
+	FormatXLSX = "xlsx"
+)
+
+var (
+	ErrNoHeader    = errors.New("file has no header row")
+	ErrTooManyRows = errors.New("file has too many rows")
+)
+
+// Row is one data row. Line is where it sits in the file, its line in a CSV
+// file or its row number in a worksheet, so it can be reported back to
+// whoever fixes the file.
+type Row struct {
+	Line   int
+	Fields map[string]string
+}
+
+// Get returns the trimmed value of the named column, "" if absent.
+func (r Row) Get(column string) string {
+	return strings.TrimSpace(r.Fields[column])
+}
+
+// Read reads the rows of data in the given format, stopping with
+// ErrTooManyRows as soon as there are more than maxRows of them; zero reads
+// every row. Header names are matched case-insensitively; blank rows are
+// skipped and not counted.
+func Read(format string, data []byte, maxRows int) ([]Row, error) {
+	c := &collector{maxRows: maxRows}
+	var err error
+	switch format {
+	case FormatCSV:
+		err = readCSV(bytes.NewReader(data), c)
+	case FormatXLSX:
+		err = readXLSX(bytes.NewReader(data), int64(len(data)), c)
+	default:
+		return nil, fmt.Errorf("unknown format %q, expected %s or %s", format, FormatCSV, FormatXLSX)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if c.header == nil {
+		return nil, ErrNoHeader
+	}
+	return c.rows, nil
+}
+
+// FormatOf guesses the format from a file name's extension.
+func FormatOf(fileName string) string {
+	switch {
+	case strings.HasSuffix(strings.ToLower(fileName), ".csv"):
+		return FormatCSV
+	case strings.HasSuffix(strings.ToLower(fileName), ".xlsx"):
+		return FormatXLSX
+	}
+	return ""
+}
+
+// collector turns records into rows as a reader yields them, the first
+// being the header.
+type collector struct {
+	maxRows int
+	header  []string
+	rows    []Row
+}
+
+// width is how many columns of a record are read: the header's, once it is
+// known, as a value under no header has no name to be read by.
+func (c *collector) width() int {
+	if c.header == nil {
+		return maxColumns
+	}
+	return len(c.header)
+}
+
+// add takes the record found on the given line.
+func (c *collector) add(line int, rec []string) error {
+	if c.header == nil {
+		c.header = make([]string, len(rec))
+		for i, name := range rec {
+			c.header[i] = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))
+		}
+		return nil
+	}
+
+	row := Row{Line: line, Fields: map[string]string{}}
+	blank := true
+	for j, v := range rec[:min(len(rec), len(c.header))] {
+		if c.header[j] != "" {
+			row.Fields[c.header[j]] = v
+		}
+		blank = blank && strings.TrimSpace(v) == ""
+	}
+	if blank {
+		return nil
+	}
+	if c.maxRows > 0 && len(c.rows) == c.maxRows {
+		return fmt.Errorf("%w, at most %d can be read", ErrTooManyRows, c.maxRows)
+	}
+	c.rows = append(c.rows, row)
+	return nil
+}
+
+func readCSV(r io.Reader, c *collector) error {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+	for {
+		rec, err := cr.Read()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		// A quoted field may span lines, so the count of records is not
+		// the line.
+		line, _ := cr.FieldPos(0)
+		if err := c.add(line, rec); err != nil {
+			return err
+		}
+	}
+}
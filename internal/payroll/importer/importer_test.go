@@ -0,0 +1,264 @@
+package importer
+
+import (
+	"archive/zip"
+	"bytes"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// workbook zips a minimal XLSX file around the given sheetData rows and
+// shared strings.
+func workbook(t *testing.T, rows string, shared ...string) []byte {
+	t.Helper()
+	var sst strings.Builder
+	for _, s := range shared {
+		sst.WriteString("<si><t>" + s + "</t></si>")
+	}
+	parts := map[string]string{
+		"xl/workbook.xml": `<workbook xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships">` +
+			`<sheets><sheet name="Employees" sheetId="1" r:id="rId1"/></sheets></workbook>`,
+		"xl/_rels/workbook.xml.rels": `<Relationships><Relationship Id="rId1" Target="worksheets/sheet1.xml"/></Relationships>`,
+		"xl/worksheets/sheet1.xml":   `<worksheet><sheetData>` + rows + `</sheetData></worksheet>`,
+		"xl/sharedStrings.xml":       `<sst>` + sst.String() + `</sst>`,
+	}
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for _, name := range slices.Sorted(maps.Keys(parts)) {
+		w, err := zw.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := w.Write([]byte(parts[name])); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestReadCSV(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+		want []Row
+	}{
+		{
+			"header matched case-insensitively",
+			"\ufeffCode, Full_Name ,EMAIL\nE1,Budi,budi@example.com\n",
+			[]Row{{Line: 2, Fields: map[string]string{"code": "E1", "full_name": "Budi", "email": "budi@example.com"}}},
+		},
+		{
+			"blank rows keep their lines",
+			"code,full_name\nE1,Budi\n,\n\nE2,Siti\n",
+			[]Row{
+				{Line: 2, Fields: map[string]string{"code": "E1", "full_name": "Budi"}},
+				{Line: 5, Fields: map[string]string{"code": "E2", "full_name": "Siti"}},
+			},
+		},
+		{
+			"quoted field over two lines",
+			"code,department\nE1,\"Finance\nand Tax\"\nE2,HR\n",
+			[]Row{
+				{Line: 2, Fields: map[string]string{"code": "E1", "department": "Finance\nand Tax"}},
+				{Line: 4, Fields: map[string]string{"code": "E2", "department": "HR"}},
+			},
+		},
+		{
+			"columns past the header and unnamed ones dropped",
+			"code,,npwp\nE1,x,123,extra\n",
+			[]Row{{Line: 2, Fields: map[string]string{"code": "E1", "npwp": "123"}}},
+		},
+		{"header only", "code,full_name\n", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Read(FormatCSV, []byte(tt.data), 0)
+			if err != nil {
+				t.Fatalf("Read() error = %v", err)
+			}
+			if !equalRows(got, tt.want) {
+				t.Errorf("Read() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestReadXLSX(t *testing.T) {
+	header := `<row r="1"><c r="A1" t="s"><v>0</v></c><c r="B1" t="inlineStr"><is><t>Full_Name</t></is></c><c r="C1"><v>hire_date</v></c></row>`
+	tests := []struct {
+		name string
+		rows string
+		want []Row
+	}{
+		{
+			"shared, inline and rich strings",
+			header + `<row r="2"><c r="A2" t="s"><v>1</v></c>` +
+				`<c r="B2" t="inlineStr"><is><r><t>Budi </t></r><r><t>Santoso</t></r><rPh><t>x</t></rPh></is></c>` +
+				`<c r="C2"><v>45474</v></c></row>`,
+			[]Row{{Line: 2, Fields: map[string]string{"code": "E1", "full_name": "Budi Santoso", "hire_date": "45474"}}},
+		},
+		{
+			"line is the row number",
+			header + `<row r="4"><c r="A4" t="s"><v>1</v></c></row><row r="9"><c r="C9"><v>45474</v></c></row>`,
+			[]Row{
+				{Line: 4, Fields: map[string]string{"code": "E1"}},
+				{Line: 9, Fields: map[string]string{"code": "", "full_name": "", "hire_date": "45474"}},
+			},
+		},
+		{
+			"rows and cells without references follow the previous ones",
+			header + `<row r="5"><c r="B5" t="inlineStr"><is><t>Siti</t></is></c><c><v>1</v></c></row><row><c t="s"><v>1</v></c></row>`,
+			[]Row{
+				{Line: 5, Fields: map[string]string{"code": "", "full_name": "Siti", "hire_date": "1"}},
+				{Line: 6, Fields: map[string]string{"code": "E1"}},
+			},
+		},
+		{
+			"cells past the header ignored",
+			header + `<row r="2"><c r="A2" t="s"><v>1</v></c><c r="XFD2"><v>1</v></c></row><row r="3"><c r="D3"><v>1</v></c></row>`,
+			[]Row{{Line: 2, Fields: map[string]string{"code": "E1"}}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Read(FormatXLSX, workbook(t, tt.rows, "Code", "E1"), 0)
+			if err != nil {
+				t.Fatalf("Read() error = %v", err)
+			}
+			if !equalRows(got, tt.want) {
+				t.Errorf("Read() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestReadErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		format string
+		data   []byte
+		want   error
+	}{
+		{"empty csv", FormatCSV, nil, ErrNoHeader},
+		{"empty sheet", FormatXLSX, workbook(t, ""), ErrNoHeader},
+		{"bad csv quoting", FormatCSV, []byte("code\n\"E1\n"), nil},
+		{"not a zip", FormatXLSX, []byte("code\nE1\n"), nil},
+		{"bad shared string", FormatXLSX, workbook(t, `<row><c t="s"><v>3</v></c></row>`, "Code"), nil},
+		{"column past XFD", FormatXLSX, workbook(t, `<row><c r="XFE1"><v>code</v></c></row>`), nil},
+		{"bad row number", FormatXLSX, workbook(t, `<row r="x"><c><v>code</v></c></row>`), nil},
+		{"unknown format", "ods", nil, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Read(tt.format, tt.data, 0)
+			if err == nil {
+				t.Fatal("Read() error = nil, want an error")
+			}
+			if tt.want != nil && !errors.Is(err, tt.want) {
+				t.Errorf("Read() error = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestReadMaxRows(t *testing.T) {
+	csvRows := func(n int) []byte {
+		var b strings.Builder
+		b.WriteString("code\n")
+		for i := range n {
+			// Blank rows are not counted.
+			fmt.Fprintf(&b, "E%d\n,\n", i)
+		}
+		return []byte(b.String())
+	}
+	xlsxRows := func(n int) []byte {
+		var b strings.Builder
+		b.WriteString(`<row r="1"><c r="A1"><v>code</v></c></row>`)
+		for i := range n {
+			fmt.Fprintf(&b, `<row r="%d"><c r="A%d"><v>%d</v></c></row><row r="%d"/>`, 2*i+2, 2*i+2, i, 2*i+3)
+		}
+		return workbook(t, b.String())
+	}
+	tests := []struct {
+		name    string
+		format  string
+		data    []byte
+		wantErr bool
+	}{
+		{"csv at the limit", FormatCSV, csvRows(3), false},
+		{"csv over the limit", FormatCSV, csvRows(4), true},
+		{"xlsx at the limit", FormatXLSX, xlsxRows(3), false},
+		{"xlsx over the limit", FormatXLSX, xlsxRows(4), true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rows, err := Read(tt.format, tt.data, 3)
+			if tt.wantErr {
+				if !errors.Is(err, ErrTooManyRows) {
+					t.Errorf("Read() error = %v, want ErrTooManyRows", err)
+				}
+				return
+			}
+			if err != nil || len(rows) != 3 {
+				t.Errorf("Read() = %d rows, %v, want 3 rows", len(rows), err)
+			}
+		})
+	}
+}
+
+func TestColumnIndex(t *testing.T) {
+	tests := []struct {
+		ref     string
+		want    int
+		wantErr bool
+	}{
+		{"A1", 0, false},
+		{"Z9", 25, false},
+		{"AA10", 26, false},
+		{"XFD1048576", 16383, false},
+		{"XFE1", 0, true},
+		{"AAAAAAAAAAAAAAAAAAAAAA1", 0, true},
+		{"1", 0, true},
+		{"A", 0, true},
+		{"a1", 0, true},
+		{"A1:B2", 0, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.ref, func(t *testing.T) {
+			got, err := columnIndex(tt.ref)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("columnIndex(%q) error = %v, want error %v", tt.ref, err, tt.wantErr)
+			}
+			if !tt.wantErr && got != tt.want {
+				t.Errorf("columnIndex(%q) = %d, want %d", tt.ref, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFormatOf(t *testing.T) {
+	tests := map[string]string{
+		"employees.csv":  FormatCSV,
+		"Employees.XLSX": FormatXLSX,
+		"employees.xls":  "",
+		"employees":      "",
+	}
+	for name, want := range tests {
+		if got := FormatOf(name); got != want {
+			t.Errorf("FormatOf(%q) = %q, want %q", name, got, want)
+		}
+	}
+}
+
+func equalRows(a, b []Row) bool {
+	return slices.EqualFunc(a, b, func(x, y Row) bool {
+		return x.Line == y.Line && maps.Equal(x.Fields, y.Fields)
+	})
+}
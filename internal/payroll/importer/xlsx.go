@@ -0,0 +1,260 @@
+package importer
+
+import (
+	"archive/zip"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"strconv"
+	"strings"
+)
+
+// maxColumns is the number of columns a worksheet can have, XFD being the
+// last.
+const maxColumns = 16384
+
+// xlsxRow is a worksheet's <row>, R being its 1-based row number.
+type xlsxRow struct {
+	R     string `xml:"r,attr"`
+	Cells []struct {
+		Ref    string `xml:"r,attr"`
+		Type   string `xml:"t,attr"`
+		Value  string `xml:"v"`
+		Inline struct {
+			Text string `xml:",innerxml"`
+		} `xml:"is"`
+	} `xml:"c"`
+}
+
+// readXLSX reads the cell values of the first worksheet of an Office Open
+// XML workbook, a row at a time. Cells are read as Excel stores them: shared
+// and inline strings as text, everything else as its raw value, so dates
+// arrive as serial numbers.
+func readXLSX(r io.ReaderAt, size int64, c *collector) error {
+	zr, err := zip.NewReader(r, size)
+	if err != nil {
+		return fmt.Errorf("not an xlsx file: %w", err)
+	}
+	files := map[string]*zip.File{}
+	for _, f := range zr.File {
+		files[f.Name] = f
+	}
+
+	sheet, err := firstSheetPath(files)
+	if err != nil {
+		return err
+	}
+	shared, err := sharedStrings(files)
+	if err != nil {
+		return err
+	}
+
+	p, err := openPart(files, sheet)
+	if err != nil {
+		return err
+	}
+	defer p.Close()
+	d := xml.NewDecoder(p)
+	line := 0
+	for {
+		tok, err := d.Token()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return p.wrap(err)
+		}
+		start, ok := tok.(xml.StartElement)
+		if !ok || start.Name.Local != "row" {
+			continue
+		}
+		var row xlsxRow
+		if err := d.DecodeElement(&row, &start); err != nil {
+			return p.wrap(err)
+		}
+
+		// Rows and cells without a reference follow the one before them;
+		// empty ones are left out of the file.
+		line++
+		if row.R != "" {
+			if line, err = strconv.Atoi(row.R); err != nil || line < 1 {
+				return fmt.Errorf("bad row number %q", row.R)
+			}
+		}
+		var rec []string
+		col := -1
+		for _, cell := range row.Cells {
+			col++
+			if cell.Ref != "" {
+				if col, err = columnIndex(cell.Ref); err != nil {
+					return err
+				}
+			}
+			if col >= c.width() {
+				continue
+			}
+			for len(rec) <= col {
+				rec = append(rec, "")
+			}
+			switch cell.Type {
+			case "s":
+				n, err := strconv.Atoi(cell.Value)
+				if err != nil || n < 0 || n >= len(shared) {
+					return fmt.Errorf("cell %s: bad shared string index %q", cell.Ref, cell.Value)
+				}
+				rec[col] = shared[n]
+			case "inlineStr":
+				rec[col] = richText(cell.Inline.Text)
+			default:
+				rec[col] = cell.Value
+			}
+		}
+		if err := c.add(line, rec); err != nil {
+			return err
+		}
+	}
+}
+
+// firstSheetPath finds the part holding the workbook's first sheet.
+func firstSheetPath(files map[string]*zip.File) (string, error) {
+	var wb struct {
+		Sheets []struct {
+			RID string `xml:"http://schemas.openxmlformats.org/officeDocument/2006/relationships id,attr"`
+		} `xml:"sheets>sheet"`
+	}
+	if err := decodeXML(files, "xl/workbook.xml", &wb); err != nil {
+		return "", err
+	}
+	if len(wb.Sheets) == 0 {
+		return "", errors.New("workbook has no sheets")
+	}
+
+	var rels struct {
+		Rels []struct {
+			ID     string `xml:"Id,attr"`
+			Target string `xml:"Target,attr"`
+		} `xml:"Relationship"`
+	}
+	if err := decodeXML(files, "xl/_rels/workbook.xml.rels", &rels); err != nil {
+		return "", err
+	}
+	for _, rel := range rels.Rels {
+		if rel.ID != wb.Sheets[0].RID {
+			continue
+		}
+		if strings.HasPrefix(rel.Target, "/") {
+			return strings.TrimPrefix(rel.Target, "/"), nil
+		}
+		return path.Join("xl", rel.Target), nil
+	}
+	return "", errors.New("workbook's first sheet has no part")
+}
+
+func sharedStrings(files map[string]*zip.File) ([]string, error) {
+	if _, ok := files["xl/sharedStrings.xml"]; !ok {
+		return nil, nil
+	}
+	var sst struct {
+		Items []struct {
+			Text string `xml:",innerxml"`
+		} `xml:"si"`
+	}
+	if err := decodeXML(files, "xl/sharedStrings.xml", &sst); err != nil {
+		return nil, err
+	}
+	out := make([]string, len(sst.Items))
+	for i, si := range sst.Items {
+		out[i] = richText(si.Text)
+	}
+	return out, nil
+}
+
+// richText joins the <t> runs of a string item, plain or rich, leaving out
+// phonetic runs.
+func richText(inner string) string {
+	var item struct {
+		T    string `xml:"t"`
+		Runs []struct {
+			T string `xml:"t"`
+		} `xml:"r"`
+	}
+	if err := xml.Unmarshal([]byte("<si>"+inner+"</si>"), &item); err != nil {
+		return ""
+	}
+	var b strings.Builder
+	b.WriteString(item.T)
+	for _, r := range item.Runs {
+		b.WriteString(r.T)
+	}
+	return b.String()
+}
+
+// maxPartSize caps what a workbook part may inflate to, so a small upload
+// cannot decompress into gigabytes of XML.
+const maxPartSize = 64 << 20
+
+// part is an open workbook part. Reads are capped at maxPartSize, as the
+// size the zip entry declares may lie.
+type part struct {
+	name string
+	rc   io.ReadCloser
+	lr   *io.LimitedReader
+}
+
+func openPart(files map[string]*zip.File, name string) (*part, error) {
+	f, ok := files[name]
+	if !ok {
+		return nil, fmt.Errorf("xlsx file has no %s", name)
+	}
+	if f.UncompressedSize64 > maxPartSize {
+		return nil, fmt.Errorf("%s is larger than %d MB", name, maxPartSize>>20)
+	}
+	rc, err := f.Open()
+	if err != nil {
+		return nil, err
+	}
+	return &part{name: name, rc: rc, lr: &io.LimitedReader{R: rc, N: maxPartSize}}, nil
+}
+
+func (p *part) Read(b []byte) (int, error) { return p.lr.Read(b) }
+
+func (p *part) Close() error { return p.rc.Close() }
+
+// wrap names the part in a decoding error, which is the cap when the part
+// was cut off at it.
+func (p *part) wrap(err error) error {
+	if p.lr.N == 0 {
+		return fmt.Errorf("%s is larger than %d MB", p.name, maxPartSize>>20)
+	}
+	return fmt.Errorf("%s: %w", p.name, err)
+}
+
+func decodeXML(files map[string]*zip.File, name string, v any) error {
+	p, err := openPart(files, name)
+	if err != nil {
+		return err
+	}
+	defer p.Close()
+	if err := xml.NewDecoder(p).Decode(v); err != nil {
+		return p.wrap(err)
+	}
+	return nil
+}
+
+// columnIndex is the 0-based column of a cell reference such as "AB12".
+func columnIndex(ref string) (int, error) {
+	col := 0
+	for i, r := range ref {
+		if r >= 'A' && r <= 'Z' && col < maxColumns {
+			col = col*26 + int(r-'A'+1)
+			continue
+		}
+		if i == 0 || col > maxColumns || r < '0' || r > '9' {
+			break
+		}
+		return col - 1, nil
+	}
+	return 0, fmt.Errorf("bad cell reference %q", ref)
+}
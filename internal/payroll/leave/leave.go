@@ -0,0 +1,86 @@
+// Package leave works out leave days and balances. Balances are not stored:
+// they follow from the leave type's policy, the employee's hire date and
+// their requests, so changing a policy or a request never leaves one stale.
+package leave
+
+import (
+	"go-payroll-service/internal/payroll/model/domain"
+	"time"
+)
+
+// Days counts the working days from start to end inclusive, in a week of
+// workWeek working days starting on Monday.
+func Days(workWeek int, start, end time.Time) int {
+	n := 0
+	for d := start; !d.After(end); d = d.AddDate(0, 0, 1) {
+		// Monday is 1 and Sunday 0, so Sunday is never a working day.
+		if wd := int(d.Weekday()); wd != 0 && wd <= workWeek {
+			n++
+		}
+	}
+	return n
+}
+
+// Entitlement is the days of t earned for year by an employee hired on
+// hireDate, as of asOf. Nothing is earned before the employee is eligible;
+// in the year they become eligible they earn for the months left in it,
+// counting the month they become eligible in.
+func Entitlement(t domain.LeaveType, hireDate time.Time, year int, asOf time.Time) int {
+	eligible := hireDate.AddDate(0, t.EligibleAfterMonths, 0)
+	switch {
+	case eligible.Year() > year || asOf.Before(eligible):
+		return 0
+	case eligible.Year() < year:
+		return t.AnnualDays
+	default:
+		return t.AnnualDays * (13 - int(eligible.Month())) / 12
+	}
+}
+
+// Balance is the employee's balance of t for year as of asOf, from requests,
+// their requests of t of every year. Days left at the end of a year carry
+// over up to the type's limit; carried days are used first and lapse if
+// still unused when they expire.
+func Balance(t domain.LeaveType, hireDate time.Time, requests []domain.LeaveRequest, year int, asOf time.Time) domain.LeaveBalance {
+	carried := 0
+	if t.Tracked() {
+		for y := hireDate.AddDate(0, t.EligibleAfterMonths, 0).Year(); y < year; y++ {
+			b := yearBalance(t, hireDate, requests, y, time.Date(y, time.December, 31, 0, 0, 0, 0, time.UTC), carried)
+			carried = min(max(b.Available()+b.Pending, 0), t.CarryOverDays)
+		}
+	}
+	return yearBalance(t, hireDate, requests, year, asOf, carried)
+}
+
+func yearBalance(t domain.LeaveType, hireDate time.Time, requests []domain.LeaveRequest, year int, asOf time.Time, carried int) domain.LeaveBalance {
+	b := domain.LeaveBalance{LeaveType: t, Year: year, CarriedOver: carried}
+	var expiry time.Time
+	if t.CarryOverExpiryMonths > 0 {
+		expiry = time.Date(year, time.January, 1, 0, 0, 0, 0, time.UTC).AddDate(0, t.CarryOverExpiryMonths, 0)
+	}
+
+	usedBeforeExpiry := 0
+	for _, r := range requests {
+		if r.StartDate.Year() != year {
+			continue
+		}
+		switch r.Status {
+		case domain.LeaveStatusApproved:
+			b.Used += r.Days
+			if r.StartDate.Before(expiry) {
+				usedBeforeExpiry += r.Days
+			}
+		case domain.LeaveStatusPending:
+			b.Pending += r.Days
+		}
+	}
+	if !t.Tracked() {
+		return b
+	}
+
+	b.Entitlement = Entitlement(t, hireDate, year, asOf)
+	if !expiry.IsZero() && !asOf.Before(expiry) {
+		b.Expired = max(carried-usedBeforeExpiry, 0)
+	}
+	return b
+}
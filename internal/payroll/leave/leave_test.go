@@ -0,0 +1,137 @@
+package leave
+
+import (
+	"go-payroll-service/internal/payroll/model/domain"
+	"testing"
+	"time"
+)
+
+func date(s string) time.Time {
+	d, err := time.Parse("2006-01-02", s)
+	if err != nil {
+		panic(err)
+	}
+	return d
+}
+
+// annual is 12 days a year after 12 months of service, carrying at most 6
+// days over that lapse at the end of March.
+var annual = domain.LeaveType{
+	Code:                  "ANNUAL",
+	Paid:                  true,
+	AnnualDays:            12,
+	EligibleAfterMonths:   12,
+	CarryOverDays:         6,
+	CarryOverExpiryMonths: 3,
+}
+
+func TestDays(t *testing.T) {
+	tests := []struct {
+		name       string
+		workWeek   int
+		start, end string
+		want       int
+	}{
+		{"Monday to Sunday, 5-day week", 5, "2024-06-03", "2024-06-09", 5},
+		{"Monday to Sunday, 6-day week", 6, "2024-06-03", "2024-06-09", 6},
+		{"Saturday, 5-day week", 5, "2024-06-08", "2024-06-08", 0},
+		{"Saturday, 6-day week", 6, "2024-06-08", "2024-06-08", 1},
+		{"Sunday", 6, "2024-06-09", "2024-06-09", 0},
+		{"over a month end", 5, "2024-05-30", "2024-06-04", 4},
+		{"end before start", 5, "2024-06-04", "2024-06-03", 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Days(tt.workWeek, date(tt.start), date(tt.end)); got != tt.want {
+				t.Errorf("Days() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEntitlement(t *testing.T) {
+	hired := date("2023-03-15")
+	tests := []struct {
+		name string
+		year int
+		asOf string
+		want int
+	}{
+		{"year hired", 2023, "2023-12-31", 0},
+		{"before 12 months of service", 2024, "2024-03-14", 0},
+		{"months left in the year eligible", 2024, "2024-03-15", 10},
+		{"full year after", 2025, "2025-01-01", 12},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Entitlement(annual, hired, tt.year, date(tt.asOf)); got != tt.want {
+				t.Errorf("Entitlement() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBalance(t *testing.T) {
+	hired := date("2020-01-01")
+	request := func(start string, days int, status string) domain.LeaveRequest {
+		return domain.LeaveRequest{StartDate: date(start), EndDate: date(start), Days: days, Status: status}
+	}
+	requests := []domain.LeaveRequest{
+		// 2021 leaves 12 days, 6 carried into 2022 and used with all of
+		// 2022's, so nothing is carried into 2023.
+		request("2022-02-01", 18, domain.LeaveStatusApproved),
+		// 2023 leaves 8 days, 6 carried into 2024.
+		request("2023-05-06", 4, domain.LeaveStatusApproved),
+		request("2023-07-01", 3, domain.LeaveStatusRejected),
+		request("2024-01-15", 2, domain.LeaveStatusApproved),
+		request("2024-08-01", 3, domain.LeaveStatusPending),
+	}
+	tests := []struct {
+		name string
+		year int
+		asOf string
+		want domain.LeaveBalance
+	}{
+		{
+			"first year eligible",
+			2021, "2021-12-31",
+			domain.LeaveBalance{Year: 2021, Entitlement: 12},
+		},
+		{
+			"carried over used before it lapses",
+			2022, "2022-12-31",
+			domain.LeaveBalance{Year: 2022, Entitlement: 12, CarriedOver: 6, Used: 18},
+		},
+		{
+			"carried over still valid",
+			2024, "2024-03-31",
+			domain.LeaveBalance{Year: 2024, Entitlement: 12, CarriedOver: 6, Used: 2, Pending: 3},
+		},
+		{
+			"carried over lapsed",
+			2024, "2024-04-01",
+			domain.LeaveBalance{Year: 2024, Entitlement: 12, CarriedOver: 6, Expired: 4, Used: 2, Pending: 3},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.want.LeaveType = annual
+			if got := Balance(annual, hired, requests, tt.year, date(tt.asOf)); got != tt.want {
+				t.Errorf("Balance() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBalanceUntracked(t *testing.T) {
+	unpaid := domain.LeaveType{Code: "UNPAID"}
+	requests := []domain.LeaveRequest{
+		{StartDate: date("2024-02-05"), Days: 3, Status: domain.LeaveStatusApproved},
+		{StartDate: date("2024-03-05"), Days: 2, Status: domain.LeaveStatusPending},
+	}
+	got := Balance(unpaid, date("2020-01-01"), requests, 2024, date("2024-12-31"))
+	want := domain.LeaveBalance{LeaveType: unpaid, Year: 2024, Used: 3, Pending: 2}
+	if got != want {
+		t.Errorf("Balance() = %+v, want %+v", got, want)
+	}
+}
@@ -0,0 +1,150 @@
+package ledger
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"go-payroll-service/internal/payroll/model/domain"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var ErrUnbalanced = errors.New("journal does not balance")
+
+// UnmappedError lists the line codes that have no account for the side they
+// post to.
+type UnmappedError struct {
+	Codes []string
+}
+
+func (e *UnmappedError) Error() string {
+	return "no GL account mapped for " + strings.Join(e.Codes, ", ")
+}
+
+// Entry is one journal line; exactly one of Debit and Credit is non-zero.
+type Entry struct {
+	Account     string
+	CostCenter  string
+	Description string
+	Debit       int64
+	Credit      int64
+}
+
+type Journal struct {
+	PeriodCode string
+	Date       time.Time
+	Entries    []Entry
+}
+
+// Reference identifies the journal in the accounting system.
+func (j Journal) Reference() string {
+	return "PAYROLL-" + j.PeriodCode
+}
+
+func (j Journal) Totals() (debit, credit int64) {
+	for _, e := range j.Entries {
+		debit += e.Debit
+		credit += e.Credit
+	}
+	return debit, credit
+}
+
+// Build posts every line of the payslips and their net salaries, summed per
+// account, cost center and description. It fails with an *UnmappedError if
+// a line has no account, and with ErrUnbalanced if debits and credits
+// differ.
+func Build(periodCode string, date time.Time, mappings map[string]domain.GLMapping, payslips []domain.Payslip) (Journal, error) {
+	type key struct {
+		account, costCenter, description string
+		debit                            bool
+	}
+	sums := map[key]int64{}
+	var order []key
+	unmapped := map[string]bool{}
+
+	post := func(code, account string, debit bool, amount int64) {
+		if amount == 0 {
+			return
+		}
+		if account == "" {
+			unmapped[code] = true
+			return
+		}
+		m := mappings[code]
+		k := key{account, m.CostCenter, m.Description, debit}
+		if _, ok := sums[k]; !ok {
+			order = append(order, k)
+		}
+		sums[k] += amount
+	}
+
+	for _, p := range payslips {
+		for _, l := range p.Lines {
+			m := mappings[l.Code]
+			switch l.Type {
+			case domain.LineTypeEarning:
+				post(l.Code, m.ExpenseAccount, true, l.Amount)
+			case domain.LineTypeDeduction:
+				post(l.Code, m.PayableAccount, false, l.Amount)
+			case domain.LineTypeEmployerContribution:
+				post(l.Code, m.ExpenseAccount, true, l.Amount)
+				post(l.Code, m.PayableAccount, false, l.Amount)
+			}
+		}
+		post(domain.GLMappingNetPay, mappings[domain.GLMappingNetPay].PayableAccount, false, p.NetSalary())
+	}
+
+	if len(unmapped) > 0 {
+		codes := make([]string, 0, len(unmapped))
+		for c := range unmapped {
+			codes = append(codes, c)
+		}
+		slices.Sort(codes)
+		return Journal{}, &UnmappedError{Codes: codes}
+	}
+
+	j := Journal{PeriodCode: periodCode, Date: date}
+	for _, k := range order {
+		e := Entry{Account: k.account, CostCenter: k.costCenter, Description: k.description}
+		if k.debit {
+			e.Debit = sums[k]
+		} else {
+			e.Credit = sums[k]
+		}
+		j.Entries = append(j.Entries, e)
+	}
+	// Debits first, then by account, as accounting systems list them.
+	slices.SortStableFunc(j.Entries, func(a, b Entry) int {
+		if (a.Debit > 0) != (b.Debit > 0) {
+			if a.Debit > 0 {
+				return -1
+			}
+			return 1
+		}
+		return strings.Compare(a.Account, b.Account)
+	})
+
+	if debit, credit := j.Totals(); debit != credit {
+		return Journal{}, fmt.Errorf("%w: debit %d, credit %d", ErrUnbalanced, debit, credit)
+	}
+	return j, nil
+}
+
+// WriteCSV writes the journal with one row per entry.
+func WriteCSV(w io.Writer, j Journal) error {
+	cw := csv.NewWriter(w)
+	_ = cw.Write([]string{"date", "reference", "account", "cost_center", "description", "debit", "credit"})
+	date := j.Date.Format("2006-01-02")
+	ref := j.Reference()
+	for _, e := range j.Entries {
+		_ = cw.Write([]string{
+			date, ref, e.Account, e.CostCenter, e.Description,
+			strconv.FormatInt(e.Debit, 10), strconv.FormatInt(e.Credit, 10),
+		})
+	}
+	cw.Flush()
+	return cw.Error()
+}
@@ -0,0 +1,120 @@
+package ledger
+
+import (
+	"bytes"
+	"errors"
+	"go-payroll-service/internal/payroll/model/domain"
+	"slices"
+	"testing"
+	"time"
+)
+
+var mappings = map[string]domain.GLMapping{
+	"BASIC":                {Code: "BASIC", Description: "Salaries", ExpenseAccount: "6100", CostCenter: "HQ"},
+	"PPH21":                {Code: "PPH21", Description: "PPh 21 payable", PayableAccount: "2110"},
+	"JHT":                  {Code: "JHT", Description: "BPJS JHT", ExpenseAccount: "6200", PayableAccount: "2120"},
+	"KES":                  {Code: "KES", Description: "BPJS Kesehatan", ExpenseAccount: "6200", PayableAccount: "2120"},
+	domain.GLMappingNetPay: {Code: domain.GLMappingNetPay, Description: "Net pay", PayableAccount: "2300"},
+}
+
+func payslip(basic, tax int64) domain.Payslip {
+	return domain.Payslip{Lines: []domain.PayslipLine{
+		{Code: "BASIC", Type: domain.LineTypeEarning, Amount: basic},
+		{Code: "PPH21", Type: domain.LineTypeDeduction, Amount: tax},
+		{Code: "JHT", Type: domain.LineTypeDeduction, Amount: basic * 2 / 100},
+		{Code: "JHT", Type: domain.LineTypeEmployerContribution, Amount: basic * 37 / 1000},
+		{Code: "KES", Type: domain.LineTypeEmployerContribution, Amount: basic * 4 / 100},
+		{Code: "UNUSED", Type: domain.LineTypeEarning, Amount: 0},
+	}}
+}
+
+func TestBuild(t *testing.T) {
+	date := time.Date(2024, time.June, 25, 0, 0, 0, 0, time.UTC)
+	j, err := Build("2024-06", date, mappings, []domain.Payslip{payslip(10000000, 200000), payslip(5000000, 0)})
+	if err != nil {
+		t.Fatalf("Build() error = %v", err)
+	}
+	want := []Entry{
+		{Account: "6100", CostCenter: "HQ", Description: "Salaries", Debit: 15000000},
+		{Account: "6200", Description: "BPJS JHT", Debit: 555000},
+		{Account: "6200", Description: "BPJS Kesehatan", Debit: 600000},
+		{Account: "2110", Description: "PPh 21 payable", Credit: 200000},
+		{Account: "2120", Description: "BPJS JHT", Credit: 300000 + 555000},
+		{Account: "2120", Description: "BPJS Kesehatan", Credit: 600000},
+		{Account: "2300", Description: "Net pay", Credit: 9600000 + 4900000},
+	}
+	if !slices.Equal(j.Entries, want) {
+		t.Errorf("Build() entries =\n%v\nwant\n%v", j.Entries, want)
+	}
+	if debit, credit := j.Totals(); debit != 16155000 || credit != 16155000 {
+		t.Errorf("Totals() = %d, %d, want 16155000 both", debit, credit)
+	}
+	if got := j.Reference(); got != "PAYROLL-2024-06" {
+		t.Errorf("Reference() = %s, want PAYROLL-2024-06", got)
+	}
+}
+
+func TestBuildUnmapped(t *testing.T) {
+	tests := []struct {
+		name     string
+		mappings map[string]domain.GLMapping
+		lines    []domain.PayslipLine
+		want     []string
+	}{
+		{
+			"earning without expense account",
+			mappings,
+			[]domain.PayslipLine{{Code: "BONUS", Type: domain.LineTypeEarning, Amount: 1}},
+			[]string{"BONUS"},
+		},
+		{
+			"deduction without payable account",
+			mappings,
+			[]domain.PayslipLine{
+				{Code: "BASIC", Type: domain.LineTypeEarning, Amount: 10},
+				{Code: "BASIC", Type: domain.LineTypeDeduction, Amount: 1},
+				{Code: "LOAN", Type: domain.LineTypeDeduction, Amount: 1},
+			},
+			[]string{"BASIC", "LOAN"},
+		},
+		{
+			"no net pay account",
+			map[string]domain.GLMapping{"BASIC": mappings["BASIC"]},
+			[]domain.PayslipLine{{Code: "BASIC", Type: domain.LineTypeEarning, Amount: 1}},
+			[]string{domain.GLMappingNetPay},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Build("2024-06", time.Now(), tt.mappings, []domain.Payslip{{Lines: tt.lines}})
+			var uerr *UnmappedError
+			if !errors.As(err, &uerr) {
+				t.Fatalf("Build() error = %v, want an *UnmappedError", err)
+			}
+			if !slices.Equal(uerr.Codes, tt.want) {
+				t.Errorf("UnmappedError.Codes = %v, want %v", uerr.Codes, tt.want)
+			}
+		})
+	}
+}
+
+func TestWriteCSV(t *testing.T) {
+	j := Journal{
+		PeriodCode: "2024-06",
+		Date:       time.Date(2024, time.June, 25, 0, 0, 0, 0, time.UTC),
+		Entries: []Entry{
+			{Account: "6100", CostCenter: "HQ", Description: "Salaries", Debit: 100},
+			{Account: "2300", Description: "Net pay, June", Credit: 100},
+		},
+	}
+	var buf bytes.Buffer
+	if err := WriteCSV(&buf, j); err != nil {
+		t.Fatalf("WriteCSV() error = %v", err)
+	}
+	want := "date,reference,account,cost_center,description,debit,credit\n" +
+		"2024-06-25,PAYROLL-2024-06,6100,HQ,Salaries,100,0\n" +
+		"2024-06-25,PAYROLL-2024-06,2300,,\"Net pay, June\",0,100\n"
+	if got := buf.String(); got != want {
+		t.Errorf("WriteCSV() =\n%s\nwant\n%s", got, want)
+	}
+}
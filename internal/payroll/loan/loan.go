@@ -0,0 +1,34 @@
+// Package loan works out the repayment of employee loans and salary
+// advances (kasbon): flat interest on the principal over the term, repaid in
+// equal monthly installments deducted from salary.
+package loan
+
+// Total is what the employee repays: the principal plus flat interest at
+// rateBP, basis points a year, for the installments months of the term.
+func Total(principal, rateBP int64, installments int) int64 {
+	return principal + principal*rateBP*int64(installments)/(12*10000)
+}
+
+// Installment is the monthly deduction that repays total in installments
+// months, rounded up so the last one is the smallest.
+func Installment(total int64, installments int) int64 {
+	n := int64(max(installments, 1))
+	return (total + n - 1) / n
+}
+
+// Due is the installment to deduct after repaid has been repaid: the
+// regular installment, or the rest of the loan if that is less.
+func Due(total int64, installments int, repaid int64) int64 {
+	return max(min(Installment(total, installments), total-repaid), 0)
+}
+
+// Schedule lists the installments left after repaid, assuming none is
+// paused.
+func Schedule(total int64, installments int, repaid int64) []int64 {
+	var schedule []int64
+	for due := Due(total, installments, repaid); due > 0; due = Due(total, installments, repaid) {
+		schedule = append(schedule, due)
+		repaid += due
+	}
+	return schedule
+}
@@ -0,0 +1,90 @@
+package loan
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestTotal(t *testing.T) {
+	tests := []struct {
+		name         string
+		principal    int64
+		rateBP       int64
+		installments int
+		want         int64
+	}{
+		{"interest free kasbon", 3000000, 0, 3, 3000000},
+		{"12% a year over a year", 12000000, 1200, 12, 13440000},
+		{"12% a year over 6 months", 12000000, 1200, 6, 12720000},
+		{"rounded down", 1000000, 1000, 1, 1008333},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Total(tt.principal, tt.rateBP, tt.installments); got != tt.want {
+				t.Errorf("Total() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestInstallment(t *testing.T) {
+	tests := []struct {
+		total        int64
+		installments int
+		want         int64
+	}{
+		{13440000, 12, 1120000},
+		{1000000, 3, 333334},
+		{1000000, 1, 1000000},
+		{1000000, 0, 1000000},
+	}
+	for _, tt := range tests {
+		if got := Installment(tt.total, tt.installments); got != tt.want {
+			t.Errorf("Installment(%d, %d) = %d, want %d", tt.total, tt.installments, got, tt.want)
+		}
+	}
+}
+
+func TestDue(t *testing.T) {
+	tests := []struct {
+		name   string
+		repaid int64
+		want   int64
+	}{
+		{"first installment", 0, 333334},
+		{"last installment is the rest", 666668, 333332},
+		{"short of an installment", 900000, 100000},
+		{"repaid", 1000000, 0},
+		{"overpaid", 1200000, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Due(1000000, 3, tt.repaid); got != tt.want {
+				t.Errorf("Due() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSchedule(t *testing.T) {
+	tests := []struct {
+		name         string
+		total        int64
+		installments int
+		repaid       int64
+		want         []int64
+	}{
+		{"whole loan", 1000000, 3, 0, []int64{333334, 333334, 333332}},
+		{"after one installment", 1000000, 3, 333334, []int64{333334, 333332}},
+		{"after a partial payment", 1000000, 3, 100000, []int64{333334, 333334, 233332}},
+		{"flat interest", 13440000, 12, 11200000, []int64{1120000, 1120000}},
+		{"repaid", 1000000, 3, 1000000, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Schedule(tt.total, tt.installments, tt.repaid); !slices.Equal(got, tt.want) {
+				t.Errorf("Schedule() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
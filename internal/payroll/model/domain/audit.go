@@ -0,0 +1,62 @@
+package domain
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// AuditEntry records one change: who made it, in which request, and the
+// fields it changed. PrevHash and Hash chain it to the entry before it.
+type AuditEntry struct {
+	ID         int64           `db:"id"`
+	OccurredAt time.Time       `db:"occurred_at"`
+	Actor      string          `db:"actor"`
+	Action     string          `db:"action"`
+	Entity     string          `db:"entity"`
+	EntityID   string          `db:"entity_id"`
+	RequestID  string          `db:"request_id"`
+	Changes    json.RawMessage `db:"changes"`
+	PrevHash   string          `db:"prev_hash"`
+	Hash       string          `db:"hash"`
+}
+
+// AuditFilter selects audit entries; zero fields match everything. The
+// range is [From, To).
+type AuditFilter struct {
+	Entity   string
+	EntityID string
+	Actor    string
+	From     *time.Time
+	To       *time.Time
+	Limit    int
+}
+
+const (
+	AuditEntityEmployee          = "employee"
+	AuditEntitySalary            = "salary"
+	AuditEntityComponent         = "component"
+	AuditEntityEmployeeComponent = "employee_component"
+	AuditEntityPeriod            = "period"
+	AuditEntityPayroll           = "payroll"
+	AuditEntityAttendance        = "attendance"
+	AuditEntityBankAccount       = "bank_account"
+	AuditEntityGLMapping         = "gl_mapping"
+	AuditEntityOvertime          = "overtime"
+	AuditEntityLeaveType         = "leave_type"
+	AuditEntityLeaveRequest      = "leave_request"
+	AuditEntityLoan              = "loan"
+	AuditEntityPayrollRun        = "payroll_run"
+)
+
+const (
+	AuditActionCreate    = "create"
+	AuditActionUpdate    = "update"
+	AuditActionDelete    = "delete"
+	AuditActionGenerate  = "generate"
+	AuditActionTerminate = "terminate"
+	AuditActionApprove   = "approve"
+	AuditActionReject    = "reject"
+	AuditActionCancel    = "cancel"
+	AuditActionPayOff    = "pay_off"
+	AuditActionSubmit    = "submit"
+)
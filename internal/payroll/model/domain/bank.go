@@ -0,0 +1,11 @@
+package domain
+
+import "time"
+
+type BankAccount struct {
+	EmployeeID    int64     `db:"employee_id"`
+	BankCode      string    `db:"bank_code"`
+	AccountNumber string    `db:"account_number"`
+	AccountName   string    `db:"account_name"`
+	UpdatedAt     time.Time `db:"updated_at"`
+}
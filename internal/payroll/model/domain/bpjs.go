@@ -0,0 +1,16 @@
+package domain
+
+import "time"
+
+// BPJSProgram is the rate set for one BPJS program as of EffectiveFrom.
+// Rates are in basis points; a WageCap of zero means no cap.
+type BPJSProgram struct {
+	Code                  string    `db:"code"`
+	Label                 string    `db:"label"`
+	EffectiveFrom         time.Time `db:"effective_from"`
+	EmployeeRateBP        int64     `db:"employee_rate_bp"`
+	EmployerRateBP        int64     `db:"employer_rate_bp"`
+	WageCap               int64     `db:"wage_cap"`
+	EmployerTaxable       bool      `db:"employer_taxable"`
+	EmployeeTaxDeductible bool      `db:"employee_tax_deductible"`
+}
@@ -0,0 +1,51 @@
+package domain
+
+import (
+	"strconv"
+	"time"
+)
+
+type PayComponent struct {
+	ID        int64     `db:"id"`
+	Code      string    `db:"code"`
+	Name      string    `db:"name"`
+	Type      string    `db:"type"`
+	Taxable   bool      `db:"taxable"`
+	Formula   string    `db:"formula"`
+	IsActive  bool      `db:"is_active"`
+	CreatedAt time.Time `db:"created_at"`
+	UpdatedAt time.Time `db:"updated_at"`
+}
+
+// EmployeeComponent assigns a catalog component to an employee. A non-nil
+// AmountOverride pays that fixed amount; otherwise FormulaOverride, when
+// set, replaces the catalog formula.
+type EmployeeComponent struct {
+	ID              int64     `db:"id"`
+	EmployeeID      int64     `db:"employee_id"`
+	ComponentID     int64     `db:"component_id"`
+	AmountOverride  *int64    `db:"amount_override"`
+	FormulaOverride *string   `db:"formula_override"`
+	CreatedAt       time.Time `db:"created_at"`
+	UpdatedAt       time.Time `db:"updated_at"`
+	Component       PayComponent
+}
+
+// Formula is the expression payroll evaluates for this assignment.
+func (ec EmployeeComponent) Formula() string {
+	if ec.AmountOverride != nil {
+		return strconv.FormatInt(*ec.AmountOverride, 10)
+	}
+	if ec.FormulaOverride != nil {
+		return *ec.FormulaOverride
+	}
+	return ec.Component.Formula
+}
+
+type Attendance struct {
+	EmployeeID  int64     `db:"employee_id"`
+	PeriodCode  string    `db:"period_code"`
+	WorkingDays int       `db:"working_days"`
+	DaysPresent int       `db:"days_present"`
+	UpdatedAt   time.Time `db:"updated_at"`
+}
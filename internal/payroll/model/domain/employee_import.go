@@ -0,0 +1,44 @@
+package domain
+
+// EmployeeImport is the outcome of an employee import, row by row.
+type EmployeeImport struct {
+	Format string
+	DryRun bool
+	// Rejected is set when nothing was imported because the file had
+	// invalid rows and the whole file was to be imported or none of it.
+	Rejected bool
+	Rows     []EmployeeImportRow
+}
+
+// EmployeeImportRow is one row of the file: its problems, or the employee
+// it created.
+type EmployeeImportRow struct {
+	Line       int
+	Code       string
+	Errors     []string
+	EmployeeID int64
+}
+
+func (r EmployeeImportRow) Valid() bool {
+	return len(r.Errors) == 0
+}
+
+func (i EmployeeImport) Invalid() int {
+	n := 0
+	for _, r := range i.Rows {
+		if !r.Valid() {
+			n++
+		}
+	}
+	return n
+}
+
+func (i EmployeeImport) Imported() int {
+	n := 0
+	for _, r := range i.Rows {
+		if r.EmployeeID != 0 {
+			n++
+		}
+	}
+	return n
+}
@@ -0,0 +1,90 @@
+package domain
+
+import "time"
+
+// LeaveType is a kind of leave. A type with AnnualDays keeps a balance per
+// employee and calendar year; days of a type that is not Paid are deducted
+// from salary.
+type LeaveType struct {
+	ID                    int64     `db:"id"`
+	Code                  string    `db:"code"`
+	Name                  string    `db:"name"`
+	Paid                  bool      `db:"paid"`
+	AnnualDays            int       `db:"annual_days"`
+	EligibleAfterMonths   int       `db:"eligible_after_months"`
+	CarryOverDays         int       `db:"carry_over_days"`
+	CarryOverExpiryMonths int       `db:"carry_over_expiry_months"`
+	IsActive              bool      `db:"is_active"`
+	CreatedAt             time.Time `db:"created_at"`
+	UpdatedAt             time.Time `db:"updated_at"`
+}
+
+// Tracked reports whether the type has an entitlement that requests draw
+// from.
+func (t LeaveType) Tracked() bool {
+	return t.AnnualDays > 0
+}
+
+const (
+	LeaveStatusPending   = "pending"
+	LeaveStatusApproved  = "approved"
+	LeaveStatusRejected  = "rejected"
+	LeaveStatusCancelled = "cancelled"
+)
+
+// LeaveRequest asks for leave from StartDate to EndDate inclusive, Days
+// working days within one calendar year.
+type LeaveRequest struct {
+	ID           int64      `db:"id"`
+	EmployeeID   int64      `db:"employee_id"`
+	LeaveTypeID  int64      `db:"leave_type_id"`
+	StartDate    time.Time  `db:"start_date"`
+	EndDate      time.Time  `db:"end_date"`
+	Days         int        `db:"days"`
+	Reason       string     `db:"reason"`
+	Status       string     `db:"status"`
+	RequestedBy  string     `db:"requested_by"`
+	DecidedBy    string     `db:"decided_by"`
+	DecisionNote string     `db:"decision_note"`
+	DecidedAt    *time.Time `db:"decided_at"`
+	CreatedAt    time.Time  `db:"created_at"`
+	UpdatedAt    time.Time  `db:"updated_at"`
+	LeaveType    LeaveType
+}
+
+// Open reports whether the request holds its days: it is pending or
+// approved.
+func (r LeaveRequest) Open() bool {
+	return r.Status == LeaveStatusPending || r.Status == LeaveStatusApproved
+}
+
+// Overlaps reports whether the request covers any day from start to end.
+func (r LeaveRequest) Overlaps(start, end time.Time) bool {
+	return !r.StartDate.After(end) && !r.EndDate.Before(start)
+}
+
+// LeaveRequestFilter narrows a request listing; zero fields match all.
+// From and To select requests covering any day between them.
+type LeaveRequestFilter struct {
+	EmployeeID int64
+	Status     string
+	From       *time.Time
+	To         *time.Time
+}
+
+// LeaveBalance is an employee's standing in one leave type and year.
+// Expired counts carried over days that lapsed unused.
+type LeaveBalance struct {
+	LeaveType   LeaveType
+	Year        int
+	Entitlement int
+	CarriedOver int
+	Expired     int
+	Used        int
+	Pending     int
+}
+
+// Available is what is left to request.
+func (b LeaveBalance) Available() int {
+	return b.Entitlement + b.CarriedOver - b.Expired - b.Used - b.Pending
+}
@@ -0,0 +1,16 @@
+package domain
+
+import "time"
+
+// GLMappingNetPay is the mapping whose payable account receives net salaries.
+const GLMappingNetPay = "NET_PAY"
+
+// GLMapping posts the payslip lines with Code to the general ledger.
+type GLMapping struct {
+	Code           string    `db:"code"`
+	Description    string    `db:"description"`
+	ExpenseAccount string    `db:"expense_account"`
+	PayableAccount string    `db:"payable_account"`
+	CostCenter     string    `db:"cost_center"`
+	UpdatedAt      time.Time `db:"updated_at"`
+}
@@ -0,0 +1,55 @@
+package domain
+
+import "time"
+
+const (
+	LoanKindKasbon = "kasbon"
+	LoanKindLoan   = "loan"
+)
+
+const (
+	LoanStatusActive  = "active"
+	LoanStatusRepaid  = "repaid"
+	LoanStatusPaidOff = "paid_off"
+)
+
+// Loan is a company loan or salary advance repaid in Installments monthly
+// deductions from StartPeriodCode on. PayoffPeriodCode is set when the
+// rest was settled early, and no installment is deducted from that period.
+type Loan struct {
+	ID               int64     `db:"id"`
+	EmployeeID       int64     `db:"employee_id"`
+	Kind             string    `db:"kind"`
+	Principal        int64     `db:"principal"`
+	InterestRateBP   int64     `db:"interest_rate_bp"`
+	Installments     int       `db:"installments"`
+	StartPeriodCode  string    `db:"start_period_code"`
+	PayoffPeriodCode *string   `db:"payoff_period_code"`
+	Note             string    `db:"note"`
+	CreatedAt        time.Time `db:"created_at"`
+	UpdatedAt        time.Time `db:"updated_at"`
+	// Repaid is what payslips deducted for the loan, up to the period it is
+	// read for.
+	Repaid int64
+}
+
+// LoanRepayment is an installment a payslip deducted.
+type LoanRepayment struct {
+	PayslipID  int64
+	PeriodCode string
+	Status     string
+	Amount     int64
+}
+
+// LoanBalance is where the repayment of a loan stands. PaidOff is what the
+// early payoff settled; Schedule lists the installments still to deduct.
+type LoanBalance struct {
+	Loan
+	Total       int64
+	Installment int64
+	PaidOff     int64
+	Outstanding int64
+	Status      string
+	Schedule    []int64
+	Repayments  []LoanRepayment
+}
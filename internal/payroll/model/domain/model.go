@@ -1,42 +1,310 @@
 package domain
 
-import "time"
+import (
+	"encoding/base64"
+	"encoding/json"
+	"time"
+)
 
 type Employee struct {
-	ID         int64     `db:"id"`
-	Code       string    `db:"code"`
-	FullName   string    `db:"full_name"`
-	Email      string    `db:"email"`
-	BaseSalary int64     `db:"base_salary"`
-	Allowance  int64     `db:"allowance"`
-	IsActive   bool      `db:"is_active"`
-	HireDate   time.Time `db:"hire_date"`
-	CreatedAt  time.Time `db:"created_at"`
-	UpdatedAt  time.Time `db:"updated_at"`
+	ID         int64  `db:"id"`
+	Code       string `db:"code"`
+	FullName   string `db:"full_name"`
+	Email      string `db:"email"`
+	Department string `db:"department"`
+	// Religion decides which religious holiday the employee's THR is paid
+	// for; empty if not recorded.
+	Religion   string `db:"religion"`
+	BaseSalary int64  `db:"base_salary"`
+	Allowance  int64  `db:"allowance"`
+	PTKPStatus string `db:"ptkp_status"`
+	NPWP       string `db:"npwp"`
+	// IsActive is read as of today: it turns false once the employee is
+	// deleted or past their TerminationDate, their last working day.
+	IsActive        bool       `db:"is_active"`
+	HireDate        time.Time  `db:"hire_date"`
+	TerminationDate *time.Time `db:"termination_date"`
+	// TerminationReason and FinalPay are set when the employee is
+	// terminated. FinalPay is false when their last salary is settled
+	// outside the regular run of the period they leave.
+	TerminationReason string     `db:"termination_reason"`
+	FinalPay          bool       `db:"final_pay"`
+	DeletedAt         *time.Time `db:"deleted_at"`
+	CreatedAt         time.Time  `db:"created_at"`
+	UpdatedAt         time.Time  `db:"updated_at"`
+}
+
+// Deleted reports whether the employee was soft deleted. Deleted employees
+// are kept for the payslips and reports that reference them.
+func (e Employee) Deleted() bool {
+	return e.DeletedAt != nil
+}
+
+// EmployedDuring reports whether the employee worked any day between start
+// and end inclusive.
+func (e Employee) EmployedDuring(start, end time.Time) bool {
+	if e.HireDate.After(end) {
+		return false
+	}
+	return e.TerminationDate == nil || !e.TerminationDate.Before(start)
 }
 
 type PayrollPeriod struct {
 	ID        int64     `db:"id"`
 	Code      string    `db:"code"`
-	StartDate string    `db:"start_date"`
-	EndDate   string    `db:"end_date"`
-	Closed    bool      `db:"closed"`
+	StartDate time.Time `db:"start_date"`
+	EndDate   time.Time `db:"end_date"`
+	PayDate   time.Time `db:"pay_date"`
+	Status    string    `db:"status"`
 	CreatedAt time.Time `db:"created_at"`
 	UpdatedAt time.Time `db:"updated_at"`
+	Events    []PayrollPeriodEvent
+}
+
+const (
+	PeriodStatusDraft  = "draft"
+	PeriodStatusOpen   = "open"
+	PeriodStatusLocked = "locked"
+	PeriodStatusClosed = "closed"
+)
+
+type PayrollPeriodEvent struct {
+	ID              int64     `db:"id"`
+	PayrollPeriodID int64     `db:"payroll_period_id"`
+	Action          string    `db:"action"`
+	FromStatus      string    `db:"from_status"`
+	ToStatus        string    `db:"to_status"`
+	Reason          string    `db:"reason"`
+	CreatedAt       time.Time `db:"created_at"`
 }
 
 type Payslip struct {
-	ID              int64 `db:"id"`
-	EmployeeID      int64 `db:"employee_id"`
-	PayrollPeriodID int64 `db:"payroll_period_id"`
-	BaseSalary      int64 `db:"base_salary"`
-	Allowance       int64 `db:"allowance"`
-	Deduction       int64 `db:"deduction"`
-	NetSalary       int64 `db:"net_salary"`
+	ID              int64     `db:"id"`
+	EmployeeID      int64     `db:"employee_id"`
+	PayrollPeriodID int64     `db:"payroll_period_id"`
+	RunType         string    `db:"run_type"`
+	Status          string    `db:"status"`
+	TaxableIncome   int64     `db:"taxable_income"`
+	IncomeTax       int64     `db:"income_tax"`
+	TaxMethod       string    `db:"tax_method"`
+	ProrationMethod string    `db:"proration_method"`
+	ProratedDays    int       `db:"prorated_days"`
+	PeriodDays      int       `db:"period_days"`
+	CreatedAt       time.Time `db:"created_at"`
+	UpdatedAt       time.Time `db:"updated_at"`
+	Lines           []PayslipLine
+	TaxLines        []PayslipTaxLine
+}
+
+const PayslipStatusDraft = "draft"
+
+// Run types. A period has one payslip per employee and run type; the THR
+// run pays the religious holiday allowance besides the regular salary, and
+// the off-cycle runs pay chosen components to chosen employees.
+const (
+	RunTypeRegular    = "regular"
+	RunTypeTHR        = "thr"
+	RunTypeBonus      = "bonus"
+	RunTypeCorrection = "correction"
+	RunTypeFinalPay   = "final_pay"
+)
+
+// ProrationFactor is the share of the period the salary lines pay for.
+func (p Payslip) ProrationFactor() float64 {
+	if p.PeriodDays == 0 {
+		return 1
+	}
+	return float64(p.ProratedDays) / float64(p.PeriodDays)
+}
+
+// SameFigures reports whether two payslips carry the same amounts and lines,
+// ignoring ids and timestamps.
+func (p Payslip) SameFigures(o Payslip) bool {
+	if p.TaxableIncome != o.TaxableIncome || p.IncomeTax != o.IncomeTax || p.TaxMethod != o.TaxMethod ||
+		p.ProrationMethod != o.ProrationMethod || p.ProratedDays != o.ProratedDays || p.PeriodDays != o.PeriodDays ||
+		len(p.Lines) != len(o.Lines) || len(p.TaxLines) != len(o.TaxLines) {
+		return false
+	}
+	for i, l := range p.Lines {
+		m := o.Lines[i]
+		if l.Code != m.Code || l.Label != m.Label || l.Type != m.Type ||
+			l.Amount != m.Amount || l.Taxable != m.Taxable || l.Source != m.Source ||
+			(l.LoanID == nil) != (m.LoanID == nil) || l.LoanID != nil && *l.LoanID != *m.LoanID {
+			return false
+		}
+	}
+	for i, l := range p.TaxLines {
+		m := o.TaxLines[i]
+		if l.LineNo != m.LineNo || l.Code != m.Code || l.Label != m.Label || l.Amount != m.Amount {
+			return false
+		}
+	}
+	return true
+}
+
+// PayrollRunSummary counts what a payroll generation did to the period's
+// payslips.
+type PayrollRunSummary struct {
+	Created   int
+	Updated   int
+	Unchanged int
+	Removed   int
+}
+
+// Total is the number of payslips the period holds after the run.
+func (s PayrollRunSummary) Total() int {
+	return s.Created + s.Updated + s.Unchanged
+}
+
+// Total sums the payslip's lines of the given type.
+func (p Payslip) Total(lineType string) int64 {
+	var total int64
+	for _, l := range p.Lines {
+		if l.Type == lineType {
+			total += l.Amount
+		}
+	}
+	return total
+}
+
+// NetSalary is what the employee is paid: earnings less deductions.
+// Employer contributions are a cost to the company and do not affect it.
+func (p Payslip) NetSalary() int64 {
+	return p.Total(LineTypeEarning) - p.Total(LineTypeDeduction)
+}
+
+const (
+	LineTypeEarning              = "earning"
+	LineTypeDeduction            = "deduction"
+	LineTypeEmployerContribution = "employer_contribution"
+)
+
+const (
+	LineSourceSalary     = "salary"
+	LineSourceTax        = "tax"
+	LineSourceBPJS       = "bpjs"
+	LineSourceComponent  = "component"
+	LineSourceAdjustment = "adjustment"
+	LineSourceOvertime   = "overtime"
+	LineSourceLeave      = "leave"
+	LineSourceTHR        = "thr"
+	LineSourceLoan       = "loan"
+)
+
+// PayslipLine is one component of a payslip. Taxable marks lines that enter
+// the PPh 21 calculation: taxable earnings and employer-paid benefits add to
+// taxable income, and deductible employee contributions reduce it.
+type PayslipLine struct {
+	ID        int64  `db:"id"`
+	PayslipID int64  `db:"payslip_id"`
+	Code      string `db:"code"`
+	Label     string `db:"label"`
+	Type      string `db:"type"`
+	Amount    int64  `db:"amount"`
+	Taxable   bool   `db:"taxable"`
+	Source    string `db:"source"`
+	// LoanID is the loan a repayment line deducts an installment of.
+	LoanID *int64 `db:"loan_id"`
+}
+
+type PayslipTaxLine struct {
+	ID        int64  `db:"id"`
+	PayslipID int64  `db:"payslip_id"`
+	LineNo    int    `db:"line_no"`
+	Code      string `db:"code"`
+	Label     string `db:"label"`
+	Amount    int64  `db:"amount"`
 }
 
 type PayslipWithEmployee struct {
 	Payslip
 	EmployeeName string `db:"employee_name"`
 	PeriodCode   string `db:"period_code"`
+	// RunStatus is the approval status of the payslip's run.
+	RunStatus string `db:"run_status"`
+}
+
+// TaxYTD is what an employee has already earned and had withheld in the
+// current tax year, before the period being calculated.
+type TaxYTD struct {
+	Months              int
+	TaxableIncome       int64
+	IncomeTax           int64
+	PensionContribution int64
+}
+
+// PayslipYTD are an employee's payslip totals in the tax year up to and
+// including a period.
+type PayslipYTD struct {
+	Earnings   int64
+	Deductions int64
+	IncomeTax  int64
+}
+
+func (y PayslipYTD) NetSalary() int64 {
+	return y.Earnings - y.Deductions
+}
+
+// EmployeeQuery selects a page of employees for listing. Nil and zero
+// fields do not filter. Deleted employees are never listed.
+type EmployeeQuery struct {
+	// Active matches IsActive, so terminated employees count as active
+	// until their last working day.
+	Active     *bool
+	HiredFrom  *time.Time
+	HiredTo    *time.Time
+	MinSalary  *int64
+	MaxSalary  *int64
+	Search     string
+	Department string
+	// SortBy is one of the EmployeeSort fields; SortDesc reverses it.
+	SortBy   string
+	SortDesc bool
+	Limit    int
+	// After continues a listing after the last employee of a page.
+	After *EmployeeCursor
+}
+
+const (
+	EmployeeSortID         = "id"
+	EmployeeSortCode       = "code"
+	EmployeeSortFullName   = "full_name"
+	EmployeeSortHireDate   = "hire_date"
+	EmployeeSortBaseSalary = "base_salary"
+)
+
+// EmployeeCursor is the position of an employee in a sorted listing: its
+// sort key, as text, and its ID to break ties. Sort records the sort it
+// belongs to, so it is not used with another.
+type EmployeeCursor struct {
+	Sort  string `json:"s"`
+	Value string `json:"v"`
+	ID    int64  `json:"i"`
+}
+
+// Encode returns the cursor as an opaque URL-safe token.
+func (c EmployeeCursor) Encode() string {
+	raw, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(raw)
+}
+
+// ParseEmployeeCursor decodes a token made by Encode.
+func ParseEmployeeCursor(token string) (EmployeeCursor, error) {
+	var c EmployeeCursor
+	raw, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return EmployeeCursor{}, err
+	}
+	if err := json.Unmarshal(raw, &c); err != nil {
+		return EmployeeCursor{}, err
+	}
+	return c, nil
+}
+
+type EmployeePage struct {
+	Employees []Employee
+	// Total counts every employee matching the filters, on all pages.
+	Total int
+	// Next is where the next page starts; nil on the last page.
+	Next *EmployeeCursor
 }
@@ -0,0 +1,17 @@
+package domain
+
+import "time"
+
+// OvertimeEntry is the overtime an employee worked on WorkDate, paid in the
+// period PeriodCode. DayType is one of the overtime day types.
+type OvertimeEntry struct {
+	ID         int64     `db:"id"`
+	EmployeeID int64     `db:"employee_id"`
+	PeriodCode string    `db:"period_code"`
+	WorkDate   time.Time `db:"work_date"`
+	DayType    string    `db:"day_type"`
+	Minutes    int       `db:"minutes"`
+	Note       string    `db:"note"`
+	CreatedAt  time.Time `db:"created_at"`
+	UpdatedAt  time.Time `db:"updated_at"`
+}
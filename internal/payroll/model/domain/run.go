@@ -0,0 +1,66 @@
+package domain
+
+import "time"
+
+const (
+	RunStatusDraft     = "draft"
+	RunStatusSubmitted = "submitted"
+	RunStatusApproved  = "approved"
+	RunStatusRejected  = "rejected"
+)
+
+const (
+	RunActionGenerate = "generate"
+	RunActionSubmit   = "submit"
+	RunActionApprove  = "approve"
+	RunActionReject   = "reject"
+)
+
+// PayrollRun is the approval state of one run type of a period. Round counts
+// the submissions; RequiredLevels and TotalNet are fixed when the run is
+// submitted. GeneratedBy and SubmittedBy are the makers, who may not approve
+// the run themselves.
+type PayrollRun struct {
+	ID              int64     `db:"id"`
+	PayrollPeriodID int64     `db:"payroll_period_id"`
+	PeriodCode      string    `db:"period_code"`
+	RunType         string    `db:"run_type"`
+	Status          string    `db:"status"`
+	Round           int       `db:"round"`
+	TotalNet        int64     `db:"total_net"`
+	RequiredLevels  int       `db:"required_levels"`
+	GeneratedBy     string    `db:"generated_by"`
+	SubmittedBy     string    `db:"submitted_by"`
+	CreatedAt       time.Time `db:"created_at"`
+	UpdatedAt       time.Time `db:"updated_at"`
+	Events          []PayrollRunEvent
+}
+
+type PayrollRunEvent struct {
+	ID           int64     `db:"id"`
+	PayrollRunID int64     `db:"payroll_run_id"`
+	Round        int       `db:"round"`
+	Action       string    `db:"action"`
+	FromStatus   string    `db:"from_status"`
+	ToStatus     string    `db:"to_status"`
+	Actor        string    `db:"actor"`
+	Comment      string    `db:"comment"`
+	CreatedAt    time.Time `db:"created_at"`
+}
+
+// Approvers lists who approved the run in its current round, in order.
+func (r PayrollRun) Approvers() []string {
+	var approvers []string
+	for _, ev := range r.Events {
+		if ev.Round == r.Round && ev.Action == RunActionApprove {
+			approvers = append(approvers, ev.Actor)
+		}
+	}
+	return approvers
+}
+
+// Published reports whether employees may see the payslip: its run is
+// approved.
+func (p PayslipWithEmployee) Published() bool {
+	return p.RunStatus == RunStatusApproved
+}
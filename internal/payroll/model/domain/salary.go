@@ -0,0 +1,16 @@
+package domain
+
+import "time"
+
+// SalaryRecord is an employee's base salary and fixed allowance from
+// EffectiveFrom until the next record takes effect.
+type SalaryRecord struct {
+	ID            int64     `db:"id"`
+	EmployeeID    int64     `db:"employee_id"`
+	BaseSalary    int64     `db:"base_salary"`
+	Allowance     int64     `db:"allowance"`
+	EffectiveFrom time.Time `db:"effective_from"`
+	Reason        string    `db:"reason"`
+	ApprovedBy    string    `db:"approved_by"`
+	CreatedAt     time.Time `db:"created_at"`
+}
@@ -0,0 +1,28 @@
+package domain
+
+// TaxRates is the PPh 21 rate set in force for a tax year. Rates are in
+// basis points (1/100 of a percent) so 0.25% is stored as 25.
+type TaxRates struct {
+	EffectiveYear          int
+	PositionCostRateBP     int64
+	PositionCostMonthlyCap int64
+	PositionCostAnnualCap  int64
+	NonNPWPSurchargeBP     int64
+	PTKP                   map[string]PTKPRate
+	Brackets               []TaxBracket
+	TER                    map[string][]TaxBracket
+}
+
+type PTKPRate struct {
+	Status      string `db:"status"`
+	Amount      int64  `db:"amount"`
+	TERCategory string `db:"ter_category"`
+}
+
+// TaxBracket covers income above LowerBound up to and including UpperBound.
+// An UpperBound of zero means the bracket is open-ended.
+type TaxBracket struct {
+	LowerBound int64 `db:"lower_bound"`
+	UpperBound int64 `db:"upper_bound"`
+	RateBP     int64 `db:"rate_bp"`
+}
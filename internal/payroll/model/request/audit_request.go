@@ -0,0 +1,11 @@
+package request
+
+// ListAuditRequest filters the audit log; from and to are inclusive dates.
+type ListAuditRequest struct {
+	Entity   string `form:"entity"`
+	EntityID string `form:"entity_id"`
+	Actor    string `form:"actor"`
+	From     string `form:"from"`
+	To       string `form:"to"`
+	Limit    int    `form:"limit" binding:"omitempty,min=1"`
+}
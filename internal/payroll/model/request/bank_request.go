@@ -0,0 +1,7 @@
+package request
+
+type SaveBankAccountRequest struct {
+	BankCode      string `json:"bank_code" binding:"required,numeric,len=3"`
+	AccountNumber string `json:"account_number" binding:"required,numeric,min=5,max=20"`
+	AccountName   string `json:"account_name" binding:"required,max=255"`
+}
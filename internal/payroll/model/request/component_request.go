@@ -0,0 +1,25 @@
+package request
+
+type CreateComponentRequest struct {
+	Code    string `json:"code" binding:"required"`
+	Name    string `json:"name" binding:"required"`
+	Type    string `json:"type" binding:"required,oneof=earning deduction"`
+	Taxable *bool  `json:"taxable"`
+	Formula string `json:"formula" binding:"required"`
+}
+
+type UpdateComponentRequest struct {
+	Name     *string `json:"name"`
+	Type     *string `json:"type" binding:"omitempty,oneof=earning deduction"`
+	Taxable  *bool   `json:"taxable"`
+	Formula  *string `json:"formula"`
+	IsActive *bool   `json:"is_active"`
+}
+
+// AssignComponentRequest assigns a component to an employee. Amount pays a
+// fixed amount and Formula replaces the catalog formula; with neither set
+// the catalog formula is used.
+type AssignComponentRequest struct {
+	Amount  *int64  `json:"amount" binding:"omitempty,min=0"`
+	Formula *string `json:"formula"`
+}
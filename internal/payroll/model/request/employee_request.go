@@ -6,16 +6,63 @@ type CreateEmployeeRequest struct {
 	Code       string    `json:"code" binding:"required"`
 	FullName   string    `json:"full_name" binding:"required"`
 	Email      string    `json:"email" binding:"required,email"`
+	Department string    `json:"department"`
+	Religion   string    `json:"religion" binding:"omitempty,oneof=islam protestant catholic hindu buddhist confucian"`
 	BaseSalary int64     `json:"base_salary" binding:"required"`
 	Allowance  int64     `json:"allowance"`
+	PTKPStatus string    `json:"ptkp_status" binding:"omitempty,oneof=TK/0 TK/1 TK/2 TK/3 K/0 K/1 K/2 K/3"`
+	NPWP       string    `json:"npwp"`
 	HireDate   time.Time `json:"hire_date"`
 }
 
+// TerminateEmployeeRequest ends an employee's employment on TerminationDate,
+// their last working day. FinalPay defaults to true.
+type TerminateEmployeeRequest struct {
+	TerminationDate time.Time `json:"termination_date" binding:"required"`
+	Reason          string    `json:"reason" binding:"required"`
+	FinalPay        *bool     `json:"final_pay"`
+}
+
+// UpdateEmployeeRequest changes the employee's details. Employment ends
+// through TerminateEmployeeRequest only, so TerminationDate and IsActive
+// may only repeat the employee's current values.
 type UpdateEmployeeRequest struct {
-	FullName   *string    `json:"full_name"`
-	Email      *string    `json:"email"`
-	BaseSalary *int64     `json:"base_salary"`
-	Allowance  *int64     `json:"allowance"`
-	HireDate   *time.Time `json:"hire_date"`
-	IsActive   *bool      `json:"is_active"`
+	FullName        *string    `json:"full_name"`
+	Email           *string    `json:"email"`
+	Department      *string    `json:"department"`
+	Religion        *string    `json:"religion" binding:"omitempty,oneof=islam protestant catholic hindu buddhist confucian"`
+	BaseSalary      *int64     `json:"base_salary"`
+	Allowance       *int64     `json:"allowance"`
+	PTKPStatus      *string    `json:"ptkp_status" binding:"omitempty,oneof=TK/0 TK/1 TK/2 TK/3 K/0 K/1 K/2 K/3"`
+	NPWP            *string    `json:"npwp"`
+	HireDate        *time.Time `json:"hire_date"`
+	TerminationDate *time.Time `json:"termination_date"`
+	IsActive        *bool      `json:"is_active"`
+}
+
+// ListEmployeesRequest filters, sorts and pages the employee listing. Sort
+// is a field name, prefixed with - for descending order; Cursor is the
+// next_cursor of the previous page.
+type ListEmployeesRequest struct {
+	Active     *bool  `form:"active"`
+	HiredFrom  string `form:"hired_from"`
+	HiredTo    string `form:"hired_to"`
+	MinSalary  *int64 `form:"min_salary" binding:"omitempty,min=0"`
+	MaxSalary  *int64 `form:"max_salary" binding:"omitempty,min=0"`
+	Search     string `form:"q"`
+	Department string `form:"department"`
+	Sort       string `form:"sort"`
+	Limit      int    `form:"limit" binding:"omitempty,min=1"`
+	Cursor     string `form:"cursor"`
+}
+
+// ImportEmployeesRequest are the options of an employee import. Format is
+// csv or xlsx, taken from the file name when empty. DryRun only validates;
+// AllOrNothing imports nothing if any row is invalid, instead of importing
+// the valid rows.
+type ImportEmployeesRequest struct {
+	Format       string `form:"format"`
+	FileName     string `form:"-"`
+	DryRun       bool   `form:"dry_run"`
+	AllOrNothing bool   `form:"all_or_nothing"`
 }
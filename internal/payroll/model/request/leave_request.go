@@ -0,0 +1,43 @@
+package request
+
+type CreateLeaveTypeRequest struct {
+	Code                  string `json:"code" binding:"required,max=50"`
+	Name                  string `json:"name" binding:"required"`
+	Paid                  *bool  `json:"paid"`
+	AnnualDays            int    `json:"annual_days" binding:"min=0,max=366"`
+	EligibleAfterMonths   int    `json:"eligible_after_months" binding:"min=0"`
+	CarryOverDays         int    `json:"carry_over_days" binding:"min=0"`
+	CarryOverExpiryMonths int    `json:"carry_over_expiry_months" binding:"min=0,max=12"`
+}
+
+type UpdateLeaveTypeRequest struct {
+	Name                  *string `json:"name"`
+	Paid                  *bool   `json:"paid"`
+	AnnualDays            *int    `json:"annual_days" binding:"omitempty,min=0,max=366"`
+	EligibleAfterMonths   *int    `json:"eligible_after_months" binding:"omitempty,min=0"`
+	CarryOverDays         *int    `json:"carry_over_days" binding:"omitempty,min=0"`
+	CarryOverExpiryMonths *int    `json:"carry_over_expiry_months" binding:"omitempty,min=0,max=12"`
+	IsActive              *bool   `json:"is_active"`
+}
+
+// CreateLeaveRequest asks for leave_type leave from start_date to end_date
+// (YYYY-MM-DD), both inclusive and in the same year.
+type CreateLeaveRequest struct {
+	LeaveType string `json:"leave_type" binding:"required"`
+	StartDate string `json:"start_date" binding:"required,datetime=2006-01-02"`
+	EndDate   string `json:"end_date" binding:"required,datetime=2006-01-02"`
+	Reason    string `json:"reason" binding:"max=255"`
+}
+
+type DecideLeaveRequest struct {
+	Note string `json:"note" binding:"max=255"`
+}
+
+// ListLeaveRequestsRequest filters leave requests; from and to (YYYY-MM-DD)
+// select requests covering any day between them.
+type ListLeaveRequestsRequest struct {
+	EmployeeID int64  `form:"employee_id"`
+	Status     string `form:"status" binding:"omitempty,oneof=pending approved rejected cancelled"`
+	From       string `form:"from" binding:"omitempty,datetime=2006-01-02"`
+	To         string `form:"to" binding:"omitempty,datetime=2006-01-02"`
+}
@@ -0,0 +1,10 @@
+package request
+
+// SaveGLMappingRequest maps a payslip line code to GL accounts. Which
+// accounts are needed depends on the line type; see the gl_mappings table.
+type SaveGLMappingRequest struct {
+	Description    string `json:"description" binding:"required"`
+	ExpenseAccount string `json:"expense_account" binding:"max=50"`
+	PayableAccount string `json:"payable_account" binding:"max=50"`
+	CostCenter     string `json:"cost_center" binding:"max=50"`
+}
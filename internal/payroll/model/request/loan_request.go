@@ -0,0 +1,19 @@
+package request
+
+// CreateLoanRequest lends principal to be repaid in installments monthly
+// deductions from start_period_code on. interest_rate_bp is a flat yearly
+// rate in basis points on the principal.
+type CreateLoanRequest struct {
+	Kind            string `json:"kind" binding:"required,oneof=kasbon loan"`
+	Principal       int64  `json:"principal" binding:"required,min=1"`
+	InterestRateBP  int64  `json:"interest_rate_bp" binding:"min=0,max=10000"`
+	Installments    int    `json:"installments" binding:"required,min=1,max=120"`
+	StartPeriodCode string `json:"start_period_code" binding:"required"`
+	Note            string `json:"note" binding:"max=255"`
+}
+
+// PayOffLoanRequest settles what is left of a loan outside payroll; no
+// installment is deducted from period_code on.
+type PayOffLoanRequest struct {
+	PeriodCode string `json:"period_code" binding:"required"`
+}
@@ -0,0 +1,11 @@
+package request
+
+// RecordOvertimeRequest sets the overtime worked on work_date (YYYY-MM-DD),
+// replacing what was recorded for that day.
+type RecordOvertimeRequest struct {
+	PeriodCode string `json:"period_code" binding:"required"`
+	WorkDate   string `json:"work_date" binding:"required,datetime=2006-01-02"`
+	DayType    string `json:"day_type" binding:"required,oneof=weekday rest_day public_holiday"`
+	Minutes    int    `json:"minutes" binding:"required,min=1"`
+	Note       string `json:"note" binding:"max=255"`
+}
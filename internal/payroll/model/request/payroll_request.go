@@ -3,3 +3,35 @@ package request
 type GeneratePayrollRequest struct {
 	PeriodCode string `json:"period_code" binding:"required"`
 }
+
+type RecordAttendanceRequest struct {
+	PeriodCode  string `json:"period_code" binding:"required"`
+	EmployeeID  int64  `json:"employee_id" binding:"required"`
+	WorkingDays int    `json:"working_days" binding:"required,min=1"`
+	DaysPresent int    `json:"days_present" binding:"min=0,ltefield=WorkingDays"`
+}
+
+// GenerateTHRRequest runs THR for the holiday on holiday_date (YYYY-MM-DD)
+// for the employees of religions, or for everyone if it is empty.
+type GenerateTHRRequest struct {
+	PeriodCode  string   `json:"period_code" binding:"required"`
+	HolidayDate string   `json:"holiday_date" binding:"required,datetime=2006-01-02"`
+	Religions   []string `json:"religions" binding:"omitempty,dive,oneof=islam protestant catholic hindu buddhist confucian"`
+}
+
+// GenerateOffCycleRequest runs the off-cycle run_type of the period for
+// employees, paying only components, catalog codes.
+type GenerateOffCycleRequest struct {
+	PeriodCode string                    `json:"period_code" binding:"required"`
+	RunType    string                    `json:"run_type" binding:"required,oneof=bonus correction final_pay"`
+	Components []string                  `json:"components" binding:"required,min=1,dive,required"`
+	Employees  []OffCycleEmployeeRequest `json:"employees" binding:"required,min=1,dive"`
+}
+
+// OffCycleEmployeeRequest selects an employee for an off-cycle run. Amounts
+// pays fixed amounts for some of the run's components instead of their
+// formulas.
+type OffCycleEmployeeRequest struct {
+	EmployeeID int64            `json:"employee_id" binding:"required"`
+	Amounts    map[string]int64 `json:"amounts" binding:"omitempty,dive,min=0"`
+}
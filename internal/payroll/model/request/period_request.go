@@ -0,0 +1,13 @@
+package request
+
+// Dates are calendar dates in YYYY-MM-DD form.
+type CreatePeriodRequest struct {
+	Code      string `json:"code" binding:"required"`
+	StartDate string `json:"start_date" binding:"required,datetime=2006-01-02"`
+	EndDate   string `json:"end_date" binding:"required,datetime=2006-01-02"`
+	PayDate   string `json:"pay_date" binding:"required,datetime=2006-01-02"`
+}
+
+type PeriodTransitionRequest struct {
+	Reason string `json:"reason"`
+}
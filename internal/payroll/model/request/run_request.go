@@ -0,0 +1,7 @@
+package request
+
+// ReviewRunRequest carries the reviewer's comment on a payroll run; a
+// rejection requires one.
+type ReviewRunRequest struct {
+	Comment string `json:"comment" binding:"max=1000"`
+}
@@ -0,0 +1,11 @@
+package request
+
+// ScheduleSalaryRequest sets an employee's salary from effective_from
+// (YYYY-MM-DD), which may be in the future. The caller is recorded as the
+// approver.
+type ScheduleSalaryRequest struct {
+	BaseSalary    int64  `json:"base_salary" binding:"required,gt=0"`
+	Allowance     int64  `json:"allowance" binding:"gte=0"`
+	EffectiveFrom string `json:"effective_from" binding:"required,datetime=2006-01-02"`
+	Reason        string `json:"reason" binding:"required"`
+}
@@ -0,0 +1,11 @@
+package response
+
+import "time"
+
+type BankAccountResponse struct {
+	EmployeeID    int64     `json:"employee_id"`
+	BankCode      string    `json:"bank_code"`
+	AccountNumber string    `json:"account_number"`
+	AccountName   string    `json:"account_name"`
+	UpdateAt      time.Time `json:"update_at"`
+}
@@ -0,0 +1,28 @@
+package response
+
+import "time"
+
+type ComponentResponse struct {
+	ID       int64     `json:"id"`
+	Code     string    `json:"code"`
+	Name     string    `json:"name"`
+	Type     string    `json:"type"`
+	Taxable  bool      `json:"taxable"`
+	Formula  string    `json:"formula"`
+	IsActive bool      `json:"is_active"`
+	CreateAt time.Time `json:"create_at"`
+	UpdateAt time.Time `json:"update_at"`
+}
+
+type ComponentListResponse []ComponentResponse
+
+type EmployeeComponentResponse struct {
+	EmployeeID      int64             `json:"employee_id"`
+	Component       ComponentResponse `json:"component"`
+	AmountOverride  *int64            `json:"amount_override"`
+	FormulaOverride *string           `json:"formula_override"`
+	Formula         string            `json:"formula"`
+	UpdateAt        time.Time         `json:"update_at"`
+}
+
+type EmployeeComponentListResponse []EmployeeComponentResponse
@@ -3,16 +3,49 @@ package response
 import "time"
 
 type EmployeeResponse struct {
-	ID         int64     `json:"id"`
-	Code       string    `json:"code"`
-	FullName   string    `json:"full_name"`
-	Email      string    `json:"email"`
-	BaseSalary int64     `json:"base_salary"`
-	Allowance  int64     `json:"allowance"`
-	IsActive   bool      `json:"is_active"`
-	HireDate   time.Time `json:"hire_date"`
-	CreateAt   time.Time `json:"create_at"`
-	UpdateAt   time.Time `json:"update_at"`
+	ID                int64      `json:"id"`
+	Code              string     `json:"code"`
+	FullName          string     `json:"full_name"`
+	Email             string     `json:"email"`
+	Department        string     `json:"department"`
+	Religion          string     `json:"religion,omitempty"`
+	BaseSalary        int64      `json:"base_salary"`
+	Allowance         int64      `json:"allowance"`
+	PTKPStatus        string     `json:"ptkp_status"`
+	NPWP              string     `json:"npwp"`
+	IsActive          bool       `json:"is_active"`
+	HireDate          time.Time  `json:"hire_date"`
+	TerminationDate   *time.Time `json:"termination_date"`
+	TerminationReason string     `json:"termination_reason,omitempty"`
+	FinalPay          bool       `json:"final_pay"`
+	DeletedAt         *time.Time `json:"deleted_at,omitempty"`
+	CreateAt          time.Time  `json:"create_at"`
+	UpdateAt          time.Time  `json:"update_at"`
 }
 
 type EmployeeListResponse []EmployeeResponse
+
+type EmployeePageResponse struct {
+	Data  EmployeeListResponse `json:"data"`
+	Total int                  `json:"total"`
+	// NextCursor fetches the next page; empty on the last one.
+	NextCursor string `json:"next_cursor,omitempty"`
+}
+
+type EmployeeImportResponse struct {
+	Format   string                      `json:"format"`
+	DryRun   bool                        `json:"dry_run"`
+	Rejected bool                        `json:"rejected"`
+	Total    int                         `json:"total_rows"`
+	Invalid  int                         `json:"invalid_rows"`
+	Imported int                         `json:"imported"`
+	Rows     []EmployeeImportRowResponse `json:"rows"`
+}
+
+type EmployeeImportRowResponse struct {
+	Line       int      `json:"line"`
+	Code       string   `json:"code"`
+	Status     string   `json:"status"`
+	Errors     []string `json:"errors,omitempty"`
+	EmployeeID int64    `json:"employee_id,omitempty"`
+}
@@ -0,0 +1,51 @@
+package response
+
+import "time"
+
+type LeaveTypeResponse struct {
+	ID                    int64     `json:"id"`
+	Code                  string    `json:"code"`
+	Name                  string    `json:"name"`
+	Paid                  bool      `json:"paid"`
+	AnnualDays            int       `json:"annual_days"`
+	EligibleAfterMonths   int       `json:"eligible_after_months"`
+	CarryOverDays         int       `json:"carry_over_days"`
+	CarryOverExpiryMonths int       `json:"carry_over_expiry_months"`
+	IsActive              bool      `json:"is_active"`
+	CreateAt              time.Time `json:"create_at"`
+	UpdateAt              time.Time `json:"update_at"`
+}
+
+type LeaveTypeListResponse []LeaveTypeResponse
+
+type LeaveRequestResponse struct {
+	ID           int64      `json:"id"`
+	EmployeeID   int64      `json:"employee_id"`
+	LeaveType    string     `json:"leave_type"`
+	StartDate    string     `json:"start_date"`
+	EndDate      string     `json:"end_date"`
+	Days         int        `json:"days"`
+	Reason       string     `json:"reason"`
+	Status       string     `json:"status"`
+	RequestedBy  string     `json:"requested_by"`
+	DecidedBy    string     `json:"decided_by,omitempty"`
+	DecisionNote string     `json:"decision_note,omitempty"`
+	DecidedAt    *time.Time `json:"decided_at,omitempty"`
+	CreateAt     time.Time  `json:"create_at"`
+}
+
+type LeaveRequestListResponse []LeaveRequestResponse
+
+type LeaveBalanceResponse struct {
+	LeaveType   string `json:"leave_type"`
+	Year        int    `json:"year"`
+	Tracked     bool   `json:"tracked"`
+	Entitlement int    `json:"entitlement"`
+	CarriedOver int    `json:"carried_over"`
+	Expired     int    `json:"expired"`
+	Used        int    `json:"used"`
+	Pending     int    `json:"pending"`
+	Available   *int   `json:"available,omitempty"`
+}
+
+type LeaveBalanceListResponse []LeaveBalanceResponse
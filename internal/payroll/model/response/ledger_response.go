@@ -0,0 +1,31 @@
+package response
+
+import "time"
+
+type GLMappingResponse struct {
+	Code           string    `json:"code"`
+	Description    string    `json:"description"`
+	ExpenseAccount string    `json:"expense_account"`
+	PayableAccount string    `json:"payable_account"`
+	CostCenter     string    `json:"cost_center"`
+	UpdateAt       time.Time `json:"update_at"`
+}
+
+type GLMappingListResponse []GLMappingResponse
+
+type JournalResponse struct {
+	PeriodCode  string                 `json:"period_code"`
+	Date        string                 `json:"date"`
+	Reference   string                 `json:"reference"`
+	TotalDebit  int64                  `json:"total_debit"`
+	TotalCredit int64                  `json:"total_credit"`
+	Entries     []JournalEntryResponse `json:"entries"`
+}
+
+type JournalEntryResponse struct {
+	Account     string `json:"account"`
+	CostCenter  string `json:"cost_center"`
+	Description string `json:"description"`
+	Debit       int64  `json:"debit"`
+	Credit      int64  `json:"credit"`
+}
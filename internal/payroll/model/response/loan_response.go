@@ -0,0 +1,34 @@
+package response
+
+import "time"
+
+type LoanResponse struct {
+	ID               int64                   `json:"id"`
+	EmployeeID       int64                   `json:"employee_id"`
+	Kind             string                  `json:"kind"`
+	Principal        int64                   `json:"principal"`
+	InterestRateBP   int64                   `json:"interest_rate_bp"`
+	Installments     int                     `json:"installments"`
+	StartPeriodCode  string                  `json:"start_period_code"`
+	PayoffPeriodCode *string                 `json:"payoff_period_code"`
+	Note             string                  `json:"note"`
+	Status           string                  `json:"status"`
+	Total            int64                   `json:"total"`
+	Installment      int64                   `json:"installment"`
+	Repaid           int64                   `json:"repaid"`
+	PaidOff          int64                   `json:"paid_off"`
+	Outstanding      int64                   `json:"outstanding"`
+	Schedule         []int64                 `json:"schedule"`
+	Repayments       []LoanRepaymentResponse `json:"repayments,omitempty"`
+	CreateAt         time.Time               `json:"create_at"`
+	UpdateAt         time.Time               `json:"update_at"`
+}
+
+type LoanRepaymentResponse struct {
+	PayslipID  int64  `json:"payslip_id"`
+	PeriodCode string `json:"period_code"`
+	Status     string `json:"status"`
+	Amount     int64  `json:"amount"`
+}
+
+type LoanListResponse []LoanResponse
@@ -0,0 +1,17 @@
+package response
+
+import "time"
+
+type OvertimeResponse struct {
+	ID         int64     `json:"id"`
+	EmployeeID int64     `json:"employee_id"`
+	PeriodCode string    `json:"period_code"`
+	WorkDate   string    `json:"work_date"`
+	DayType    string    `json:"day_type"`
+	Minutes    int       `json:"minutes"`
+	Note       string    `json:"note"`
+	CreateAt   time.Time `json:"create_at"`
+	UpdateAt   time.Time `json:"update_at"`
+}
+
+type OvertimeListResponse []OvertimeResponse
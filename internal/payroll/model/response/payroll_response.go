@@ -1,19 +1,73 @@
 package response
 
+import "time"
+
 type PayslipResponse struct {
-	ID           int64  `json:"id"`
-	EmployeeID   int64  `json:"employee_id"`
-	EmployeeName string `json:"employee_name"`
-	PeriodCode   string `json:"period_code"`
-	BaseSalary   int64  `json:"base_salary"`
-	Allowance    int64  `json:"allowance"`
-	Deduction    int64  `json:"deduction"`
-	NetSalary    int64  `json:"net_salary"`
+	ID              int64                    `json:"id"`
+	EmployeeID      int64                    `json:"employee_id"`
+	EmployeeName    string                   `json:"employee_name"`
+	PeriodCode      string                   `json:"period_code"`
+	RunType         string                   `json:"run_type"`
+	Status          string                   `json:"status"`
+	TotalEarnings   int64                    `json:"total_earnings"`
+	TotalDeductions int64                    `json:"total_deductions"`
+	NetSalary       int64                    `json:"net_salary"`
+	EmployerCost    int64                    `json:"employer_cost"`
+	TaxableIncome   int64                    `json:"taxable_income"`
+	IncomeTax       int64                    `json:"income_tax"`
+	TaxMethod       string                   `json:"tax_method"`
+	Proration       ProrationResponse        `json:"proration"`
+	Lines           []PayslipLineResponse    `json:"lines"`
+	TaxLines        []PayslipTaxLineResponse `json:"tax_lines"`
+}
+
+type ProrationResponse struct {
+	Method     string  `json:"method"`
+	Days       int     `json:"days"`
+	PeriodDays int     `json:"period_days"`
+	Factor     float64 `json:"factor"`
+}
+
+type PayslipLineResponse struct {
+	Code    string `json:"code"`
+	Label   string `json:"label"`
+	Type    string `json:"type"`
+	Amount  int64  `json:"amount"`
+	Taxable bool   `json:"taxable"`
+	Source  string `json:"source"`
+	LoanID  *int64 `json:"loan_id,omitempty"`
+}
+
+type PayslipTaxLineResponse struct {
+	Code   string `json:"code"`
+	Label  string `json:"label"`
+	Amount int64  `json:"amount"`
 }
 
 type GeneratePayrollResponse struct {
 	PeriodCode   string `json:"period_code"`
+	RunType      string `json:"run_type"`
 	TotalPayslip int    `json:"total_payslip"`
+	Created      int    `json:"created"`
+	Updated      int    `json:"updated"`
+	Unchanged    int    `json:"unchanged"`
+	Removed      int    `json:"removed"`
 }
 
 type PayslipListResponse []PayslipResponse
+
+type AttendanceResponse struct {
+	EmployeeID  int64     `json:"employee_id"`
+	PeriodCode  string    `json:"period_code"`
+	WorkingDays int       `json:"working_days"`
+	DaysPresent int       `json:"days_present"`
+	UpdateAt    time.Time `json:"update_at"`
+}
+
+type PayslipYTDResponse struct {
+	Year       int   `json:"year"`
+	Earnings   int64 `json:"earnings"`
+	Deductions int64 `json:"deductions"`
+	IncomeTax  int64 `json:"income_tax"`
+	NetSalary  int64 `json:"net_salary"`
+}
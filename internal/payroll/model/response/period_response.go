@@ -0,0 +1,25 @@
+package response
+
+import "time"
+
+type PeriodResponse struct {
+	ID        int64                 `json:"id"`
+	Code      string                `json:"code"`
+	StartDate string                `json:"start_date"`
+	EndDate   string                `json:"end_date"`
+	PayDate   string                `json:"pay_date"`
+	Status    string                `json:"status"`
+	CreateAt  time.Time             `json:"create_at"`
+	UpdateAt  time.Time             `json:"update_at"`
+	Events    []PeriodEventResponse `json:"events,omitempty"`
+}
+
+type PeriodEventResponse struct {
+	Action     string    `json:"action"`
+	FromStatus string    `json:"from_status"`
+	ToStatus   string    `json:"to_status"`
+	Reason     string    `json:"reason"`
+	CreateAt   time.Time `json:"create_at"`
+}
+
+type PeriodListResponse []PeriodResponse
@@ -0,0 +1,30 @@
+package response
+
+import "time"
+
+type PayrollRunResponse struct {
+	PeriodCode     string                    `json:"period_code"`
+	RunType        string                    `json:"run_type"`
+	Status         string                    `json:"status"`
+	Round          int                       `json:"round"`
+	TotalNet       int64                     `json:"total_net"`
+	RequiredLevels int                       `json:"required_levels"`
+	Approvers      []string                  `json:"approvers"`
+	GeneratedBy    string                    `json:"generated_by"`
+	SubmittedBy    string                    `json:"submitted_by"`
+	CreateAt       time.Time                 `json:"create_at"`
+	UpdateAt       time.Time                 `json:"update_at"`
+	Events         []PayrollRunEventResponse `json:"events,omitempty"`
+}
+
+type PayrollRunEventResponse struct {
+	Round      int       `json:"round"`
+	Action     string    `json:"action"`
+	FromStatus string    `json:"from_status"`
+	ToStatus   string    `json:"to_status"`
+	Actor      string    `json:"actor"`
+	Comment    string    `json:"comment"`
+	CreateAt   time.Time `json:"create_at"`
+}
+
+type PayrollRunListResponse []PayrollRunResponse
@@ -0,0 +1,16 @@
+package response
+
+import "time"
+
+type SalaryResponse struct {
+	ID            int64     `json:"id"`
+	EmployeeID    int64     `json:"employee_id"`
+	BaseSalary    int64     `json:"base_salary"`
+	Allowance     int64     `json:"allowance"`
+	EffectiveFrom string    `json:"effective_from"`
+	Reason        string    `json:"reason"`
+	ApprovedBy    string    `json:"approved_by"`
+	CreateAt      time.Time `json:"create_at"`
+}
+
+type SalaryListResponse []SalaryResponse
@@ -0,0 +1,102 @@
+// Package overtime prices overtime work the way PP 35/2021 sets it out: the
+// hourly wage is 1/173 of the monthly wage, and every hour is paid at a
+// multiple of it that rises with the hours worked that day.
+package overtime
+
+import (
+	"fmt"
+	"strconv"
+)
+
+const (
+	DayWeekday       = "weekday"
+	DayRestDay       = "rest_day"
+	DayPublicHoliday = "public_holiday"
+)
+
+// Work weeks the rest day and public holiday tiers are set for.
+const (
+	FiveDayWeek = 5
+	SixDayWeek  = 6
+)
+
+// HoursPerMonth divides the monthly wage into the hourly wage.
+const HoursPerMonth = 173
+
+// ParseWorkWeek checks that days is a supported number of working days a
+// week.
+func ParseWorkWeek(days string) (int, error) {
+	n, err := strconv.Atoi(days)
+	if err != nil || (n != FiveDayWeek && n != SixDayWeek) {
+		return 0, fmt.Errorf("work week must be %d or %d days, got %q", FiveDayWeek, SixDayWeek, days)
+	}
+	return n, nil
+}
+
+// tier pays the hours up to upTo at halves times the hourly wage.
+type tier struct {
+	upTo   int
+	halves int64
+}
+
+// tiers returns the multipliers of a day, the last tier ending at the most
+// overtime the day allows.
+func tiers(workWeek int, dayType string) ([]tier, error) {
+	switch {
+	case dayType == DayWeekday:
+		// The first hour at 1.5 times, the next ones at twice, at most 4
+		// hours.
+		return []tier{{1, 3}, {4, 4}}, nil
+	case dayType != DayRestDay && dayType != DayPublicHoliday:
+		return nil, fmt.Errorf("unknown day type %q", dayType)
+	case workWeek == SixDayWeek:
+		return []tier{{7, 4}, {8, 6}, {10, 8}}, nil
+	default:
+		return []tier{{8, 4}, {9, 6}, {11, 8}}, nil
+	}
+}
+
+// MaxMinutes is the most overtime that can be worked on a day of the type.
+func MaxMinutes(workWeek int, dayType string) (int, error) {
+	t, err := tiers(workWeek, dayType)
+	if err != nil {
+		return 0, err
+	}
+	return t[len(t)-1].upTo * 60, nil
+}
+
+// Day is the overtime worked on one day, paid on the monthly wage in
+// effect that day.
+type Day struct {
+	Type        string
+	Minutes     int
+	MonthlyWage int64
+}
+
+// Pay prices the days together, rounding once to the nearest rupiah.
+func Pay(workWeek int, days []Day) (int64, error) {
+	const perHalfMinute = HoursPerMonth * 60 * 2
+
+	var total int64
+	for _, d := range days {
+		t, err := tiers(workWeek, d.Type)
+		if err != nil {
+			return 0, err
+		}
+		if limit := t[len(t)-1].upTo * 60; d.Minutes > limit {
+			return 0, fmt.Errorf("%d minutes of overtime on a %s, at most %d allowed", d.Minutes, d.Type, limit)
+		}
+
+		var weighted int64
+		from := 0
+		for _, tr := range t {
+			to := min(d.Minutes, tr.upTo*60)
+			if to > from {
+				weighted += int64(to-from) * tr.halves
+			}
+			from = tr.upTo * 60
+		}
+		total += d.MonthlyWage * weighted
+	}
+	return (total + perHalfMinute/2) / perHalfMinute, nil
+}
@@ -0,0 +1,113 @@
+package overtime
+
+import "testing"
+
+// wage is a monthly wage whose hourly wage, 1/173 of it, is 20,000.
+const wage = 173 * 20000
+
+func TestPay(t *testing.T) {
+	tests := []struct {
+		name     string
+		workWeek int
+		days     []Day
+		want     int64
+	}{
+		{"weekday first hour at 1.5", FiveDayWeek, []Day{{DayWeekday, 60, wage}}, 30000},
+		{"weekday half hour", FiveDayWeek, []Day{{DayWeekday, 30, wage}}, 15000},
+		{"weekday later hours at 2", FiveDayWeek, []Day{{DayWeekday, 180, wage}}, 30000 + 2*40000},
+		{"weekday at most", FiveDayWeek, []Day{{DayWeekday, 240, wage}}, 30000 + 3*40000},
+		{"5-day rest day first 8 hours at 2", FiveDayWeek, []Day{{DayRestDay, 480, wage}}, 8 * 40000},
+		{"5-day rest day 9th hour at 3", FiveDayWeek, []Day{{DayRestDay, 540, wage}}, 8*40000 + 60000},
+		{"5-day rest day 10th and 11th hour at 4", FiveDayWeek, []Day{{DayRestDay, 660, wage}}, 8*40000 + 60000 + 2*80000},
+		{"6-day rest day first 7 hours at 2", SixDayWeek, []Day{{DayRestDay, 420, wage}}, 7 * 40000},
+		{"6-day rest day 8th hour at 3", SixDayWeek, []Day{{DayRestDay, 480, wage}}, 7*40000 + 60000},
+		{"6-day public holiday at most", SixDayWeek, []Day{{DayPublicHoliday, 600, wage}}, 7*40000 + 60000 + 2*80000},
+		{"weekday tiers do not depend on the week", SixDayWeek, []Day{{DayWeekday, 120, wage}}, 30000 + 40000},
+		{
+			"wage in effect each day",
+			FiveDayWeek,
+			[]Day{{DayWeekday, 60, wage}, {DayWeekday, 60, 2 * wage}},
+			30000 + 60000,
+		},
+		{
+			// Each hour is 43,352.6; rounding per day would pay 86,706.
+			"rounded once",
+			FiveDayWeek,
+			[]Day{{DayWeekday, 60, 5000000}, {DayWeekday, 60, 5000000}},
+			86705,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Pay(tt.workWeek, tt.days)
+			if err != nil {
+				t.Fatalf("Pay() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("Pay() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPayErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		day  Day
+	}{
+		{"weekday over 4 hours", Day{DayWeekday, 241, wage}},
+		{"5-day rest day over 11 hours", Day{DayRestDay, 661, wage}},
+		{"unknown day type", Day{"sunday", 60, wage}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := Pay(FiveDayWeek, []Day{tt.day}); err == nil {
+				t.Error("Pay() error = nil, want an error")
+			}
+		})
+	}
+}
+
+func TestMaxMinutes(t *testing.T) {
+	tests := []struct {
+		workWeek int
+		dayType  string
+		want     int
+	}{
+		{FiveDayWeek, DayWeekday, 240},
+		{SixDayWeek, DayWeekday, 240},
+		{FiveDayWeek, DayRestDay, 660},
+		{FiveDayWeek, DayPublicHoliday, 660},
+		{SixDayWeek, DayRestDay, 600},
+		{SixDayWeek, DayPublicHoliday, 600},
+	}
+	for _, tt := range tests {
+		got, err := MaxMinutes(tt.workWeek, tt.dayType)
+		if err != nil {
+			t.Fatalf("MaxMinutes(%d, %s) error = %v", tt.workWeek, tt.dayType, err)
+		}
+		if got != tt.want {
+			t.Errorf("MaxMinutes(%d, %s) = %d, want %d", tt.workWeek, tt.dayType, got, tt.want)
+		}
+	}
+}
+
+func TestParseWorkWeek(t *testing.T) {
+	tests := []struct {
+		days    string
+		want    int
+		wantErr bool
+	}{
+		{"5", 5, false},
+		{"6", 6, false},
+		{"7", 0, true},
+		{"five", 0, true},
+		{"", 0, true},
+	}
+	for _, tt := range tests {
+		got, err := ParseWorkWeek(tt.days)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("ParseWorkWeek(%q) = %d, %v, want %d, error %v", tt.days, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
@@ -0,0 +1,106 @@
+package proration
+
+import (
+	"fmt"
+	"time"
+)
+
+const (
+	// CalendarDays divides by the number of calendar days in the period.
+	CalendarDays = "calendar_days"
+	// WorkingDays counts Monday to Friday only.
+	WorkingDays = "working_days"
+	// Fixed30 treats every month as 30 days (30/360 day count).
+	Fixed30 = "fixed_30"
+)
+
+// ParseMethod checks that method is one of the supported proration methods.
+func ParseMethod(method string) (string, error) {
+	switch method {
+	case CalendarDays, WorkingDays, Fixed30:
+		return method, nil
+	}
+	return "", fmt.Errorf("unknown proration method %q", method)
+}
+
+// Segment is a monthly rate paid from From to To, both inclusive.
+type Segment struct {
+	From   time.Time
+	To     time.Time
+	Amount int64
+}
+
+// Result is the prorated amount of a set of segments.
+type Result struct {
+	Method     string
+	Days       int
+	PeriodDays int
+	Amount     int64
+}
+
+// Factor is the share of the period that was paid.
+func (r Result) Factor() float64 {
+	if r.PeriodDays == 0 {
+		return 0
+	}
+	return float64(r.Days) / float64(r.PeriodDays)
+}
+
+// Prorate pays each segment for the days it covers inside the period. With a
+// single segment covering the whole period the result is exactly its amount.
+func Prorate(method string, periodStart, periodEnd time.Time, segments []Segment) Result {
+	res := Result{Method: method, PeriodDays: count(method, periodStart, periodEnd)}
+	if res.PeriodDays == 0 {
+		return res
+	}
+
+	for _, seg := range segments {
+		from, to := seg.From, seg.To
+		if from.Before(periodStart) {
+			from = periodStart
+		}
+		if to.IsZero() || to.After(periodEnd) {
+			to = periodEnd
+		}
+		if to.Before(from) {
+			continue
+		}
+
+		days := count(method, from, to)
+		res.Days += days
+		res.Amount += (seg.Amount*int64(days)*2 + int64(res.PeriodDays)) / (int64(res.PeriodDays) * 2)
+	}
+	res.Days = min(res.Days, res.PeriodDays)
+	return res
+}
+
+// count returns the number of days from start to end inclusive under the
+// method.
+func count(method string, start, end time.Time) int {
+	if end.Before(start) {
+		return 0
+	}
+	switch method {
+	case WorkingDays:
+		n := 0
+		for d := start; !d.After(end); d = d.AddDate(0, 0, 1) {
+			if wd := d.Weekday(); wd != time.Saturday && wd != time.Sunday {
+				n++
+			}
+		}
+		return n
+	case Fixed30:
+		d1 := min(start.Day(), 30)
+		d2 := min(end.Day(), 30)
+		if isMonthEnd(end) {
+			d2 = 30
+		}
+		return (end.Year()-start.Year())*360 + int(end.Month()-start.Month())*30 + d2 - d1 + 1
+	default:
+		return int(end.Sub(start).Hours()/24) + 1
+	}
+}
+
+func isMonthEnd(d time.Time) bool {
+	return d.AddDate(0, 0, 1).Month() != d.Month()
+}
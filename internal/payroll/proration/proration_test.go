@@ -0,0 +1,139 @@
+package proration
+
+import (
+	"testing"
+	"time"
+)
+
+func date(s string) time.Time {
+	d, err := time.Parse("2006-01-02", s)
+	if err != nil {
+		panic(err)
+	}
+	return d
+}
+
+func TestProrate(t *testing.T) {
+	tests := []struct {
+		name           string
+		method         string
+		start, end     string
+		segments       []Segment
+		wantDays       int
+		wantPeriodDays int
+		wantAmount     int64
+	}{
+		{
+			"calendar days, whole period",
+			CalendarDays, "2024-07-01", "2024-07-31",
+			[]Segment{{From: date("2020-01-01"), Amount: 10000000}},
+			31, 31, 10000000,
+		},
+		{
+			"calendar days, hired mid-month",
+			CalendarDays, "2024-07-01", "2024-07-31",
+			[]Segment{{From: date("2024-07-16"), Amount: 10000000}},
+			16, 31, 5161290,
+		},
+		{
+			"calendar days, terminated mid-month",
+			CalendarDays, "2024-06-01", "2024-06-30",
+			[]Segment{{From: date("2020-01-01"), To: date("2024-06-20"), Amount: 9000000}},
+			20, 30, 6000000,
+		},
+		{
+			// Each segment is rounded on its own: 4,838,709.68 and
+			// 5,161,290.32.
+			"calendar days, salary change",
+			CalendarDays, "2024-07-01", "2024-07-31",
+			[]Segment{
+				{From: date("2020-01-01"), To: date("2024-07-15"), Amount: 10000000},
+				{From: date("2024-07-16"), Amount: 10000000},
+			},
+			31, 31, 10000000,
+		},
+		{
+			"calendar days, raise",
+			CalendarDays, "2024-06-01", "2024-06-30",
+			[]Segment{
+				{From: date("2020-01-01"), To: date("2024-06-15"), Amount: 10000000},
+				{From: date("2024-06-16"), Amount: 12000000},
+			},
+			30, 30, 11000000,
+		},
+		{
+			"working days, whole period",
+			WorkingDays, "2024-06-01", "2024-06-30",
+			[]Segment{{From: date("2020-01-01"), Amount: 10000000}},
+			20, 20, 10000000,
+		},
+		{
+			"working days, hired on a Monday",
+			WorkingDays, "2024-06-01", "2024-06-30",
+			[]Segment{{From: date("2024-06-17"), Amount: 10000000}},
+			10, 20, 5000000,
+		},
+		{
+			"working days, hired on a Saturday",
+			WorkingDays, "2024-06-01", "2024-06-30",
+			[]Segment{{From: date("2024-06-15"), Amount: 10000000}},
+			10, 20, 5000000,
+		},
+		{
+			"fixed 30, 31-day month",
+			Fixed30, "2024-07-01", "2024-07-31",
+			[]Segment{{From: date("2024-07-16"), Amount: 10000000}},
+			15, 30, 5000000,
+		},
+		{
+			"fixed 30, February counts as 30",
+			Fixed30, "2024-02-01", "2024-02-29",
+			[]Segment{{From: date("2020-01-01"), Amount: 10000000}},
+			30, 30, 10000000,
+		},
+		{
+			"fixed 30, terminated in February",
+			Fixed30, "2024-02-01", "2024-02-29",
+			[]Segment{{From: date("2020-01-01"), To: date("2024-02-14"), Amount: 10000000}},
+			14, 30, 4666667,
+		},
+		{
+			"segment outside the period",
+			CalendarDays, "2024-06-01", "2024-06-30",
+			[]Segment{{From: date("2024-07-01"), Amount: 10000000}},
+			0, 30, 0,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Prorate(tt.method, date(tt.start), date(tt.end), tt.segments)
+			if got.Days != tt.wantDays || got.PeriodDays != tt.wantPeriodDays || got.Amount != tt.wantAmount {
+				t.Errorf("Prorate() = %d/%d days, %d, want %d/%d days, %d",
+					got.Days, got.PeriodDays, got.Amount, tt.wantDays, tt.wantPeriodDays, tt.wantAmount)
+			}
+			if got.Method != tt.method {
+				t.Errorf("Prorate() method = %s, want %s", got.Method, tt.method)
+			}
+		})
+	}
+}
+
+func TestFactor(t *testing.T) {
+	if got := (Result{Days: 15, PeriodDays: 30}).Factor(); got != 0.5 {
+		t.Errorf("Factor() = %v, want 0.5", got)
+	}
+	if got := (Result{}).Factor(); got != 0 {
+		t.Errorf("Factor() of an empty period = %v, want 0", got)
+	}
+}
+
+func TestParseMethod(t *testing.T) {
+	for _, m := range []string{CalendarDays, WorkingDays, Fixed30} {
+		if got, err := ParseMethod(m); err != nil || got != m {
+			t.Errorf("ParseMethod(%q) = %q, %v", m, got, err)
+		}
+	}
+	if _, err := ParseMethod("actual_360"); err == nil {
+		t.Error("ParseMethod(actual_360) error = nil, want an error")
+	}
+}
@@ -0,0 +1,65 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"go-payroll-service/internal/payroll/model/domain"
+	"time"
+)
+
+type AttendanceRepository interface {
+	Upsert(ctx context.Context, a domain.Attendance) (domain.Attendance, error)
+	ListByPeriodCode(ctx context.Context, periodCode string) (map[int64]domain.Attendance, error)
+	WithTx(tx *sql.Tx) AttendanceRepository
+}
+
+type attendanceRepository struct {
+	db DBTX
+}
+
+func (r attendanceRepository) Upsert(ctx context.Context, a domain.Attendance) (domain.Attendance, error) {
+	a.UpdatedAt = time.Now()
+
+	_, err := r.db.ExecContext(ctx, `
+		INSERT INTO attendance_summaries(employee_id, period_code, working_days, days_present, updated_at)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (employee_id, period_code)
+		DO UPDATE SET working_days = EXCLUDED.working_days,
+		              days_present = EXCLUDED.days_present,
+		              updated_at = EXCLUDED.updated_at`,
+		a.EmployeeID, a.PeriodCode, a.WorkingDays, a.DaysPresent, a.UpdatedAt,
+	)
+	if err != nil {
+		return domain.Attendance{}, err
+	}
+	return a, nil
+}
+
+func (r attendanceRepository) ListByPeriodCode(ctx context.Context, periodCode string) (map[int64]domain.Attendance, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT employee_id, period_code, working_days, days_present, updated_at
+		FROM attendance_summaries
+		WHERE period_code = $1`, periodCode)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	result := map[int64]domain.Attendance{}
+	for rows.Next() {
+		var a domain.Attendance
+		if err := rows.Scan(&a.EmployeeID, &a.PeriodCode, &a.WorkingDays, &a.DaysPresent, &a.UpdatedAt); err != nil {
+			return nil, err
+		}
+		result[a.EmployeeID] = a
+	}
+	return result, rows.Err()
+}
+
+func (r attendanceRepository) WithTx(tx *sql.Tx) AttendanceRepository {
+	return &attendanceRepository{db: tx}
+}
+
+func NewAttendanceRepository(db *sql.DB) AttendanceRepository {
+	return &attendanceRepository{db: db}
+}
@@ -0,0 +1,132 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"go-payroll-service/internal/payroll/audit"
+	"go-payroll-service/internal/payroll/model/domain"
+	"strings"
+)
+
+// auditChainLockKey is the Postgres advisory lock serializing appends, so
+// that two transactions never chain to the same previous entry.
+const auditChainLockKey = 7_346_109_216
+
+type AuditRepository interface {
+	// LastHash locks the chain until the transaction ends and returns the
+	// hash of the newest entry, or the genesis hash if there is none. It
+	// must run inside a transaction.
+	LastHash(ctx context.Context) (string, error)
+	Create(ctx context.Context, e domain.AuditEntry) (domain.AuditEntry, error)
+	List(ctx context.Context, f domain.AuditFilter) ([]domain.AuditEntry, error)
+	// ListAll returns the whole chain, oldest first.
+	ListAll(ctx context.Context) ([]domain.AuditEntry, error)
+	WithTx(tx *sql.Tx) AuditRepository
+}
+
+type auditRepository struct {
+	db DBTX
+}
+
+const auditColumns = `id, occurred_at, actor, action, entity, entity_id, request_id, changes, prev_hash, hash`
+
+func (r auditRepository) LastHash(ctx context.Context) (string, error) {
+	if _, err := r.db.ExecContext(ctx, `SELECT pg_advisory_xact_lock($1)`, auditChainLockKey); err != nil {
+		return "", fmt.Errorf("lock audit chain: %w", err)
+	}
+	var hash string
+	err := r.db.QueryRowContext(ctx, `SELECT hash FROM audit_log ORDER BY id DESC LIMIT 1`).Scan(&hash)
+	if errors.Is(err, sql.ErrNoRows) {
+		return audit.GenesisHash, nil
+	}
+	return hash, err
+}
+
+func (r auditRepository) Create(ctx context.Context, e domain.AuditEntry) (domain.AuditEntry, error) {
+	err := r.db.QueryRowContext(ctx, `
+		INSERT INTO audit_log(occurred_at, actor, action, entity, entity_id, request_id, changes, prev_hash, hash)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		RETURNING id`,
+		e.OccurredAt, e.Actor, e.Action, e.Entity, e.EntityID, e.RequestID, []byte(e.Changes), e.PrevHash, e.Hash,
+	).Scan(&e.ID)
+	if err != nil {
+		return domain.AuditEntry{}, err
+	}
+	return e, nil
+}
+
+func (r auditRepository) List(ctx context.Context, f domain.AuditFilter) ([]domain.AuditEntry, error) {
+	var (
+		where []string
+		args  []any
+	)
+	add := func(cond string, v any) {
+		args = append(args, v)
+		where = append(where, fmt.Sprintf(cond, len(args)))
+	}
+	if f.Entity != "" {
+		add("entity = $%d", f.Entity)
+	}
+	if f.EntityID != "" {
+		add("entity_id = $%d", f.EntityID)
+	}
+	if f.Actor != "" {
+		add("actor = $%d", f.Actor)
+	}
+	if f.From != nil {
+		add("occurred_at >= $%d", *f.From)
+	}
+	if f.To != nil {
+		add("occurred_at < $%d", *f.To)
+	}
+
+	query := `SELECT ` + auditColumns + ` FROM audit_log`
+	if len(where) > 0 {
+		query += ` WHERE ` + strings.Join(where, " AND ")
+	}
+	query += ` ORDER BY id DESC`
+	if f.Limit > 0 {
+		args = append(args, f.Limit)
+		query += fmt.Sprintf(` LIMIT $%d`, len(args))
+	}
+	return r.query(ctx, query, args...)
+}
+
+func (r auditRepository) ListAll(ctx context.Context) ([]domain.AuditEntry, error) {
+	return r.query(ctx, `SELECT `+auditColumns+` FROM audit_log ORDER BY id`)
+}
+
+func (r auditRepository) query(ctx context.Context, query string, args ...any) ([]domain.AuditEntry, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var result []domain.AuditEntry
+	for rows.Next() {
+		var (
+			e       domain.AuditEntry
+			changes []byte
+		)
+		if err := rows.Scan(&e.ID, &e.OccurredAt, &e.Actor, &e.Action, &e.Entity, &e.EntityID,
+			&e.RequestID, &changes, &e.PrevHash, &e.Hash); err != nil {
+			return nil, err
+		}
+		e.Changes = changes
+		result = append(result, e)
+	}
+	return result, rows.Err()
+}
+
+func (r auditRepository) WithTx(tx *sql.Tx) AuditRepository {
+	return &auditRepository{db: tx}
+}
+
+func NewAuditRepository(db *sql.DB) AuditRepository {
+	return &auditRepository{
+		db: db,
+	}
+}
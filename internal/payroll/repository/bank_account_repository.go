@@ -0,0 +1,84 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"go-payroll-service/internal/payroll/model/domain"
+	"go-payroll-service/internal/payroll/util"
+	"time"
+)
+
+type BankAccountRepository interface {
+	GetByEmployeeID(ctx context.Context, employeeID int64) (domain.BankAccount, error)
+	List(ctx context.Context) (map[int64]domain.BankAccount, error)
+	Upsert(ctx context.Context, a domain.BankAccount) (domain.BankAccount, error)
+	WithTx(tx *sql.Tx) BankAccountRepository
+}
+
+type bankAccountRepository struct {
+	db DBTX
+}
+
+func (r bankAccountRepository) GetByEmployeeID(ctx context.Context, employeeID int64) (domain.BankAccount, error) {
+	var a domain.BankAccount
+	err := r.db.QueryRowContext(ctx, `
+		SELECT employee_id, bank_code, account_number, account_name, updated_at
+		FROM employee_bank_accounts
+		WHERE employee_id = $1`, employeeID,
+	).Scan(&a.EmployeeID, &a.BankCode, &a.AccountNumber, &a.AccountName, &a.UpdatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return domain.BankAccount{}, util.ErrNotFound
+	}
+	if err != nil {
+		return domain.BankAccount{}, err
+	}
+	return a, nil
+}
+
+func (r bankAccountRepository) List(ctx context.Context) (map[int64]domain.BankAccount, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT employee_id, bank_code, account_number, account_name, updated_at
+		FROM employee_bank_accounts`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	result := map[int64]domain.BankAccount{}
+	for rows.Next() {
+		var a domain.BankAccount
+		if err := rows.Scan(&a.EmployeeID, &a.BankCode, &a.AccountNumber, &a.AccountName, &a.UpdatedAt); err != nil {
+			return nil, err
+		}
+		result[a.EmployeeID] = a
+	}
+	return result, rows.Err()
+}
+
+func (r bankAccountRepository) Upsert(ctx context.Context, a domain.BankAccount) (domain.BankAccount, error) {
+	a.UpdatedAt = time.Now()
+
+	_, err := r.db.ExecContext(ctx, `
+		INSERT INTO employee_bank_accounts(employee_id, bank_code, account_number, account_name, updated_at)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (employee_id)
+		DO UPDATE SET bank_code = EXCLUDED.bank_code,
+		              account_number = EXCLUDED.account_number,
+		              account_name = EXCLUDED.account_name,
+		              updated_at = EXCLUDED.updated_at`,
+		a.EmployeeID, a.BankCode, a.AccountNumber, a.AccountName, a.UpdatedAt,
+	)
+	if err != nil {
+		return domain.BankAccount{}, err
+	}
+	return a, nil
+}
+
+func (r bankAccountRepository) WithTx(tx *sql.Tx) BankAccountRepository {
+	return &bankAccountRepository{db: tx}
+}
+
+func NewBankAccountRepository(db *sql.DB) BankAccountRepository {
+	return &bankAccountRepository{db: db}
+}
@@ -0,0 +1,51 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"go-payroll-service/internal/payroll/model/domain"
+	"time"
+)
+
+type BPJSRepository interface {
+	ListPrograms(ctx context.Context, asOf time.Time) ([]domain.BPJSProgram, error)
+	WithTx(tx *sql.Tx) BPJSRepository
+}
+
+type bpjsRepository struct {
+	db DBTX
+}
+
+func (r bpjsRepository) ListPrograms(ctx context.Context, asOf time.Time) ([]domain.BPJSProgram, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT DISTINCT ON (code)
+		       code, label, effective_from, employee_rate_bp, employer_rate_bp,
+		       COALESCE(wage_cap, 0), employer_taxable, employee_tax_deductible
+		FROM bpjs_programs
+		WHERE effective_from <= $1
+		ORDER BY code, effective_from DESC`, asOf)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var result []domain.BPJSProgram
+	for rows.Next() {
+		var p domain.BPJSProgram
+		if err := rows.Scan(
+			&p.Code, &p.Label, &p.EffectiveFrom, &p.EmployeeRateBP, &p.EmployerRateBP,
+			&p.WageCap, &p.EmployerTaxable, &p.EmployeeTaxDeductible); err != nil {
+			return nil, err
+		}
+		result = append(result, p)
+	}
+	return result, rows.Err()
+}
+
+func (r bpjsRepository) WithTx(tx *sql.Tx) BPJSRepository {
+	return &bpjsRepository{db: tx}
+}
+
+func NewBPJSRepository(db *sql.DB) BPJSRepository {
+	return &bpjsRepository{db: db}
+}
@@ -0,0 +1,206 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"go-payroll-service/internal/payroll/model/domain"
+	"go-payroll-service/internal/payroll/util"
+	"time"
+)
+
+type ComponentRepository interface {
+	List(ctx context.Context) ([]domain.PayComponent, error)
+	Create(ctx context.Context, c domain.PayComponent) (domain.PayComponent, error)
+	GetByID(ctx context.Context, id int64) (domain.PayComponent, error)
+	Update(ctx context.Context, c domain.PayComponent) (domain.PayComponent, error)
+	ListAssignments(ctx context.Context, employeeID int64) ([]domain.EmployeeComponent, error)
+	ListActiveAssignments(ctx context.Context) ([]domain.EmployeeComponent, error)
+	Assign(ctx context.Context, ec domain.EmployeeComponent) (domain.EmployeeComponent, error)
+	Unassign(ctx context.Context, employeeID, componentID int64) error
+	WithTx(tx *sql.Tx) ComponentRepository
+}
+
+type componentRepository struct {
+	db DBTX
+}
+
+func (r componentRepository) List(ctx context.Context) ([]domain.PayComponent, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT id, code, name, type, taxable, formula, is_active, created_at, updated_at
+		FROM pay_components
+		ORDER BY code`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var results []domain.PayComponent
+	for rows.Next() {
+		var c domain.PayComponent
+		if err := rows.Scan(
+			&c.ID, &c.Code, &c.Name, &c.Type, &c.Taxable, &c.Formula,
+			&c.IsActive, &c.CreatedAt, &c.UpdatedAt); err != nil {
+			return nil, err
+		}
+		results = append(results, c)
+	}
+	return results, rows.Err()
+}
+
+func (r componentRepository) Create(ctx context.Context, c domain.PayComponent) (domain.PayComponent, error) {
+	now := time.Now()
+	c.CreatedAt = now
+	c.UpdatedAt = now
+	c.IsActive = true
+
+	err := r.db.QueryRowContext(ctx, `
+		INSERT INTO pay_components(code, name, type, taxable, formula, is_active, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		RETURNING id`,
+		c.Code, c.Name, c.Type, c.Taxable, c.Formula, c.IsActive, c.CreatedAt, c.UpdatedAt,
+	).Scan(&c.ID)
+	if err != nil {
+		return domain.PayComponent{}, err
+	}
+	return c, nil
+}
+
+func (r componentRepository) GetByID(ctx context.Context, id int64) (domain.PayComponent, error) {
+	var c domain.PayComponent
+	err := r.db.QueryRowContext(ctx, `
+		SELECT id, code, name, type, taxable, formula, is_active, created_at, updated_at
+		FROM pay_components
+		WHERE id = $1`, id,
+	).Scan(
+		&c.ID, &c.Code, &c.Name, &c.Type, &c.Taxable, &c.Formula,
+		&c.IsActive, &c.CreatedAt, &c.UpdatedAt,
+	)
+
+	if errors.Is(err, sql.ErrNoRows) {
+		return domain.PayComponent{}, util.ErrNotFound
+	}
+	if err != nil {
+		return domain.PayComponent{}, err
+	}
+	return c, nil
+}
+
+func (r componentRepository) Update(ctx context.Context, c domain.PayComponent) (domain.PayComponent, error) {
+	c.UpdatedAt = time.Now()
+
+	res, err := r.db.ExecContext(ctx, `
+		UPDATE pay_components
+		SET name=$1, type=$2, taxable=$3, formula=$4, is_active=$5, updated_at=$6
+		WHERE id = $7`,
+		c.Name, c.Type, c.Taxable, c.Formula, c.IsActive, c.UpdatedAt, c.ID,
+	)
+	if err != nil {
+		return domain.PayComponent{}, err
+	}
+
+	aff, err := res.RowsAffected()
+	if err == nil && aff == 0 {
+		return domain.PayComponent{}, util.ErrNotFound
+	}
+	return c, nil
+}
+
+const assignmentColumns = `
+		ec.id, ec.employee_id, ec.component_id, ec.amount_override, ec.formula_override,
+		ec.created_at, ec.updated_at,
+		pc.id, pc.code, pc.name, pc.type, pc.taxable, pc.formula, pc.is_active,
+		pc.created_at, pc.updated_at`
+
+func (r componentRepository) ListAssignments(ctx context.Context, employeeID int64) ([]domain.EmployeeComponent, error) {
+	return r.listAssignments(ctx, `
+		SELECT`+assignmentColumns+`
+		FROM employee_components ec
+		JOIN pay_components pc ON pc.id = ec.component_id
+		WHERE ec.employee_id = $1
+		ORDER BY pc.code`, employeeID)
+}
+
+func (r componentRepository) ListActiveAssignments(ctx context.Context) ([]domain.EmployeeComponent, error) {
+	return r.listAssignments(ctx, `
+		SELECT`+assignmentColumns+`
+		FROM employee_components ec
+		JOIN pay_components pc ON pc.id = ec.component_id
+		WHERE pc.is_active
+		ORDER BY ec.employee_id, pc.code`)
+}
+
+func (r componentRepository) listAssignments(ctx context.Context, query string, args ...any) ([]domain.EmployeeComponent, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var results []domain.EmployeeComponent
+	for rows.Next() {
+		var ec domain.EmployeeComponent
+		var amount sql.NullInt64
+		var formula sql.NullString
+		c := &ec.Component
+		if err := rows.Scan(
+			&ec.ID, &ec.EmployeeID, &ec.ComponentID, &amount, &formula,
+			&ec.CreatedAt, &ec.UpdatedAt,
+			&c.ID, &c.Code, &c.Name, &c.Type, &c.Taxable, &c.Formula, &c.IsActive,
+			&c.CreatedAt, &c.UpdatedAt); err != nil {
+			return nil, err
+		}
+		if amount.Valid {
+			ec.AmountOverride = &amount.Int64
+		}
+		if formula.Valid {
+			ec.FormulaOverride = &formula.String
+		}
+		results = append(results, ec)
+	}
+	return results, rows.Err()
+}
+
+func (r componentRepository) Assign(ctx context.Context, ec domain.EmployeeComponent) (domain.EmployeeComponent, error) {
+	now := time.Now()
+	ec.CreatedAt = now
+	ec.UpdatedAt = now
+
+	err := r.db.QueryRowContext(ctx, `
+		INSERT INTO employee_components(employee_id, component_id, amount_override, formula_override, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		ON CONFLICT (employee_id, component_id)
+		DO UPDATE SET amount_override = EXCLUDED.amount_override,
+		              formula_override = EXCLUDED.formula_override,
+		              updated_at = EXCLUDED.updated_at
+		RETURNING id, created_at`,
+		ec.EmployeeID, ec.ComponentID, ec.AmountOverride, ec.FormulaOverride, ec.CreatedAt, ec.UpdatedAt,
+	).Scan(&ec.ID, &ec.CreatedAt)
+	if err != nil {
+		return domain.EmployeeComponent{}, err
+	}
+	return ec, nil
+}
+
+func (r componentRepository) Unassign(ctx context.Context, employeeID, componentID int64) error {
+	res, err := r.db.ExecContext(ctx, `
+		DELETE FROM employee_components
+		WHERE employee_id = $1 AND component_id = $2`, employeeID, componentID)
+	if err != nil {
+		return err
+	}
+
+	aff, err := res.RowsAffected()
+	if err == nil && aff == 0 {
+		return util.ErrNotFound
+	}
+	return nil
+}
+
+func (r componentRepository) WithTx(tx *sql.Tx) ComponentRepository {
+	return &componentRepository{db: tx}
+}
+
+func NewComponentRepository(db *sql.DB) ComponentRepository {
+	return &componentRepository{db: db}
+}
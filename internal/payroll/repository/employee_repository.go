@@ -4,29 +4,54 @@ import (
 	"context"
 	"database/sql"
 	"errors"
+	"fmt"
 	"go-payroll-service/internal/payroll/model/domain"
 	"go-payroll-service/internal/payroll/util"
+	"strconv"
+	"strings"
 	"time"
 )
 
 type EmployeeRepository interface {
 	List(ctx context.Context) ([]domain.Employee, error)
+	// Page lists a page of the employees matching q, in its sort order.
+	Page(ctx context.Context, q domain.EmployeeQuery) (domain.EmployeePage, error)
 	Create(ctx context.Context, employee domain.Employee) (domain.Employee, error)
 	GetByID(ctx context.Context, id int64) (domain.Employee, error)
 	Update(ctx context.Context, employee domain.Employee) (domain.Employee, error)
+	// Delete soft deletes the employee, keeping the row for the payslips
+	// that reference it.
 	Delete(ctx context.Context, id int64) error
+	WithTx(tx *sql.Tx) EmployeeRepository
 }
 
+// employeeActive is whether the employee is active today: not deleted and
+// not past their last working day.
+const employeeActive = `(e.is_active AND (e.termination_date IS NULL OR e.termination_date >= CURRENT_DATE))`
+
+// currentSalaryJoin attaches the salary in effect today, or on the hire date
+// for an employee who has not started yet, as s.base_salary and s.allowance.
+const currentSalaryJoin = `
+		LEFT JOIN LATERAL (
+			SELECT base_salary, allowance
+			FROM employee_salaries
+			WHERE employee_id = e.id
+			  AND effective_from <= GREATEST(CURRENT_DATE, e.hire_date)
+			ORDER BY effective_from DESC
+			LIMIT 1
+		) s ON TRUE`
+
 type employeeRepository struct {
-	db *sql.DB
+	db DBTX
 }
 
 func (r *employeeRepository) List(ctx context.Context) ([]domain.Employee, error) {
 	rows, err := r.db.QueryContext(ctx, `
-		SELECT id, code, full_name, email, base_salary, allowance, is_active,
-		       hire_date, created_at, updated_at
-		FROM employees
-		ORDER BY id`)
+		SELECT e.id, e.code, e.full_name, e.email, e.department, e.religion, COALESCE(s.base_salary, 0), COALESCE(s.allowance, 0),
+		       e.ptkp_status, e.npwp, `+employeeActive+`, e.hire_date, e.termination_date, e.termination_reason,
+		       e.final_pay, e.deleted_at, e.created_at, e.updated_at
+		FROM employees e`+currentSalaryJoin+`
+		ORDER BY e.id`)
 	if err != nil {
 		return nil, err
 	}
@@ -36,9 +61,10 @@ func (r *employeeRepository) List(ctx context.Context) ([]domain.Employee, error
 	for rows.Next() {
 		var e domain.Employee
 		if err := rows.Scan(
-			&e.ID, &e.Code, &e.FullName, &e.Email,
-			&e.BaseSalary, &e.Allowance, &e.IsActive,
-			&e.HireDate, &e.CreatedAt, &e.UpdatedAt); err != nil {
+			&e.ID, &e.Code, &e.FullName, &e.Email, &e.Department, &e.Religion,
+			&e.BaseSalary, &e.Allowance, &e.PTKPStatus, &e.NPWP,
+			&e.IsActive, &e.HireDate, &e.TerminationDate, &e.TerminationReason,
+			&e.FinalPay, &e.DeletedAt, &e.CreatedAt, &e.UpdatedAt); err != nil {
 			return nil, err
 		}
 		results = append(results, e)
@@ -46,17 +72,150 @@ func (r *employeeRepository) List(ctx context.Context) ([]domain.Employee, error
 	return results, rows.Err()
 }
 
+// employeeSortKeys maps each sort field to its column and the SQL type a
+// cursor value is cast to.
+var employeeSortKeys = map[string]struct{ column, sqlType string }{
+	domain.EmployeeSortID:         {"e.id", "bigint"},
+	domain.EmployeeSortCode:       {"e.code", "text"},
+	domain.EmployeeSortFullName:   {"e.full_name", "text"},
+	domain.EmployeeSortHireDate:   {"e.hire_date", "date"},
+	domain.EmployeeSortBaseSalary: {"COALESCE(s.base_salary, 0)", "bigint"},
+}
+
+// employeeFilter is the WHERE condition of a Page query and its arguments,
+// numbered from $1.
+func employeeFilter(q domain.EmployeeQuery) (string, []any) {
+	where := []string{"e.deleted_at IS NULL"}
+	var args []any
+	add := func(cond string, v ...any) {
+		placeholders := make([]any, len(v))
+		for i := range v {
+			args = append(args, v[i])
+			placeholders[i] = len(args)
+		}
+		where = append(where, fmt.Sprintf(cond, placeholders...))
+	}
+	if q.Active != nil {
+		add(employeeActive+" = $%d", *q.Active)
+	}
+	if q.HiredFrom != nil {
+		add("e.hire_date >= $%d", *q.HiredFrom)
+	}
+	if q.HiredTo != nil {
+		add("e.hire_date <= $%d", *q.HiredTo)
+	}
+	if q.MinSalary != nil {
+		add("COALESCE(s.base_salary, 0) >= $%d", *q.MinSalary)
+	}
+	if q.MaxSalary != nil {
+		add("COALESCE(s.base_salary, 0) <= $%d", *q.MaxSalary)
+	}
+	if q.Search != "" {
+		pattern := "%" + likeEscaper.Replace(q.Search) + "%"
+		add("(e.full_name ILIKE $%[1]d OR e.code ILIKE $%[1]d OR e.email ILIKE $%[1]d)", pattern)
+	}
+	if q.Department != "" {
+		add("e.department = $%d", q.Department)
+	}
+	return strings.Join(where, " AND "), args
+}
+
+func (r *employeeRepository) Page(ctx context.Context, q domain.EmployeeQuery) (domain.EmployeePage, error) {
+	key, ok := employeeSortKeys[q.SortBy]
+	if !ok {
+		return domain.EmployeePage{}, fmt.Errorf("%w: unknown sort %q", util.ErrInvalid, q.SortBy)
+	}
+
+	where, args := employeeFilter(q)
+	from := ` FROM employees e` + currentSalaryJoin + ` WHERE ` + where
+
+	var page domain.EmployeePage
+	if err := r.db.QueryRowContext(ctx, `SELECT COUNT(*)`+from, args...).Scan(&page.Total); err != nil {
+		return domain.EmployeePage{}, err
+	}
+
+	dir, cmp := "ASC", ">"
+	if q.SortDesc {
+		dir, cmp = "DESC", "<"
+	}
+	if q.After != nil {
+		args = append(args, q.After.Value, q.After.ID)
+		from += fmt.Sprintf(" AND (%s, e.id) %s ($%d::%s, $%d)", key.column, cmp, len(args)-1, key.sqlType, len(args))
+	}
+	// One extra row tells whether there is a next page.
+	args = append(args, q.Limit+1)
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT e.id, e.code, e.full_name, e.email, e.department, e.religion, COALESCE(s.base_salary, 0), COALESCE(s.allowance, 0),
+		       e.ptkp_status, e.npwp, `+employeeActive+`, e.hire_date, e.termination_date, e.termination_reason,
+		       e.final_pay, e.deleted_at, e.created_at, e.updated_at`+from+
+		fmt.Sprintf(" ORDER BY %s %s, e.id %s LIMIT $%d", key.column, dir, dir, len(args)), args...)
+	if err != nil {
+		return domain.EmployeePage{}, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var e domain.Employee
+		if err := rows.Scan(
+			&e.ID, &e.Code, &e.FullName, &e.Email, &e.Department, &e.Religion,
+			&e.BaseSalary, &e.Allowance, &e.PTKPStatus, &e.NPWP,
+			&e.IsActive, &e.HireDate, &e.TerminationDate, &e.TerminationReason,
+			&e.FinalPay, &e.DeletedAt, &e.CreatedAt, &e.UpdatedAt); err != nil {
+			return domain.EmployeePage{}, err
+		}
+		page.Employees = append(page.Employees, e)
+	}
+	if err := rows.Err(); err != nil {
+		return domain.EmployeePage{}, err
+	}
+
+	if len(page.Employees) > q.Limit {
+		page.Employees = page.Employees[:q.Limit]
+		last := page.Employees[q.Limit-1]
+		page.Next = &domain.EmployeeCursor{Sort: sortToken(q), Value: employeeSortValue(q.SortBy, last), ID: last.ID}
+	}
+	return page, nil
+}
+
+// sortToken names the sort of q as the sort query parameter does.
+func sortToken(q domain.EmployeeQuery) string {
+	if q.SortDesc {
+		return "-" + q.SortBy
+	}
+	return q.SortBy
+}
+
+// likeEscaper escapes the LIKE wildcards in a search term.
+var likeEscaper = strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`)
+
+// employeeSortValue is e's sort key in the text form a cursor carries.
+func employeeSortValue(sortBy string, e domain.Employee) string {
+	switch sortBy {
+	case domain.EmployeeSortCode:
+		return e.Code
+	case domain.EmployeeSortFullName:
+		return e.FullName
+	case domain.EmployeeSortHireDate:
+		return e.HireDate.Format("2006-01-02")
+	case domain.EmployeeSortBaseSalary:
+		return strconv.FormatInt(e.BaseSalary, 10)
+	default:
+		return strconv.FormatInt(e.ID, 10)
+	}
+}
+
 func (r *employeeRepository) Create(ctx context.Context, e domain.Employee) (domain.Employee, error) {
 	now := time.Now()
 	e.CreatedAt = now
 	e.UpdatedAt = now
 	e.IsActive = true
+	e.FinalPay = true
 
 	err := r.db.QueryRowContext(ctx, `
-		INSERT INTO employees(code, full_name, email, base_salary, allowance, is_active, hire_date, created_at, updated_at)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		INSERT INTO employees(code, full_name, email, department, religion, ptkp_status, npwp, is_active, hire_date, termination_date, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
 		RETURNING id`,
-		e.Code, e.FullName, e.Email, e.BaseSalary, e.Allowance, e.IsActive, e.HireDate, e.CreatedAt, e.UpdatedAt,
+		e.Code, e.FullName, e.Email, e.Department, e.Religion, e.PTKPStatus, e.NPWP, e.IsActive, e.HireDate, e.TerminationDate, e.CreatedAt, e.UpdatedAt,
 	).Scan(&e.ID)
 	if err != nil {
 		return domain.Employee{}, err
@@ -67,13 +226,16 @@ func (r *employeeRepository) Create(ctx context.Context, e domain.Employee) (dom
 func (r employeeRepository) GetByID(ctx context.Context, id int64) (domain.Employee, error) {
 	var e domain.Employee
 	err := r.db.QueryRowContext(ctx, `
-		SELECT id, code, full_name, email, base_salary, allowance, is_active, hire_date, created_at, updated_at
-		FROM employees
-		WHERE id = $1`, id,
+		SELECT e.id, e.code, e.full_name, e.email, e.department, e.religion, COALESCE(s.base_salary, 0), COALESCE(s.allowance, 0),
+		       e.ptkp_status, e.npwp, `+employeeActive+`, e.hire_date, e.termination_date, e.termination_reason,
+		       e.final_pay, e.deleted_at, e.created_at, e.updated_at
+		FROM employees e`+currentSalaryJoin+`
+		WHERE e.id = $1`, id,
 	).Scan(
-		&e.ID, &e.Code, &e.FullName, &e.Email,
-		&e.BaseSalary, &e.Allowance, &e.IsActive,
-		&e.HireDate, &e.CreatedAt, &e.UpdatedAt,
+		&e.ID, &e.Code, &e.FullName, &e.Email, &e.Department, &e.Religion,
+		&e.BaseSalary, &e.Allowance, &e.PTKPStatus, &e.NPWP,
+		&e.IsActive, &e.HireDate, &e.TerminationDate, &e.TerminationReason,
+		&e.FinalPay, &e.DeletedAt, &e.CreatedAt, &e.UpdatedAt,
 	)
 
 	if errors.Is(err, sql.ErrNoRows) {
@@ -92,10 +254,11 @@ func (r employeeRepository) Update(ctx context.Context, e domain.Employee) (doma
 
 	res, err := r.db.ExecContext(ctx, `
 		UPDATE employees
-		SET full_name=$1, email=$2, base_salary=$3, allowance=$4, is_active=$5, hire_date=$6, updated_at=$7
-		WHERE id = $8`,
-		e.FullName, e.Email, e.BaseSalary, e.Allowance, e.IsActive,
-		e.HireDate, e.UpdatedAt, e.ID,
+		SET full_name=$1, email=$2, ptkp_status=$3, npwp=$4, hire_date=$5,
+		    termination_date=$6, termination_reason=$7, final_pay=$8, department=$9, religion=$10, updated_at=$11
+		WHERE id = $12 AND deleted_at IS NULL`,
+		e.FullName, e.Email, e.PTKPStatus, e.NPWP,
+		e.HireDate, e.TerminationDate, e.TerminationReason, e.FinalPay, e.Department, e.Religion, e.UpdatedAt, e.ID,
 	)
 
 	if err != nil {
@@ -111,7 +274,11 @@ func (r employeeRepository) Update(ctx context.Context, e domain.Employee) (doma
 }
 
 func (r employeeRepository) Delete(ctx context.Context, id int64) error {
-	res, err := r.db.ExecContext(ctx, `DELETE FROM employees WHERE id = $1`, id)
+	now := time.Now()
+	res, err := r.db.ExecContext(ctx, `
+		UPDATE employees
+		SET deleted_at=$1, is_active=FALSE, updated_at=$1
+		WHERE id = $2 AND deleted_at IS NULL`, now, id)
 	if err != nil {
 		return err
 	}
@@ -124,6 +291,10 @@ func (r employeeRepository) Delete(ctx context.Context, id int64) error {
 	return nil
 }
 
+func (r employeeRepository) WithTx(tx *sql.Tx) EmployeeRepository {
+	return &employeeRepository{db: tx}
+}
+
 func NewEmployeeRepository(db *sql.DB) EmployeeRepository {
 	return &employeeRepository{db: db}
 }
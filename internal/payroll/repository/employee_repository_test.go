@@ -0,0 +1,52 @@
+package repository
+
+import (
+	"go-payroll-service/internal/payroll/model/domain"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestEmployeeFilter(t *testing.T) {
+	yes := true
+	hired := time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC)
+	minSalary := int64(5000000)
+	tests := []struct {
+		name      string
+		q         domain.EmployeeQuery
+		wantWhere string
+		wantArgs  []any
+	}{
+		{"no filters", domain.EmployeeQuery{}, "e.deleted_at IS NULL", nil},
+		{
+			// Terminated employees drop out the day after their last
+			// working day, whatever the stored flag says.
+			"active",
+			domain.EmployeeQuery{Active: &yes},
+			"e.deleted_at IS NULL AND (e.is_active AND (e.termination_date IS NULL OR e.termination_date >= CURRENT_DATE)) = $1",
+			[]any{true},
+		},
+		{
+			"placeholders numbered in order",
+			domain.EmployeeQuery{Active: &yes, HiredFrom: &hired, MinSalary: &minSalary, Search: "50%_off", Department: "Finance"},
+			"e.deleted_at IS NULL" +
+				" AND (e.is_active AND (e.termination_date IS NULL OR e.termination_date >= CURRENT_DATE)) = $1" +
+				" AND e.hire_date >= $2" +
+				" AND COALESCE(s.base_salary, 0) >= $3" +
+				" AND (e.full_name ILIKE $4 OR e.code ILIKE $4 OR e.email ILIKE $4)" +
+				" AND e.department = $5",
+			[]any{true, hired, minSalary, `%50\%\_off%`, "Finance"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			where, args := employeeFilter(tt.q)
+			if where != tt.wantWhere {
+				t.Errorf("employeeFilter() where =\n%s\nwant\n%s", where, tt.wantWhere)
+			}
+			if !reflect.DeepEqual(args, tt.wantArgs) {
+				t.Errorf("employeeFilter() args = %v, want %v", args, tt.wantArgs)
+			}
+		})
+	}
+}
@@ -0,0 +1,100 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"go-payroll-service/internal/payroll/model/domain"
+	"go-payroll-service/internal/payroll/util"
+	"time"
+)
+
+type GLMappingRepository interface {
+	List(ctx context.Context) ([]domain.GLMapping, error)
+	GetByCode(ctx context.Context, code string) (domain.GLMapping, error)
+	Upsert(ctx context.Context, m domain.GLMapping) (domain.GLMapping, error)
+	Delete(ctx context.Context, code string) error
+	WithTx(tx *sql.Tx) GLMappingRepository
+}
+
+type glMappingRepository struct {
+	db DBTX
+}
+
+func (r glMappingRepository) List(ctx context.Context) ([]domain.GLMapping, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT code, description, expense_account, payable_account, cost_center, updated_at
+		FROM gl_mappings
+		ORDER BY code`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var result []domain.GLMapping
+	for rows.Next() {
+		var m domain.GLMapping
+		if err := rows.Scan(&m.Code, &m.Description, &m.ExpenseAccount, &m.PayableAccount, &m.CostCenter, &m.UpdatedAt); err != nil {
+			return nil, err
+		}
+		result = append(result, m)
+	}
+	return result, rows.Err()
+}
+
+func (r glMappingRepository) GetByCode(ctx context.Context, code string) (domain.GLMapping, error) {
+	var m domain.GLMapping
+	err := r.db.QueryRowContext(ctx, `
+		SELECT code, description, expense_account, payable_account, cost_center, updated_at
+		FROM gl_mappings
+		WHERE code = $1`, code,
+	).Scan(&m.Code, &m.Description, &m.ExpenseAccount, &m.PayableAccount, &m.CostCenter, &m.UpdatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return domain.GLMapping{}, util.ErrNotFound
+	}
+	if err != nil {
+		return domain.GLMapping{}, err
+	}
+	return m, nil
+}
+
+func (r glMappingRepository) Upsert(ctx context.Context, m domain.GLMapping) (domain.GLMapping, error) {
+	m.UpdatedAt = time.Now()
+
+	_, err := r.db.ExecContext(ctx, `
+		INSERT INTO gl_mappings(code, description, expense_account, payable_account, cost_center, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		ON CONFLICT (code)
+		DO UPDATE SET description = EXCLUDED.description,
+		              expense_account = EXCLUDED.expense_account,
+		              payable_account = EXCLUDED.payable_account,
+		              cost_center = EXCLUDED.cost_center,
+		              updated_at = EXCLUDED.updated_at`,
+		m.Code, m.Description, m.ExpenseAccount, m.PayableAccount, m.CostCenter, m.UpdatedAt,
+	)
+	if err != nil {
+		return domain.GLMapping{}, err
+	}
+	return m, nil
+}
+
+func (r glMappingRepository) Delete(ctx context.Context, code string) error {
+	res, err := r.db.ExecContext(ctx, `DELETE FROM gl_mappings WHERE code = $1`, code)
+	if err != nil {
+		return err
+	}
+
+	aff, err := res.RowsAffected()
+	if err == nil && aff == 0 {
+		return util.ErrNotFound
+	}
+	return nil
+}
+
+func (r glMappingRepository) WithTx(tx *sql.Tx) GLMappingRepository {
+	return &glMappingRepository{db: tx}
+}
+
+func NewGLMappingRepository(db *sql.DB) GLMappingRepository {
+	return &glMappingRepository{db: db}
+}
@@ -0,0 +1,280 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"go-payroll-service/internal/payroll/model/domain"
+	"go-payroll-service/internal/payroll/util"
+	"strings"
+	"time"
+)
+
+type LeaveRepository interface {
+	ListTypes(ctx context.Context) ([]domain.LeaveType, error)
+	GetType(ctx context.Context, id int64) (domain.LeaveType, error)
+	GetTypeByCode(ctx context.Context, code string) (domain.LeaveType, error)
+	CreateType(ctx context.Context, t domain.LeaveType) (domain.LeaveType, error)
+	UpdateType(ctx context.Context, t domain.LeaveType) (domain.LeaveType, error)
+
+	// ListRequests returns the matching requests, latest first.
+	ListRequests(ctx context.Context, f domain.LeaveRequestFilter) ([]domain.LeaveRequest, error)
+	// ListApprovedUnpaid returns, per employee, the approved requests of
+	// unpaid types covering any day from from to to.
+	ListApprovedUnpaid(ctx context.Context, from, to time.Time) (map[int64][]domain.LeaveRequest, error)
+	GetRequest(ctx context.Context, id int64) (domain.LeaveRequest, error)
+	CreateRequest(ctx context.Context, r domain.LeaveRequest) (domain.LeaveRequest, error)
+	// UpdateRequestStatus saves the status and decision of r.
+	UpdateRequestStatus(ctx context.Context, r domain.LeaveRequest) (domain.LeaveRequest, error)
+	// LockEmployee serializes changes to an employee's requests until the
+	// transaction ends, so two of them cannot spend the same balance.
+	LockEmployee(ctx context.Context, employeeID int64) error
+	WithTx(tx *sql.Tx) LeaveRepository
+}
+
+type leaveRepository struct {
+	db DBTX
+}
+
+const leaveTypeColumns = `id, code, name, paid, annual_days, eligible_after_months, carry_over_days,
+		       carry_over_expiry_months, is_active, created_at, updated_at`
+
+func (r leaveRepository) ListTypes(ctx context.Context) ([]domain.LeaveType, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT `+leaveTypeColumns+`
+		FROM leave_types
+		ORDER BY code`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var result []domain.LeaveType
+	for rows.Next() {
+		var t domain.LeaveType
+		if err := rows.Scan(leaveTypeFields(&t)...); err != nil {
+			return nil, err
+		}
+		result = append(result, t)
+	}
+	return result, rows.Err()
+}
+
+func (r leaveRepository) GetType(ctx context.Context, id int64) (domain.LeaveType, error) {
+	return r.getType(ctx, `id = $1`, id)
+}
+
+func (r leaveRepository) GetTypeByCode(ctx context.Context, code string) (domain.LeaveType, error) {
+	return r.getType(ctx, `code = $1`, code)
+}
+
+func (r leaveRepository) getType(ctx context.Context, where string, arg any) (domain.LeaveType, error) {
+	var t domain.LeaveType
+	err := r.db.QueryRowContext(ctx, `
+		SELECT `+leaveTypeColumns+`
+		FROM leave_types
+		WHERE `+where, arg,
+	).Scan(leaveTypeFields(&t)...)
+	if errors.Is(err, sql.ErrNoRows) {
+		return domain.LeaveType{}, util.ErrNotFound
+	}
+	if err != nil {
+		return domain.LeaveType{}, err
+	}
+	return t, nil
+}
+
+func (r leaveRepository) CreateType(ctx context.Context, t domain.LeaveType) (domain.LeaveType, error) {
+	now := time.Now()
+	t.CreatedAt = now
+	t.UpdatedAt = now
+	t.IsActive = true
+
+	err := r.db.QueryRowContext(ctx, `
+		INSERT INTO leave_types(code, name, paid, annual_days, eligible_after_months, carry_over_days,
+		                        carry_over_expiry_months, is_active, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+		RETURNING id`,
+		t.Code, t.Name, t.Paid, t.AnnualDays, t.EligibleAfterMonths, t.CarryOverDays,
+		t.CarryOverExpiryMonths, t.IsActive, t.CreatedAt, t.UpdatedAt,
+	).Scan(&t.ID)
+	if err != nil {
+		return domain.LeaveType{}, err
+	}
+	return t, nil
+}
+
+func (r leaveRepository) UpdateType(ctx context.Context, t domain.LeaveType) (domain.LeaveType, error) {
+	t.UpdatedAt = time.Now()
+
+	res, err := r.db.ExecContext(ctx, `
+		UPDATE leave_types
+		SET name=$1, paid=$2, annual_days=$3, eligible_after_months=$4, carry_over_days=$5,
+		    carry_over_expiry_months=$6, is_active=$7, updated_at=$8
+		WHERE id = $9`,
+		t.Name, t.Paid, t.AnnualDays, t.EligibleAfterMonths, t.CarryOverDays,
+		t.CarryOverExpiryMonths, t.IsActive, t.UpdatedAt, t.ID,
+	)
+	if err != nil {
+		return domain.LeaveType{}, err
+	}
+
+	aff, err := res.RowsAffected()
+	if err == nil && aff == 0 {
+		return domain.LeaveType{}, util.ErrNotFound
+	}
+	return t, nil
+}
+
+func leaveTypeFields(t *domain.LeaveType) []any {
+	return []any{
+		&t.ID, &t.Code, &t.Name, &t.Paid, &t.AnnualDays, &t.EligibleAfterMonths, &t.CarryOverDays,
+		&t.CarryOverExpiryMonths, &t.IsActive, &t.CreatedAt, &t.UpdatedAt,
+	}
+}
+
+const leaveRequestSelect = `
+		SELECT r.id, r.employee_id, r.leave_type_id, r.start_date, r.end_date, r.days, r.reason, r.status,
+		       r.requested_by, r.decided_by, r.decision_note, r.decided_at, r.created_at, r.updated_at,
+		       t.id, t.code, t.name, t.paid, t.annual_days, t.eligible_after_months, t.carry_over_days,
+		       t.carry_over_expiry_months, t.is_active, t.created_at, t.updated_at
+		FROM leave_requests r
+		JOIN leave_types t ON t.id = r.leave_type_id`
+
+func (r leaveRepository) ListRequests(ctx context.Context, f domain.LeaveRequestFilter) ([]domain.LeaveRequest, error) {
+	var (
+		conds []string
+		args  []any
+	)
+	arg := func(v any) string {
+		args = append(args, v)
+		return fmt.Sprintf("$%d", len(args))
+	}
+	if f.EmployeeID != 0 {
+		conds = append(conds, "r.employee_id = "+arg(f.EmployeeID))
+	}
+	if f.Status != "" {
+		conds = append(conds, "r.status = "+arg(f.Status))
+	}
+	if f.From != nil {
+		conds = append(conds, "r.end_date >= "+arg(*f.From))
+	}
+	if f.To != nil {
+		conds = append(conds, "r.start_date <= "+arg(*f.To))
+	}
+	where := ""
+	if len(conds) > 0 {
+		where = "\n\t\tWHERE " + strings.Join(conds, " AND ")
+	}
+	return r.listRequests(ctx, where+"\n\t\tORDER BY r.start_date DESC, r.id DESC", args...)
+}
+
+func (r leaveRepository) ListApprovedUnpaid(ctx context.Context, from, to time.Time) (map[int64][]domain.LeaveRequest, error) {
+	list, err := r.listRequests(ctx, `
+		WHERE r.status = 'approved' AND NOT t.paid AND r.end_date >= $1 AND r.start_date <= $2
+		ORDER BY r.employee_id, r.start_date`, from, to)
+	if err != nil {
+		return nil, err
+	}
+	result := map[int64][]domain.LeaveRequest{}
+	for _, lr := range list {
+		result[lr.EmployeeID] = append(result[lr.EmployeeID], lr)
+	}
+	return result, nil
+}
+
+func (r leaveRepository) listRequests(ctx context.Context, tail string, args ...any) ([]domain.LeaveRequest, error) {
+	rows, err := r.db.QueryContext(ctx, leaveRequestSelect+tail, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var result []domain.LeaveRequest
+	for rows.Next() {
+		var lr domain.LeaveRequest
+		if err := rows.Scan(leaveRequestFields(&lr)...); err != nil {
+			return nil, err
+		}
+		result = append(result, lr)
+	}
+	return result, rows.Err()
+}
+
+func (r leaveRepository) GetRequest(ctx context.Context, id int64) (domain.LeaveRequest, error) {
+	var lr domain.LeaveRequest
+	err := r.db.QueryRowContext(ctx, leaveRequestSelect+`
+		WHERE r.id = $1`, id,
+	).Scan(leaveRequestFields(&lr)...)
+	if errors.Is(err, sql.ErrNoRows) {
+		return domain.LeaveRequest{}, util.ErrNotFound
+	}
+	if err != nil {
+		return domain.LeaveRequest{}, err
+	}
+	return lr, nil
+}
+
+func (r leaveRepository) CreateRequest(ctx context.Context, lr domain.LeaveRequest) (domain.LeaveRequest, error) {
+	now := time.Now()
+	lr.CreatedAt = now
+	lr.UpdatedAt = now
+
+	err := r.db.QueryRowContext(ctx, `
+		INSERT INTO leave_requests(employee_id, leave_type_id, start_date, end_date, days, reason, status,
+		                           requested_by, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+		RETURNING id`,
+		lr.EmployeeID, lr.LeaveTypeID, lr.StartDate, lr.EndDate, lr.Days, lr.Reason, lr.Status,
+		lr.RequestedBy, lr.CreatedAt, lr.UpdatedAt,
+	).Scan(&lr.ID)
+	if err != nil {
+		return domain.LeaveRequest{}, err
+	}
+	return lr, nil
+}
+
+func (r leaveRepository) UpdateRequestStatus(ctx context.Context, lr domain.LeaveRequest) (domain.LeaveRequest, error) {
+	lr.UpdatedAt = time.Now()
+
+	res, err := r.db.ExecContext(ctx, `
+		UPDATE leave_requests
+		SET status=$1, decided_by=$2, decision_note=$3, decided_at=$4, updated_at=$5
+		WHERE id = $6`,
+		lr.Status, lr.DecidedBy, lr.DecisionNote, lr.DecidedAt, lr.UpdatedAt, lr.ID,
+	)
+	if err != nil {
+		return domain.LeaveRequest{}, err
+	}
+
+	aff, err := res.RowsAffected()
+	if err == nil && aff == 0 {
+		return domain.LeaveRequest{}, util.ErrNotFound
+	}
+	return lr, nil
+}
+
+func (r leaveRepository) LockEmployee(ctx context.Context, employeeID int64) error {
+	var id int64
+	err := r.db.QueryRowContext(ctx, `SELECT id FROM employees WHERE id = $1 FOR UPDATE`, employeeID).Scan(&id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return util.ErrNotFound
+	}
+	return err
+}
+
+func leaveRequestFields(lr *domain.LeaveRequest) []any {
+	return append([]any{
+		&lr.ID, &lr.EmployeeID, &lr.LeaveTypeID, &lr.StartDate, &lr.EndDate, &lr.Days, &lr.Reason, &lr.Status,
+		&lr.RequestedBy, &lr.DecidedBy, &lr.DecisionNote, &lr.DecidedAt, &lr.CreatedAt, &lr.UpdatedAt,
+	}, leaveTypeFields(&lr.LeaveType)...)
+}
+
+func (r leaveRepository) WithTx(tx *sql.Tx) LeaveRepository {
+	return &leaveRepository{db: tx}
+}
+
+func NewLeaveRepository(db *sql.DB) LeaveRepository {
+	return &leaveRepository{db: db}
+}
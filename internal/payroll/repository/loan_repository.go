@@ -0,0 +1,201 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"go-payroll-service/internal/payroll/model/domain"
+	"go-payroll-service/internal/payroll/util"
+	"time"
+)
+
+type LoanRepository interface {
+	// ListByEmployee returns the employee's loans, oldest first, with what
+	// payslips repaid so far.
+	ListByEmployee(ctx context.Context, employeeID int64) ([]domain.Loan, error)
+	// GetByID returns the loan with what payslips repaid so far.
+	GetByID(ctx context.Context, id int64) (domain.Loan, error)
+	// ListDue returns, by employee, the loans that deduct installments in the
+	// period with what payslips of earlier periods repaid.
+	ListDue(ctx context.Context, period domain.PayrollPeriod) (map[int64][]domain.Loan, error)
+	// ListOutstanding returns the employee's loans not paid off before the
+	// period, with what payslips repaid besides the period's runType payslip.
+	ListOutstanding(ctx context.Context, employeeID int64, period domain.PayrollPeriod, runType string) ([]domain.Loan, error)
+	// ListRepayments returns the installments payslips deducted for the
+	// loan, by period.
+	ListRepayments(ctx context.Context, loanID int64) ([]domain.LoanRepayment, error)
+	Create(ctx context.Context, l domain.Loan) (domain.Loan, error)
+	SetPayoff(ctx context.Context, id int64, periodCode string) error
+	WithTx(tx *sql.Tx) LoanRepository
+}
+
+type loanRepository struct {
+	db DBTX
+}
+
+// selectLoans reads loans with Repaid summed over the payslips of periods
+// accepted by periodCond, a condition on pp, the payslip's period, and op,
+// the loan's payoff period, if any.
+func selectLoans(periodCond string) string {
+	return `
+		SELECT l.id, l.employee_id, l.kind, l.principal, l.interest_rate_bp, l.installments,
+		       l.start_period_code, l.payoff_period_code, l.note, l.created_at, l.updated_at,
+		       COALESCE((SELECT SUM(pl.amount)
+		                 FROM payslip_lines pl
+		                 JOIN payslips ps ON ps.id = pl.payslip_id
+		                 JOIN payroll_periods pp ON pp.id = ps.payroll_period_id
+		                 WHERE pl.loan_id = l.id
+		                   AND ` + periodCond + `), 0)
+		FROM loans l
+		JOIN payroll_periods sp ON sp.code = l.start_period_code
+		LEFT JOIN payroll_periods op ON op.code = l.payoff_period_code`
+}
+
+// repaidUntilPayoff counts the payslips before the payoff period, which
+// settled everything from it on.
+const repaidUntilPayoff = `(op.id IS NULL OR pp.start_date < op.start_date)`
+
+func (r loanRepository) ListByEmployee(ctx context.Context, employeeID int64) ([]domain.Loan, error) {
+	rows, err := r.db.QueryContext(ctx, selectLoans(repaidUntilPayoff)+`
+		WHERE l.employee_id = $1
+		ORDER BY l.id`, employeeID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var result []domain.Loan
+	for rows.Next() {
+		l, err := scanLoan(rows)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, l)
+	}
+	return result, rows.Err()
+}
+
+func (r loanRepository) GetByID(ctx context.Context, id int64) (domain.Loan, error) {
+	var l domain.Loan
+	err := r.db.QueryRowContext(ctx, selectLoans(repaidUntilPayoff)+`
+		WHERE l.id = $1`, id,
+	).Scan(&l.ID, &l.EmployeeID, &l.Kind, &l.Principal, &l.InterestRateBP, &l.Installments,
+		&l.StartPeriodCode, &l.PayoffPeriodCode, &l.Note, &l.CreatedAt, &l.UpdatedAt, &l.Repaid)
+	if errors.Is(err, sql.ErrNoRows) {
+		return domain.Loan{}, util.ErrNotFound
+	}
+	if err != nil {
+		return domain.Loan{}, err
+	}
+	return l, nil
+}
+
+func (r loanRepository) ListDue(ctx context.Context, period domain.PayrollPeriod) (map[int64][]domain.Loan, error) {
+	rows, err := r.db.QueryContext(ctx, selectLoans(`pp.start_date < $1`)+`
+		WHERE sp.start_date <= $1
+		  AND (op.id IS NULL OR op.start_date > $1)
+		ORDER BY l.employee_id, l.id`, period.StartDate)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	result := map[int64][]domain.Loan{}
+	for rows.Next() {
+		l, err := scanLoan(rows)
+		if err != nil {
+			return nil, err
+		}
+		result[l.EmployeeID] = append(result[l.EmployeeID], l)
+	}
+	return result, rows.Err()
+}
+
+func (r loanRepository) ListOutstanding(ctx context.Context, employeeID int64, period domain.PayrollPeriod, runType string) ([]domain.Loan, error) {
+	rows, err := r.db.QueryContext(ctx, selectLoans(repaidUntilPayoff+` AND NOT (ps.payroll_period_id = $2 AND ps.run_type = $3)`)+`
+		WHERE l.employee_id = $1
+		  AND (op.id IS NULL OR op.start_date > $4)
+		ORDER BY l.id`, employeeID, period.ID, runType, period.StartDate)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var result []domain.Loan
+	for rows.Next() {
+		l, err := scanLoan(rows)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, l)
+	}
+	return result, rows.Err()
+}
+
+func (r loanRepository) ListRepayments(ctx context.Context, loanID int64) ([]domain.LoanRepayment, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT ps.id, pp.code, ps.status, pl.amount
+		FROM payslip_lines pl
+		JOIN payslips ps ON ps.id = pl.payslip_id
+		JOIN payroll_periods pp ON pp.id = ps.payroll_period_id
+		WHERE pl.loan_id = $1
+		ORDER BY pp.start_date, ps.id`, loanID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var result []domain.LoanRepayment
+	for rows.Next() {
+		var rp domain.LoanRepayment
+		if err := rows.Scan(&rp.PayslipID, &rp.PeriodCode, &rp.Status, &rp.Amount); err != nil {
+			return nil, err
+		}
+		result = append(result, rp)
+	}
+	return result, rows.Err()
+}
+
+func (r loanRepository) Create(ctx context.Context, l domain.Loan) (domain.Loan, error) {
+	now := time.Now()
+	l.CreatedAt, l.UpdatedAt = now, now
+
+	err := r.db.QueryRowContext(ctx, `
+		INSERT INTO loans(employee_id, kind, principal, interest_rate_bp, installments, start_period_code, note, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $8)
+		RETURNING id`,
+		l.EmployeeID, l.Kind, l.Principal, l.InterestRateBP, l.Installments, l.StartPeriodCode, l.Note, now,
+	).Scan(&l.ID)
+	if err != nil {
+		return domain.Loan{}, err
+	}
+	return l, nil
+}
+
+func (r loanRepository) SetPayoff(ctx context.Context, id int64, periodCode string) error {
+	res, err := r.db.ExecContext(ctx, `
+		UPDATE loans SET payoff_period_code = $1, updated_at = $2 WHERE id = $3`, periodCode, time.Now(), id)
+	if err != nil {
+		return err
+	}
+	aff, err := res.RowsAffected()
+	if err == nil && aff == 0 {
+		return util.ErrNotFound
+	}
+	return nil
+}
+
+func scanLoan(rows *sql.Rows) (domain.Loan, error) {
+	var l domain.Loan
+	err := rows.Scan(&l.ID, &l.EmployeeID, &l.Kind, &l.Principal, &l.InterestRateBP, &l.Installments,
+		&l.StartPeriodCode, &l.PayoffPeriodCode, &l.Note, &l.CreatedAt, &l.UpdatedAt, &l.Repaid)
+	return l, err
+}
+
+func (r loanRepository) WithTx(tx *sql.Tx) LoanRepository {
+	return &loanRepository{db: tx}
+}
+
+func NewLoanRepository(db *sql.DB) LoanRepository {
+	return &loanRepository{db: db}
+}
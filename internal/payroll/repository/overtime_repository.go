@@ -0,0 +1,138 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"go-payroll-service/internal/payroll/model/domain"
+	"go-payroll-service/internal/payroll/util"
+	"time"
+)
+
+type OvertimeRepository interface {
+	// ListByEmployee returns the employee's entries, of one period if
+	// periodCode is set, by work date.
+	ListByEmployee(ctx context.Context, employeeID int64, periodCode string) ([]domain.OvertimeEntry, error)
+	ListByPeriodCode(ctx context.Context, periodCode string) (map[int64][]domain.OvertimeEntry, error)
+	GetByID(ctx context.Context, id int64) (domain.OvertimeEntry, error)
+	// Upsert records the entry, replacing the one of the same employee and
+	// work date.
+	Upsert(ctx context.Context, o domain.OvertimeEntry) (domain.OvertimeEntry, error)
+	Delete(ctx context.Context, id int64) error
+	WithTx(tx *sql.Tx) OvertimeRepository
+}
+
+type overtimeRepository struct {
+	db DBTX
+}
+
+const overtimeColumns = `id, employee_id, period_code, work_date, day_type, minutes, note, created_at, updated_at`
+
+func (r overtimeRepository) ListByEmployee(ctx context.Context, employeeID int64, periodCode string) ([]domain.OvertimeEntry, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT `+overtimeColumns+`
+		FROM overtime_entries
+		WHERE employee_id = $1 AND ($2 = '' OR period_code = $2)
+		ORDER BY work_date`, employeeID, periodCode)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var result []domain.OvertimeEntry
+	for rows.Next() {
+		o, err := scanOvertimeEntry(rows)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, o)
+	}
+	return result, rows.Err()
+}
+
+func (r overtimeRepository) ListByPeriodCode(ctx context.Context, periodCode string) (map[int64][]domain.OvertimeEntry, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT `+overtimeColumns+`
+		FROM overtime_entries
+		WHERE period_code = $1
+		ORDER BY employee_id, work_date`, periodCode)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	result := map[int64][]domain.OvertimeEntry{}
+	for rows.Next() {
+		o, err := scanOvertimeEntry(rows)
+		if err != nil {
+			return nil, err
+		}
+		result[o.EmployeeID] = append(result[o.EmployeeID], o)
+	}
+	return result, rows.Err()
+}
+
+func (r overtimeRepository) GetByID(ctx context.Context, id int64) (domain.OvertimeEntry, error) {
+	var o domain.OvertimeEntry
+	err := r.db.QueryRowContext(ctx, `
+		SELECT `+overtimeColumns+`
+		FROM overtime_entries
+		WHERE id = $1`, id,
+	).Scan(&o.ID, &o.EmployeeID, &o.PeriodCode, &o.WorkDate, &o.DayType, &o.Minutes, &o.Note, &o.CreatedAt, &o.UpdatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return domain.OvertimeEntry{}, util.ErrNotFound
+	}
+	if err != nil {
+		return domain.OvertimeEntry{}, err
+	}
+	return o, nil
+}
+
+func (r overtimeRepository) Upsert(ctx context.Context, o domain.OvertimeEntry) (domain.OvertimeEntry, error) {
+	now := time.Now()
+	o.UpdatedAt = now
+
+	err := r.db.QueryRowContext(ctx, `
+		INSERT INTO overtime_entries(employee_id, period_code, work_date, day_type, minutes, note, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $7)
+		ON CONFLICT (employee_id, work_date)
+		DO UPDATE SET period_code = EXCLUDED.period_code,
+		              day_type = EXCLUDED.day_type,
+		              minutes = EXCLUDED.minutes,
+		              note = EXCLUDED.note,
+		              updated_at = EXCLUDED.updated_at
+		RETURNING id, created_at`,
+		o.EmployeeID, o.PeriodCode, o.WorkDate, o.DayType, o.Minutes, o.Note, now,
+	).Scan(&o.ID, &o.CreatedAt)
+	if err != nil {
+		return domain.OvertimeEntry{}, err
+	}
+	return o, nil
+}
+
+func (r overtimeRepository) Delete(ctx context.Context, id int64) error {
+	res, err := r.db.ExecContext(ctx, `DELETE FROM overtime_entries WHERE id = $1`, id)
+	if err != nil {
+		return err
+	}
+
+	aff, err := res.RowsAffected()
+	if err == nil && aff == 0 {
+		return util.ErrNotFound
+	}
+	return nil
+}
+
+func scanOvertimeEntry(rows *sql.Rows) (domain.OvertimeEntry, error) {
+	var o domain.OvertimeEntry
+	err := rows.Scan(&o.ID, &o.EmployeeID, &o.PeriodCode, &o.WorkDate, &o.DayType, &o.Minutes, &o.Note, &o.CreatedAt, &o.UpdatedAt)
+	return o, err
+}
+
+func (r overtimeRepository) WithTx(tx *sql.Tx) OvertimeRepository {
+	return &overtimeRepository{db: tx}
+}
+
+func NewOvertimeRepository(db *sql.DB) OvertimeRepository {
+	return &overtimeRepository{db: db}
+}
@@ -3,85 +3,214 @@ package repository
 import (
 	"context"
 	"database/sql"
-	"errors"
 	"go-payroll-service/internal/payroll/model/domain"
 	"go-payroll-service/internal/payroll/util"
 	"time"
+
+	"github.com/lib/pq"
 )
 
 type PayrollRepository interface {
-	GetOrCreatePeriod(ctx context.Context, code string, start, end time.Time) (domain.PayrollPeriod, error)
 	CreatePayslip(ctx context.Context, p domain.Payslip) (domain.Payslip, error)
-	ListPayslipByPeriodCode(ctx context.Context, periodCode string) ([]domain.PayslipWithEmployee, error)
+	UpdatePayslip(ctx context.Context, p domain.Payslip) (domain.Payslip, error)
+	DeletePayslip(ctx context.Context, id int64) error
+	// ListPayslipByPeriodID lists the payslips of one run type of the period.
+	ListPayslipByPeriodID(ctx context.Context, periodID int64, runType string) ([]domain.Payslip, error)
+	// ListPayslipByPeriodCode lists the period's payslips of runType, or of
+	// every run type if it is empty.
+	ListPayslipByPeriodCode(ctx context.Context, periodCode, runType string) ([]domain.PayslipWithEmployee, error)
+	// ListPayslipByEmployee lists the employee's payslips, latest period first.
+	ListPayslipByEmployee(ctx context.Context, employeeID int64) ([]domain.PayslipWithEmployee, error)
+	GetTaxYTD(ctx context.Context, employeeID int64, period domain.PayrollPeriod) (domain.TaxYTD, error)
+	// GetPayslipYTD totals the employee's payslips of the tax year up to the
+	// period; of approved runs only if publishedOnly is set.
+	GetPayslipYTD(ctx context.Context, employeeID int64, period domain.PayrollPeriod, publishedOnly bool) (domain.PayslipYTD, error)
+	// GetTaxMonth totals the employee's payslips of the period from run
+	// types other than runType, which are taxed in the same month.
+	GetTaxMonth(ctx context.Context, employeeID, periodID int64, runType string) (domain.TaxYTD, error)
+	WithTx(tx *sql.Tx) PayrollRepository
 }
 
 type payrollRepository struct {
-	db *sql.DB
+	db DBTX
 }
 
-func (r payrollRepository) GetOrCreatePeriod(ctx context.Context, code string, start, end time.Time) (domain.PayrollPeriod, error) {
-	var p domain.PayrollPeriod
-	err := r.db.QueryRowContext(ctx, `
-		SELECT id, code, start_date, end_date, closed, created_at, updated_at
-		FROM payroll_periods
-		WHERE code = $1`, code,
-	).Scan(&p.ID, &p.Code, &p.StartDate, &p.EndDate, &p.Closed, &p.CreatedAt, &p.UpdatedAt)
-
-	if err == nil {
-		return p, nil
-	}
-	if !errors.Is(err, sql.ErrNoRows) {
-		return domain.PayrollPeriod{}, err
-	}
-
+func (r payrollRepository) CreatePayslip(ctx context.Context, p domain.Payslip) (domain.Payslip, error) {
 	now := time.Now()
-	p.Code = code
-	p.StartDate = start.Format("2006-01-02")
-	p.EndDate = end.Format("2006-01-02")
-	p.Closed = false
 	p.CreatedAt = now
 	p.UpdatedAt = now
 
-	err = r.db.QueryRowContext(ctx, `
-		INSERT INTO payroll_periods(code, start_date, end_date, closed, created_at, updated_at)
-		VALUES ($1, $2, $3, $4, $5, $6)
-		RETURNING id`,
-		p.Code, p.StartDate, p.EndDate, p.Closed, p.CreatedAt, p.UpdatedAt).Scan(&p.ID)
+	err := r.db.QueryRowContext(ctx, `
+			INSERT INTO payslips(employee_id, payroll_period_id, run_type, status, taxable_income, income_tax, tax_method,
+			                     proration_method, prorated_days, period_days, created_at, updated_at)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+			RETURNING id`,
+		p.EmployeeID, p.PayrollPeriodID, p.RunType, p.Status, p.TaxableIncome, p.IncomeTax, p.TaxMethod,
+		p.ProrationMethod, p.ProratedDays, p.PeriodDays, p.CreatedAt, p.UpdatedAt,
+	).Scan(&p.ID)
 	if err != nil {
-		return domain.PayrollPeriod{}, err
+		return domain.Payslip{}, err
+	}
+
+	if err := r.insertLines(ctx, &p); err != nil {
+		return domain.Payslip{}, err
 	}
 	return p, nil
 }
 
-func (r payrollRepository) CreatePayslip(ctx context.Context, p domain.Payslip) (domain.Payslip, error) {
-	err := r.db.QueryRowContext(ctx, `
-			INSERT INTO payslips(employee_id, payroll_period_id, base_salary, allowance, deduction, net_salary)
-			VALUES ($1, $2, $3, $4, $5, $6)
-			RETURNING id`,
-		p.EmployeeID, p.PayrollPeriodID, p.BaseSalary, p.Allowance, p.Deduction, p.NetSalary,
-	).Scan(&p.ID)
+// UpdatePayslip rewrites a payslip's figures and replaces all of its lines.
+func (r payrollRepository) UpdatePayslip(ctx context.Context, p domain.Payslip) (domain.Payslip, error) {
+	p.UpdatedAt = time.Now()
+
+	res, err := r.db.ExecContext(ctx, `
+		UPDATE payslips
+		SET status=$1, taxable_income=$2, income_tax=$3, tax_method=$4,
+		    proration_method=$5, prorated_days=$6, period_days=$7, updated_at=$8
+		WHERE id = $9`,
+		p.Status, p.TaxableIncome, p.IncomeTax, p.TaxMethod,
+		p.ProrationMethod, p.ProratedDays, p.PeriodDays, p.UpdatedAt, p.ID,
+	)
 	if err != nil {
 		return domain.Payslip{}, err
 	}
+	aff, err := res.RowsAffected()
+	if err == nil && aff == 0 {
+		return domain.Payslip{}, util.ErrNotFound
+	}
+
+	if _, err := r.db.ExecContext(ctx, `DELETE FROM payslip_lines WHERE payslip_id = $1`, p.ID); err != nil {
+		return domain.Payslip{}, err
+	}
+	if _, err := r.db.ExecContext(ctx, `DELETE FROM payslip_tax_lines WHERE payslip_id = $1`, p.ID); err != nil {
+		return domain.Payslip{}, err
+	}
+	if err := r.insertLines(ctx, &p); err != nil {
+		return domain.Payslip{}, err
+	}
 	return p, nil
 }
 
-func (r payrollRepository) ListPayslipByPeriodCode(ctx context.Context, periodCode string) ([]domain.PayslipWithEmployee, error) {
+func (r payrollRepository) DeletePayslip(ctx context.Context, id int64) error {
+	res, err := r.db.ExecContext(ctx, `DELETE FROM payslips WHERE id = $1`, id)
+	if err != nil {
+		return err
+	}
+
+	aff, err := res.RowsAffected()
+	if err == nil && aff == 0 {
+		return util.ErrNotFound
+	}
+	return nil
+}
+
+func (r payrollRepository) insertLines(ctx context.Context, p *domain.Payslip) error {
+	for i := range p.TaxLines {
+		l := &p.TaxLines[i]
+		l.PayslipID = p.ID
+		err := r.db.QueryRowContext(ctx, `
+			INSERT INTO payslip_tax_lines(payslip_id, line_no, code, label, amount)
+			VALUES ($1, $2, $3, $4, $5)
+			RETURNING id`,
+			l.PayslipID, l.LineNo, l.Code, l.Label, l.Amount,
+		).Scan(&l.ID)
+		if err != nil {
+			return err
+		}
+	}
+
+	for i := range p.Lines {
+		l := &p.Lines[i]
+		l.PayslipID = p.ID
+		err := r.db.QueryRowContext(ctx, `
+			INSERT INTO payslip_lines(payslip_id, code, label, type, amount, taxable, source, loan_id)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+			RETURNING id`,
+			l.PayslipID, l.Code, l.Label, l.Type, l.Amount, l.Taxable, l.Source, l.LoanID,
+		).Scan(&l.ID)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r payrollRepository) ListPayslipByPeriodID(ctx context.Context, periodID int64, runType string) ([]domain.Payslip, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT id, employee_id, payroll_period_id, run_type, status, taxable_income, income_tax, tax_method,
+		       proration_method, prorated_days, period_days, created_at, updated_at
+		FROM payslips
+		WHERE payroll_period_id = $1 AND run_type = $2
+		ORDER BY employee_id`, periodID, runType)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var result []domain.Payslip
+	for rows.Next() {
+		var p domain.Payslip
+		if err := rows.Scan(
+			&p.ID, &p.EmployeeID, &p.PayrollPeriodID, &p.RunType, &p.Status, &p.TaxableIncome, &p.IncomeTax,
+			&p.TaxMethod, &p.ProrationMethod, &p.ProratedDays, &p.PeriodDays, &p.CreatedAt, &p.UpdatedAt); err != nil {
+			return nil, err
+		}
+		result = append(result, p)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	ptrs := make([]*domain.Payslip, len(result))
+	for i := range result {
+		ptrs[i] = &result[i]
+	}
+	if err := r.attachLines(ctx, ptrs); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func (r payrollRepository) ListPayslipByPeriodCode(ctx context.Context, periodCode, runType string) ([]domain.PayslipWithEmployee, error) {
+	result, err := r.listPayslipsWithEmployee(ctx, `pp.code = $1 AND ($2 = '' OR ps.run_type = $2)`, `e.full_name, ps.run_type`, periodCode, runType)
+	if err != nil {
+		return nil, err
+	}
+	if len(result) == 0 {
+		return nil, util.ErrNotFound
+	}
+	return result, nil
+}
+
+func (r payrollRepository) ListPayslipByEmployee(ctx context.Context, employeeID int64) ([]domain.PayslipWithEmployee, error) {
+	return r.listPayslipsWithEmployee(ctx, `ps.employee_id = $1`, `pp.start_date DESC, ps.run_type`, employeeID)
+}
+
+// listPayslipsWithEmployee loads the payslips matching where, with their
+// lines and the employee and period they belong to.
+func (r payrollRepository) listPayslipsWithEmployee(ctx context.Context, where, orderBy string, args ...any) ([]domain.PayslipWithEmployee, error) {
 	rows, err := r.db.QueryContext(ctx, `
 		SELECT ps.id,
 		       ps.employee_id,
 		       ps.payroll_period_id,
-		       ps.base_salary,
-		       ps.allowance,
-		       ps.deduction,
-		       ps.net_salary,
+		       ps.run_type,
+		       ps.status,
+		       ps.taxable_income,
+		       ps.income_tax,
+		       ps.tax_method,
+		       ps.proration_method,
+		       ps.prorated_days,
+		       ps.period_days,
+		       ps.created_at,
+		       ps.updated_at,
 		       e.full_name as employee_name,
-		       pp.code as period_code
+		       pp.code as period_code,
+		       COALESCE(pr.status, '') as run_status
 		FROM payslips ps
 		JOIN employees e ON e.id = ps.employee_id
 		JOIN payroll_periods pp ON pp.id = ps.payroll_period_id
-		WHERE pp.code = $1
-		ORDER BY e.full_name`, periodCode)
+		LEFT JOIN payroll_runs pr ON pr.payroll_period_id = ps.payroll_period_id AND pr.run_type = ps.run_type
+		WHERE `+where+`
+		ORDER BY `+orderBy, args...)
 	if err != nil {
 		return nil, err
 	}
@@ -94,22 +223,172 @@ func (r payrollRepository) ListPayslipByPeriodCode(ctx context.Context, periodCo
 			&p.ID,
 			&p.EmployeeID,
 			&p.PayrollPeriodID,
-			&p.BaseSalary,
-			&p.Allowance,
-			&p.Deduction,
-			&p.NetSalary,
+			&p.RunType,
+			&p.Status,
+			&p.TaxableIncome,
+			&p.IncomeTax,
+			&p.TaxMethod,
+			&p.ProrationMethod,
+			&p.ProratedDays,
+			&p.PeriodDays,
+			&p.CreatedAt,
+			&p.UpdatedAt,
 			&p.EmployeeName,
 			&p.PeriodCode,
+			&p.RunStatus,
 		); err != nil {
 			return nil, err
 		}
 		result = append(result, p)
 	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
 
-	if len(result) == 0 {
-		return nil, util.ErrNotFound
+	ptrs := make([]*domain.Payslip, len(result))
+	for i := range result {
+		ptrs[i] = &result[i].Payslip
+	}
+	if err := r.attachLines(ctx, ptrs); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// attachLines loads the lines and tax lines of the given payslips.
+func (r payrollRepository) attachLines(ctx context.Context, payslips []*domain.Payslip) error {
+	if len(payslips) == 0 {
+		return nil
+	}
+	byID := map[int64]*domain.Payslip{}
+	ids := make([]int64, 0, len(payslips))
+	for _, p := range payslips {
+		byID[p.ID] = p
+		ids = append(ids, p.ID)
+	}
+
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT id, payslip_id, code, label, type, amount, taxable, source, loan_id
+		FROM payslip_lines
+		WHERE payslip_id = ANY($1)
+		ORDER BY payslip_id, id`, pq.Array(ids))
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var l domain.PayslipLine
+		if err := rows.Scan(&l.ID, &l.PayslipID, &l.Code, &l.Label, &l.Type, &l.Amount, &l.Taxable, &l.Source, &l.LoanID); err != nil {
+			return err
+		}
+		p := byID[l.PayslipID]
+		p.Lines = append(p.Lines, l)
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	taxRows, err := r.db.QueryContext(ctx, `
+		SELECT id, payslip_id, line_no, code, label, amount
+		FROM payslip_tax_lines
+		WHERE payslip_id = ANY($1)
+		ORDER BY payslip_id, line_no`, pq.Array(ids))
+	if err != nil {
+		return err
+	}
+	defer taxRows.Close()
+
+	for taxRows.Next() {
+		var l domain.PayslipTaxLine
+		if err := taxRows.Scan(&l.ID, &l.PayslipID, &l.LineNo, &l.Code, &l.Label, &l.Amount); err != nil {
+			return err
+		}
+		p := byID[l.PayslipID]
+		p.TaxLines = append(p.TaxLines, l)
+	}
+	return taxRows.Err()
+}
+
+func (r payrollRepository) GetTaxYTD(ctx context.Context, employeeID int64, period domain.PayrollPeriod) (domain.TaxYTD, error) {
+	var ytd domain.TaxYTD
+	err := r.db.QueryRowContext(ctx, `
+		SELECT COUNT(DISTINCT pp.id),
+		       COALESCE(SUM(ps.taxable_income), 0),
+		       COALESCE(SUM(ps.income_tax), 0),
+		       COALESCE(SUM((SELECT SUM(pl.amount)
+		                      FROM payslip_lines pl
+		                      WHERE pl.payslip_id = ps.id
+		                        AND pl.type = $4
+		                        AND pl.taxable
+		                        AND pl.source = $5)), 0)
+		FROM payslips ps
+		JOIN payroll_periods pp ON pp.id = ps.payroll_period_id
+		WHERE ps.employee_id = $1
+		  AND EXTRACT(YEAR FROM pp.end_date) = $2
+		  AND pp.end_date < $3`,
+		employeeID, period.EndDate.Year(), period.StartDate, domain.LineTypeDeduction, domain.LineSourceBPJS,
+	).Scan(&ytd.Months, &ytd.TaxableIncome, &ytd.IncomeTax, &ytd.PensionContribution)
+	if err != nil {
+		return domain.TaxYTD{}, err
 	}
-	return result, rows.Err()
+	return ytd, nil
+}
+
+func (r payrollRepository) GetPayslipYTD(ctx context.Context, employeeID int64, period domain.PayrollPeriod, publishedOnly bool) (domain.PayslipYTD, error) {
+	var ytd domain.PayslipYTD
+	err := r.db.QueryRowContext(ctx, `
+		SELECT COALESCE(SUM((SELECT SUM(pl.amount)
+		                      FROM payslip_lines pl
+		                      WHERE pl.payslip_id = ps.id AND pl.type = $4)), 0),
+		       COALESCE(SUM((SELECT SUM(pl.amount)
+		                      FROM payslip_lines pl
+		                      WHERE pl.payslip_id = ps.id AND pl.type = $5)), 0),
+		       COALESCE(SUM(ps.income_tax), 0)
+		FROM payslips ps
+		JOIN payroll_periods pp ON pp.id = ps.payroll_period_id
+		WHERE ps.employee_id = $1
+		  AND EXTRACT(YEAR FROM pp.end_date) = $2
+		  AND pp.end_date <= $3
+		  AND (NOT $6 OR EXISTS (SELECT 1
+		                         FROM payroll_runs pr
+		                         WHERE pr.payroll_period_id = ps.payroll_period_id
+		                           AND pr.run_type = ps.run_type
+		                           AND pr.status = $7))`,
+		employeeID, period.EndDate.Year(), period.EndDate, domain.LineTypeEarning, domain.LineTypeDeduction,
+		publishedOnly, domain.RunStatusApproved,
+	).Scan(&ytd.Earnings, &ytd.Deductions, &ytd.IncomeTax)
+	if err != nil {
+		return domain.PayslipYTD{}, err
+	}
+	return ytd, nil
+}
+
+func (r payrollRepository) GetTaxMonth(ctx context.Context, employeeID, periodID int64, runType string) (domain.TaxYTD, error) {
+	var month domain.TaxYTD
+	err := r.db.QueryRowContext(ctx, `
+		SELECT COALESCE(SUM(ps.taxable_income), 0),
+		       COALESCE(SUM(ps.income_tax), 0),
+		       COALESCE(SUM((SELECT SUM(pl.amount)
+		                      FROM payslip_lines pl
+		                      WHERE pl.payslip_id = ps.id
+		                        AND pl.type = $4
+		                        AND pl.taxable
+		                        AND pl.source = $5)), 0)
+		FROM payslips ps
+		WHERE ps.employee_id = $1
+		  AND ps.payroll_period_id = $2
+		  AND ps.run_type <> $3`,
+		employeeID, periodID, runType, domain.LineTypeDeduction, domain.LineSourceBPJS,
+	).Scan(&month.TaxableIncome, &month.IncomeTax, &month.PensionContribution)
+	if err != nil {
+		return domain.TaxYTD{}, err
+	}
+	return month, nil
+}
+
+func (r payrollRepository) WithTx(tx *sql.Tx) PayrollRepository {
+	return &payrollRepository{db: tx}
 }
 
 func NewPayrollRepository(db *sql.DB) PayrollRepository {
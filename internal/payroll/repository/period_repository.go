@@ -0,0 +1,188 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"go-payroll-service/internal/payroll/model/domain"
+	"go-payroll-service/internal/payroll/util"
+	"time"
+)
+
+type PeriodRepository interface {
+	List(ctx context.Context) ([]domain.PayrollPeriod, error)
+	Create(ctx context.Context, p domain.PayrollPeriod) (domain.PayrollPeriod, error)
+	GetByCode(ctx context.Context, code string) (domain.PayrollPeriod, error)
+	GetByCodeForUpdate(ctx context.Context, code string) (domain.PayrollPeriod, error)
+	// ExistsOverlapping locks period creation until the transaction ends and
+	// reports whether a period shares a day with start to end. It must run
+	// inside a transaction.
+	ExistsOverlapping(ctx context.Context, start, end time.Time) (bool, error)
+	UpdateStatus(ctx context.Context, p domain.PayrollPeriod) (domain.PayrollPeriod, error)
+	AddEvent(ctx context.Context, ev domain.PayrollPeriodEvent) (domain.PayrollPeriodEvent, error)
+	ListEvents(ctx context.Context, periodID int64) ([]domain.PayrollPeriodEvent, error)
+	WithTx(tx *sql.Tx) PeriodRepository
+}
+
+// periodCreateLockKey is the Postgres advisory lock serializing period
+// creation, so that two overlapping periods cannot both pass the check.
+const periodCreateLockKey = 7_346_109_217
+
+type periodRepository struct {
+	db DBTX
+}
+
+func (r periodRepository) List(ctx context.Context) ([]domain.PayrollPeriod, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT id, code, start_date, end_date, pay_date, status, created_at, updated_at
+		FROM payroll_periods
+		ORDER BY start_date DESC`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var results []domain.PayrollPeriod
+	for rows.Next() {
+		var p domain.PayrollPeriod
+		if err := rows.Scan(
+			&p.ID, &p.Code, &p.StartDate, &p.EndDate, &p.PayDate,
+			&p.Status, &p.CreatedAt, &p.UpdatedAt); err != nil {
+			return nil, err
+		}
+		results = append(results, p)
+	}
+	return results, rows.Err()
+}
+
+func (r periodRepository) Create(ctx context.Context, p domain.PayrollPeriod) (domain.PayrollPeriod, error) {
+	now := time.Now()
+	p.CreatedAt = now
+	p.UpdatedAt = now
+
+	err := r.db.QueryRowContext(ctx, `
+		INSERT INTO payroll_periods(code, start_date, end_date, pay_date, status, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING id`,
+		p.Code, p.StartDate, p.EndDate, p.PayDate, p.Status, p.CreatedAt, p.UpdatedAt,
+	).Scan(&p.ID)
+	if err != nil {
+		return domain.PayrollPeriod{}, err
+	}
+	return p, nil
+}
+
+func (r periodRepository) GetByCode(ctx context.Context, code string) (domain.PayrollPeriod, error) {
+	return r.get(ctx, `
+		SELECT id, code, start_date, end_date, pay_date, status, created_at, updated_at
+		FROM payroll_periods
+		WHERE code = $1`, code)
+}
+
+// GetByCodeForUpdate locks the period row until the surrounding transaction
+// ends, so runs and transitions on the same period are serialized.
+func (r periodRepository) GetByCodeForUpdate(ctx context.Context, code string) (domain.PayrollPeriod, error) {
+	return r.get(ctx, `
+		SELECT id, code, start_date, end_date, pay_date, status, created_at, updated_at
+		FROM payroll_periods
+		WHERE code = $1
+		FOR UPDATE`, code)
+}
+
+func (r periodRepository) get(ctx context.Context, query string, args ...any) (domain.PayrollPeriod, error) {
+	var p domain.PayrollPeriod
+	err := r.db.QueryRowContext(ctx, query, args...).Scan(
+		&p.ID, &p.Code, &p.StartDate, &p.EndDate, &p.PayDate,
+		&p.Status, &p.CreatedAt, &p.UpdatedAt,
+	)
+
+	if errors.Is(err, sql.ErrNoRows) {
+		return domain.PayrollPeriod{}, util.ErrNotFound
+	}
+	if err != nil {
+		return domain.PayrollPeriod{}, err
+	}
+	return p, nil
+}
+
+func (r periodRepository) ExistsOverlapping(ctx context.Context, start, end time.Time) (bool, error) {
+	if _, err := r.db.ExecContext(ctx, `SELECT pg_advisory_xact_lock($1)`, periodCreateLockKey); err != nil {
+		return false, fmt.Errorf("lock periods: %w", err)
+	}
+	var exists bool
+	err := r.db.QueryRowContext(ctx, `
+		SELECT EXISTS (
+			SELECT 1 FROM payroll_periods
+			WHERE start_date <= $2 AND end_date >= $1
+		)`, start, end,
+	).Scan(&exists)
+	return exists, err
+}
+
+func (r periodRepository) UpdateStatus(ctx context.Context, p domain.PayrollPeriod) (domain.PayrollPeriod, error) {
+	p.UpdatedAt = time.Now()
+
+	res, err := r.db.ExecContext(ctx, `
+		UPDATE payroll_periods
+		SET status=$1, updated_at=$2
+		WHERE id = $3`,
+		p.Status, p.UpdatedAt, p.ID,
+	)
+	if err != nil {
+		return domain.PayrollPeriod{}, err
+	}
+
+	aff, err := res.RowsAffected()
+	if err == nil && aff == 0 {
+		return domain.PayrollPeriod{}, util.ErrNotFound
+	}
+	return p, nil
+}
+
+func (r periodRepository) AddEvent(ctx context.Context, ev domain.PayrollPeriodEvent) (domain.PayrollPeriodEvent, error) {
+	ev.CreatedAt = time.Now()
+
+	err := r.db.QueryRowContext(ctx, `
+		INSERT INTO payroll_period_events(payroll_period_id, action, from_status, to_status, reason, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING id`,
+		ev.PayrollPeriodID, ev.Action, ev.FromStatus, ev.ToStatus, ev.Reason, ev.CreatedAt,
+	).Scan(&ev.ID)
+	if err != nil {
+		return domain.PayrollPeriodEvent{}, err
+	}
+	return ev, nil
+}
+
+func (r periodRepository) ListEvents(ctx context.Context, periodID int64) ([]domain.PayrollPeriodEvent, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT id, payroll_period_id, action, from_status, to_status, reason, created_at
+		FROM payroll_period_events
+		WHERE payroll_period_id = $1
+		ORDER BY id`, periodID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var results []domain.PayrollPeriodEvent
+	for rows.Next() {
+		var ev domain.PayrollPeriodEvent
+		if err := rows.Scan(
+			&ev.ID, &ev.PayrollPeriodID, &ev.Action, &ev.FromStatus,
+			&ev.ToStatus, &ev.Reason, &ev.CreatedAt); err != nil {
+			return nil, err
+		}
+		results = append(results, ev)
+	}
+	return results, rows.Err()
+}
+
+func (r periodRepository) WithTx(tx *sql.Tx) PeriodRepository {
+	return &periodRepository{db: tx}
+}
+
+func NewPeriodRepository(db *sql.DB) PeriodRepository {
+	return &periodRepository{db: db}
+}
@@ -0,0 +1,187 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"go-payroll-service/internal/payroll/model/domain"
+	"go-payroll-service/internal/payroll/util"
+	"time"
+)
+
+type RunRepository interface {
+	// ListByPeriodID lists the runs of the period, by run type.
+	ListByPeriodID(ctx context.Context, periodID int64) ([]domain.PayrollRun, error)
+	Get(ctx context.Context, periodID int64, runType string) (domain.PayrollRun, error)
+	// GetForUpdate locks the run row until the surrounding transaction ends.
+	GetForUpdate(ctx context.Context, periodID int64, runType string) (domain.PayrollRun, error)
+	Create(ctx context.Context, r domain.PayrollRun) (domain.PayrollRun, error)
+	Update(ctx context.Context, r domain.PayrollRun) (domain.PayrollRun, error)
+	Delete(ctx context.Context, id int64) error
+	AddEvent(ctx context.Context, ev domain.PayrollRunEvent) (domain.PayrollRunEvent, error)
+	ListEvents(ctx context.Context, runID int64) ([]domain.PayrollRunEvent, error)
+	WithTx(tx *sql.Tx) RunRepository
+}
+
+type runRepository struct {
+	db DBTX
+}
+
+const runColumns = `pr.id, pr.payroll_period_id, pp.code, pr.run_type, pr.status, pr.round, pr.total_net,
+		       pr.required_levels, pr.generated_by, pr.submitted_by, pr.created_at, pr.updated_at`
+
+func (r runRepository) ListByPeriodID(ctx context.Context, periodID int64) ([]domain.PayrollRun, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT `+runColumns+`
+		FROM payroll_runs pr
+		JOIN payroll_periods pp ON pp.id = pr.payroll_period_id
+		WHERE pr.payroll_period_id = $1
+		ORDER BY pr.run_type`, periodID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var result []domain.PayrollRun
+	for rows.Next() {
+		var run domain.PayrollRun
+		if err := rows.Scan(
+			&run.ID, &run.PayrollPeriodID, &run.PeriodCode, &run.RunType, &run.Status, &run.Round, &run.TotalNet,
+			&run.RequiredLevels, &run.GeneratedBy, &run.SubmittedBy, &run.CreatedAt, &run.UpdatedAt); err != nil {
+			return nil, err
+		}
+		result = append(result, run)
+	}
+	return result, rows.Err()
+}
+
+func (r runRepository) Get(ctx context.Context, periodID int64, runType string) (domain.PayrollRun, error) {
+	return r.get(ctx, `
+		SELECT `+runColumns+`
+		FROM payroll_runs pr
+		JOIN payroll_periods pp ON pp.id = pr.payroll_period_id
+		WHERE pr.payroll_period_id = $1 AND pr.run_type = $2`, periodID, runType)
+}
+
+func (r runRepository) GetForUpdate(ctx context.Context, periodID int64, runType string) (domain.PayrollRun, error) {
+	return r.get(ctx, `
+		SELECT `+runColumns+`
+		FROM payroll_runs pr
+		JOIN payroll_periods pp ON pp.id = pr.payroll_period_id
+		WHERE pr.payroll_period_id = $1 AND pr.run_type = $2
+		FOR UPDATE OF pr`, periodID, runType)
+}
+
+func (r runRepository) get(ctx context.Context, query string, args ...any) (domain.PayrollRun, error) {
+	var run domain.PayrollRun
+	err := r.db.QueryRowContext(ctx, query, args...).Scan(
+		&run.ID, &run.PayrollPeriodID, &run.PeriodCode, &run.RunType, &run.Status, &run.Round, &run.TotalNet,
+		&run.RequiredLevels, &run.GeneratedBy, &run.SubmittedBy, &run.CreatedAt, &run.UpdatedAt,
+	)
+	if errors.Is(err, sql.ErrNoRows) {
+		return domain.PayrollRun{}, util.ErrNotFound
+	}
+	if err != nil {
+		return domain.PayrollRun{}, err
+	}
+	return run, nil
+}
+
+func (r runRepository) Create(ctx context.Context, run domain.PayrollRun) (domain.PayrollRun, error) {
+	now := time.Now()
+	run.CreatedAt = now
+	run.UpdatedAt = now
+
+	err := r.db.QueryRowContext(ctx, `
+		INSERT INTO payroll_runs(payroll_period_id, run_type, status, round, total_net, required_levels,
+		                         generated_by, submitted_by, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $9)
+		RETURNING id`,
+		run.PayrollPeriodID, run.RunType, run.Status, run.Round, run.TotalNet, run.RequiredLevels,
+		run.GeneratedBy, run.SubmittedBy, now,
+	).Scan(&run.ID)
+	if err != nil {
+		return domain.PayrollRun{}, err
+	}
+	return run, nil
+}
+
+func (r runRepository) Update(ctx context.Context, run domain.PayrollRun) (domain.PayrollRun, error) {
+	run.UpdatedAt = time.Now()
+
+	res, err := r.db.ExecContext(ctx, `
+		UPDATE payroll_runs
+		SET status=$1, round=$2, total_net=$3, required_levels=$4, generated_by=$5, submitted_by=$6, updated_at=$7
+		WHERE id = $8`,
+		run.Status, run.Round, run.TotalNet, run.RequiredLevels, run.GeneratedBy, run.SubmittedBy, run.UpdatedAt, run.ID,
+	)
+	if err != nil {
+		return domain.PayrollRun{}, err
+	}
+
+	aff, err := res.RowsAffected()
+	if err == nil && aff == 0 {
+		return domain.PayrollRun{}, util.ErrNotFound
+	}
+	return run, nil
+}
+
+func (r runRepository) Delete(ctx context.Context, id int64) error {
+	res, err := r.db.ExecContext(ctx, `DELETE FROM payroll_runs WHERE id = $1`, id)
+	if err != nil {
+		return err
+	}
+
+	aff, err := res.RowsAffected()
+	if err == nil && aff == 0 {
+		return util.ErrNotFound
+	}
+	return nil
+}
+
+func (r runRepository) AddEvent(ctx context.Context, ev domain.PayrollRunEvent) (domain.PayrollRunEvent, error) {
+	ev.CreatedAt = time.Now()
+
+	err := r.db.QueryRowContext(ctx, `
+		INSERT INTO payroll_run_events(payroll_run_id, round, action, from_status, to_status, actor, comment, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		RETURNING id`,
+		ev.PayrollRunID, ev.Round, ev.Action, ev.FromStatus, ev.ToStatus, ev.Actor, ev.Comment, ev.CreatedAt,
+	).Scan(&ev.ID)
+	if err != nil {
+		return domain.PayrollRunEvent{}, err
+	}
+	return ev, nil
+}
+
+func (r runRepository) ListEvents(ctx context.Context, runID int64) ([]domain.PayrollRunEvent, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT id, payroll_run_id, round, action, from_status, to_status, actor, comment, created_at
+		FROM payroll_run_events
+		WHERE payroll_run_id = $1
+		ORDER BY id`, runID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var result []domain.PayrollRunEvent
+	for rows.Next() {
+		var ev domain.PayrollRunEvent
+		if err := rows.Scan(
+			&ev.ID, &ev.PayrollRunID, &ev.Round, &ev.Action, &ev.FromStatus,
+			&ev.ToStatus, &ev.Actor, &ev.Comment, &ev.CreatedAt); err != nil {
+			return nil, err
+		}
+		result = append(result, ev)
+	}
+	return result, rows.Err()
+}
+
+func (r runRepository) WithTx(tx *sql.Tx) RunRepository {
+	return &runRepository{db: tx}
+}
+
+func NewRunRepository(db *sql.DB) RunRepository {
+	return &runRepository{db: db}
+}
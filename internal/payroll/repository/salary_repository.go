@@ -0,0 +1,120 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"go-payroll-service/internal/payroll/model/domain"
+	"go-payroll-service/internal/payroll/util"
+	"time"
+)
+
+type SalaryRepository interface {
+	ListByEmployee(ctx context.Context, employeeID int64) ([]domain.SalaryRecord, error)
+	ListEffective(ctx context.Context, from, to time.Time) (map[int64][]domain.SalaryRecord, error)
+	Create(ctx context.Context, rec domain.SalaryRecord) (domain.SalaryRecord, error)
+	// Update replaces the amounts, reason and approver of the record.
+	Update(ctx context.Context, rec domain.SalaryRecord) error
+	WithTx(tx *sql.Tx) SalaryRepository
+}
+
+type salaryRepository struct {
+	db DBTX
+}
+
+func (r salaryRepository) ListByEmployee(ctx context.Context, employeeID int64) ([]domain.SalaryRecord, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT id, employee_id, base_salary, allowance, effective_from, reason, approved_by, created_at
+		FROM employee_salaries
+		WHERE employee_id = $1
+		ORDER BY effective_from`, employeeID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var result []domain.SalaryRecord
+	for rows.Next() {
+		rec, err := scanSalaryRecord(rows)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, rec)
+	}
+	return result, rows.Err()
+}
+
+// ListEffective returns, per employee, the records in effect at some point
+// from from to to inclusive: the last one that took effect before from and
+// every one that takes effect inside the range, oldest first.
+func (r salaryRepository) ListEffective(ctx context.Context, from, to time.Time) (map[int64][]domain.SalaryRecord, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT s.id, s.employee_id, s.base_salary, s.allowance, s.effective_from, s.reason, s.approved_by, s.created_at
+		FROM employee_salaries s
+		WHERE s.effective_from <= $2
+		  AND (s.effective_from >= $1
+		       OR s.effective_from = (SELECT MAX(p.effective_from)
+		                              FROM employee_salaries p
+		                              WHERE p.employee_id = s.employee_id
+		                                AND p.effective_from < $1))
+		ORDER BY s.employee_id, s.effective_from`, from, to)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	result := map[int64][]domain.SalaryRecord{}
+	for rows.Next() {
+		rec, err := scanSalaryRecord(rows)
+		if err != nil {
+			return nil, err
+		}
+		result[rec.EmployeeID] = append(result[rec.EmployeeID], rec)
+	}
+	return result, rows.Err()
+}
+
+func (r salaryRepository) Create(ctx context.Context, rec domain.SalaryRecord) (domain.SalaryRecord, error) {
+	rec.CreatedAt = time.Now()
+
+	err := r.db.QueryRowContext(ctx, `
+		INSERT INTO employee_salaries(employee_id, base_salary, allowance, effective_from, reason, approved_by, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING id`,
+		rec.EmployeeID, rec.BaseSalary, rec.Allowance, rec.EffectiveFrom, rec.Reason, rec.ApprovedBy, rec.CreatedAt,
+	).Scan(&rec.ID)
+	if err != nil {
+		return domain.SalaryRecord{}, err
+	}
+	return rec, nil
+}
+
+func (r salaryRepository) Update(ctx context.Context, rec domain.SalaryRecord) error {
+	res, err := r.db.ExecContext(ctx, `
+		UPDATE employee_salaries
+		SET base_salary = $1, allowance = $2, reason = $3, approved_by = $4
+		WHERE id = $5`,
+		rec.BaseSalary, rec.Allowance, rec.Reason, rec.ApprovedBy, rec.ID)
+	if err != nil {
+		return err
+	}
+	aff, err := res.RowsAffected()
+	if err == nil && aff == 0 {
+		return util.ErrNotFound
+	}
+	return nil
+}
+
+func scanSalaryRecord(rows *sql.Rows) (domain.SalaryRecord, error) {
+	var rec domain.SalaryRecord
+	err := rows.Scan(&rec.ID, &rec.EmployeeID, &rec.BaseSalary, &rec.Allowance, &rec.EffectiveFrom,
+		&rec.Reason, &rec.ApprovedBy, &rec.CreatedAt)
+	return rec, err
+}
+
+func (r salaryRepository) WithTx(tx *sql.Tx) SalaryRepository {
+	return &salaryRepository{db: tx}
+}
+
+func NewSalaryRepository(db *sql.DB) SalaryRepository {
+	return &salaryRepository{db: db}
+}
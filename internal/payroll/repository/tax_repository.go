@@ -0,0 +1,126 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"go-payroll-service/internal/payroll/model/domain"
+	"go-payroll-service/internal/payroll/util"
+)
+
+type TaxRepository interface {
+	GetRates(ctx context.Context, year int) (domain.TaxRates, error)
+	WithTx(tx *sql.Tx) TaxRepository
+}
+
+type taxRepository struct {
+	db DBTX
+}
+
+func (r taxRepository) GetRates(ctx context.Context, year int) (domain.TaxRates, error) {
+	var t domain.TaxRates
+	err := r.db.QueryRowContext(ctx, `
+		SELECT effective_year, position_cost_rate_bp, position_cost_monthly_cap,
+		       position_cost_annual_cap, non_npwp_surcharge_bp
+		FROM pph21_settings
+		WHERE effective_year <= $1
+		ORDER BY effective_year DESC
+		LIMIT 1`, year,
+	).Scan(&t.EffectiveYear, &t.PositionCostRateBP, &t.PositionCostMonthlyCap,
+		&t.PositionCostAnnualCap, &t.NonNPWPSurchargeBP)
+
+	if errors.Is(err, sql.ErrNoRows) {
+		return domain.TaxRates{}, util.ErrNotFound
+	}
+	if err != nil {
+		return domain.TaxRates{}, err
+	}
+
+	if t.PTKP, err = r.listPTKP(ctx, t.EffectiveYear); err != nil {
+		return domain.TaxRates{}, err
+	}
+
+	if t.Brackets, err = r.listBrackets(ctx, t.EffectiveYear); err != nil {
+		return domain.TaxRates{}, err
+	}
+
+	if t.TER, err = r.listTER(ctx, t.EffectiveYear); err != nil {
+		return domain.TaxRates{}, err
+	}
+
+	return t, nil
+}
+
+func (r taxRepository) listPTKP(ctx context.Context, year int) (map[string]domain.PTKPRate, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT status, amount, ter_category
+		FROM pph21_ptkp
+		WHERE effective_year = $1`, year)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	result := map[string]domain.PTKPRate{}
+	for rows.Next() {
+		var p domain.PTKPRate
+		if err := rows.Scan(&p.Status, &p.Amount, &p.TERCategory); err != nil {
+			return nil, err
+		}
+		result[p.Status] = p
+	}
+	return result, rows.Err()
+}
+
+func (r taxRepository) listTER(ctx context.Context, year int) (map[string][]domain.TaxBracket, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT category, lower_bound, COALESCE(upper_bound, 0), rate_bp
+		FROM pph21_ter_rates
+		WHERE effective_year = $1
+		ORDER BY category, lower_bound`, year)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	result := map[string][]domain.TaxBracket{}
+	for rows.Next() {
+		var category string
+		var b domain.TaxBracket
+		if err := rows.Scan(&category, &b.LowerBound, &b.UpperBound, &b.RateBP); err != nil {
+			return nil, err
+		}
+		result[category] = append(result[category], b)
+	}
+	return result, rows.Err()
+}
+
+func (r taxRepository) listBrackets(ctx context.Context, year int) ([]domain.TaxBracket, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT lower_bound, COALESCE(upper_bound, 0), rate_bp
+		FROM pph21_brackets
+		WHERE effective_year = $1
+		ORDER BY lower_bound`, year)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var result []domain.TaxBracket
+	for rows.Next() {
+		var b domain.TaxBracket
+		if err := rows.Scan(&b.LowerBound, &b.UpperBound, &b.RateBP); err != nil {
+			return nil, err
+		}
+		result = append(result, b)
+	}
+	return result, rows.Err()
+}
+
+func (r taxRepository) WithTx(tx *sql.Tx) TaxRepository {
+	return &taxRepository{db: tx}
+}
+
+func NewTaxRepository(db *sql.DB) TaxRepository {
+	return &taxRepository{db: db}
+}
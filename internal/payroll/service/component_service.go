@@ -0,0 +1,422 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"go-payroll-service/internal/payroll/formula"
+	"go-payroll-service/internal/payroll/model/domain"
+	"go-payroll-service/internal/payroll/model/request"
+	"go-payroll-service/internal/payroll/repository"
+	"go-payroll-service/internal/payroll/util"
+	"math"
+	"regexp"
+	"slices"
+	"time"
+)
+
+// Variables every component formula can read, besides the codes of other
+// components.
+const (
+	varBaseSalary      = "base_salary"
+	varAllowance       = "allowance"
+	varYearsOfService  = "years_of_service"
+	varMonthsOfService = "months_of_service"
+	varWorkingDays     = "working_days"
+	varDaysPresent     = "days_present"
+	varDaysAbsent      = "days_absent"
+	varProrationFactor = "proration_factor"
+)
+
+var (
+	formulaVariables = []string{
+		varBaseSalary, varAllowance, varYearsOfService, varMonthsOfService,
+		varWorkingDays, varDaysPresent, varDaysAbsent, varProrationFactor,
+	}
+	formulaKeywords   = []string{"if", "min", "max", "round", "floor", "ceil", "abs"}
+	componentCodeExpr = regexp.MustCompile(`^[a-z][a-z0-9_]{0,49}$`)
+)
+
+type ComponentService interface {
+	List(ctx context.Context) ([]domain.PayComponent, error)
+	Create(ctx context.Context, req request.CreateComponentRequest) (domain.PayComponent, error)
+	GetByID(ctx context.Context, id int64) (domain.PayComponent, error)
+	Update(ctx context.Context, id int64, req request.UpdateComponentRequest) (domain.PayComponent, error)
+	ListEmployeeComponents(ctx context.Context, employeeID int64) ([]domain.EmployeeComponent, error)
+	AssignEmployeeComponent(ctx context.Context, employeeID, componentID int64, req request.AssignComponentRequest) (domain.EmployeeComponent, error)
+	UnassignEmployeeComponent(ctx context.Context, employeeID, componentID int64) error
+}
+
+type componentService struct {
+	repository         repository.ComponentRepository
+	employeeRepository repository.EmployeeRepository
+	auditRepository    repository.AuditRepository
+	transactor         repository.Transactor
+}
+
+func (s componentService) withTx(tx *sql.Tx) componentService {
+	s.repository = s.repository.WithTx(tx)
+	s.employeeRepository = s.employeeRepository.WithTx(tx)
+	s.auditRepository = s.auditRepository.WithTx(tx)
+	return s
+}
+
+func (s componentService) List(ctx context.Context) ([]domain.PayComponent, error) {
+	return s.repository.List(ctx)
+}
+
+func (s componentService) Create(ctx context.Context, req request.CreateComponentRequest) (domain.PayComponent, error) {
+	if !componentCodeExpr.MatchString(req.Code) {
+		return domain.PayComponent{}, fmt.Errorf("%w: code must be lower case letters, digits and underscores", util.ErrInvalid)
+	}
+	if slices.Contains(formulaVariables, req.Code) || slices.Contains(formulaKeywords, req.Code) {
+		return domain.PayComponent{}, fmt.Errorf("%w: code %q is reserved", util.ErrInvalid, req.Code)
+	}
+
+	c := domain.PayComponent{
+		Code:    req.Code,
+		Name:    req.Name,
+		Type:    req.Type,
+		Formula: req.Formula,
+		// Earnings are taxed unless said otherwise. Deductions do not
+		// lower the tax; only BPJS JHT/JP contributions do.
+		Taxable: req.Type == domain.LineTypeEarning,
+	}
+	if req.Taxable != nil {
+		c.Taxable = *req.Taxable
+	}
+
+	if err := s.validateCatalog(ctx, c); err != nil {
+		return domain.PayComponent{}, err
+	}
+
+	var created domain.PayComponent
+	err := s.transactor.WithinTx(ctx, func(tx *sql.Tx) error {
+		txs := s.withTx(tx)
+		var err error
+		if created, err = txs.repository.Create(ctx, c); err != nil {
+			return err
+		}
+		return recordAudit(ctx, txs.auditRepository, domain.AuditActionCreate, domain.AuditEntityComponent, created.ID, nil, created)
+	})
+	if err != nil {
+		return domain.PayComponent{}, err
+	}
+	return created, nil
+}
+
+func (s componentService) GetByID(ctx context.Context, id int64) (domain.PayComponent, error) {
+	return s.repository.GetByID(ctx, id)
+}
+
+func (s componentService) Update(ctx context.Context, id int64, req request.UpdateComponentRequest) (domain.PayComponent, error) {
+	current, err := s.repository.GetByID(ctx, id)
+	if err != nil {
+		return domain.PayComponent{}, err
+	}
+	before := current
+	if req.Name != nil {
+		current.Name = *req.Name
+	}
+	if req.Type != nil {
+		current.Type = *req.Type
+	}
+	if req.Taxable != nil {
+		current.Taxable = *req.Taxable
+	}
+	if req.Formula != nil {
+		current.Formula = *req.Formula
+	}
+	if req.IsActive != nil {
+		current.IsActive = *req.IsActive
+	}
+
+	if err := s.validateCatalog(ctx, current); err != nil {
+		return domain.PayComponent{}, err
+	}
+
+	var updated domain.PayComponent
+	err = s.transactor.WithinTx(ctx, func(tx *sql.Tx) error {
+		txs := s.withTx(tx)
+		var err error
+		if updated, err = txs.repository.Update(ctx, current); err != nil {
+			return err
+		}
+		return recordAudit(ctx, txs.auditRepository, domain.AuditActionUpdate, domain.AuditEntityComponent, id, before, updated)
+	})
+	if err != nil {
+		return domain.PayComponent{}, err
+	}
+	return updated, nil
+}
+
+// validateCatalog checks c's formula against the catalog it is joining:
+// every name it reads must be a formula variable or a component code, and
+// the catalog formulas must not depend on each other in a cycle.
+func (s componentService) validateCatalog(ctx context.Context, c domain.PayComponent) error {
+	catalog, err := s.repository.List(ctx)
+	if err != nil {
+		return err
+	}
+
+	exprs := map[string]*formula.Expr{}
+	for _, other := range catalog {
+		if other.Code == c.Code {
+			continue
+		}
+		if expr, err := formula.Parse(other.Formula); err == nil {
+			exprs[other.Code] = expr
+		}
+	}
+	return checkFormula(c.Code, c.Formula, exprs)
+}
+
+// validateAssignment checks ec's formula override the way validateCatalog
+// checks a catalog formula, against the formulas the employee is paid by:
+// their other assignments', and the catalog's for the rest.
+func (s componentService) validateAssignment(ctx context.Context, ec domain.EmployeeComponent) error {
+	catalog, err := s.repository.List(ctx)
+	if err != nil {
+		return err
+	}
+	assigned, err := s.repository.ListAssignments(ctx, ec.EmployeeID)
+	if err != nil {
+		return err
+	}
+
+	exprs := map[string]*formula.Expr{}
+	for _, other := range catalog {
+		if expr, err := formula.Parse(other.Formula); err == nil {
+			exprs[other.Code] = expr
+		}
+	}
+	for _, other := range assigned {
+		if expr, err := formula.Parse(other.Formula()); err == nil {
+			exprs[other.Component.Code] = expr
+		}
+	}
+	delete(exprs, ec.Component.Code)
+	return checkFormula(ec.Component.Code, *ec.FormulaOverride, exprs)
+}
+
+// checkFormula parses src as the formula of code, which may read the
+// formula variables and the components in others, and checks that it does
+// not close a cycle with them. others gains code.
+func checkFormula(code, src string, others map[string]*formula.Expr) error {
+	expr, err := parseComponentFormula(src, others)
+	if err != nil {
+		return err
+	}
+	others[code] = expr
+
+	if _, err := formula.Order(others); err != nil {
+		return fmt.Errorf("%w: %w", util.ErrInvalid, err)
+	}
+	return nil
+}
+
+func (s componentService) ListEmployeeComponents(ctx context.Context, employeeID int64) ([]domain.EmployeeComponent, error) {
+	if _, err := s.employeeRepository.GetByID(ctx, employeeID); err != nil {
+		return nil, err
+	}
+	return s.repository.ListAssignments(ctx, employeeID)
+}
+
+func (s componentService) AssignEmployeeComponent(ctx context.Context, employeeID, componentID int64, req request.AssignComponentRequest) (domain.EmployeeComponent, error) {
+	if _, err := s.employeeRepository.GetByID(ctx, employeeID); err != nil {
+		return domain.EmployeeComponent{}, err
+	}
+	c, err := s.repository.GetByID(ctx, componentID)
+	if err != nil {
+		return domain.EmployeeComponent{}, err
+	}
+
+	ec := domain.EmployeeComponent{
+		EmployeeID:      employeeID,
+		ComponentID:     componentID,
+		AmountOverride:  req.Amount,
+		FormulaOverride: req.Formula,
+		Component:       c,
+	}
+
+	if ec.AmountOverride == nil && ec.FormulaOverride != nil {
+		if err := s.validateAssignment(ctx, ec); err != nil {
+			return domain.EmployeeComponent{}, err
+		}
+	}
+
+	var saved domain.EmployeeComponent
+	err = s.transactor.WithinTx(ctx, func(tx *sql.Tx) error {
+		txs := s.withTx(tx)
+		var before any
+		action := domain.AuditActionCreate
+		if existing, ok, err := txs.assignment(ctx, employeeID, componentID); err != nil {
+			return err
+		} else if ok {
+			before, action = existing, domain.AuditActionUpdate
+		}
+		if saved, err = txs.repository.Assign(ctx, ec); err != nil {
+			return err
+		}
+		return recordAudit(ctx, txs.auditRepository, action, domain.AuditEntityEmployeeComponent,
+			assignmentID(employeeID, componentID), before, saved)
+	})
+	if err != nil {
+		return domain.EmployeeComponent{}, err
+	}
+	saved.Component = c
+	return saved, nil
+}
+
+func (s componentService) UnassignEmployeeComponent(ctx context.Context, employeeID, componentID int64) error {
+	return s.transactor.WithinTx(ctx, func(tx *sql.Tx) error {
+		txs := s.withTx(tx)
+		before, ok, err := txs.assignment(ctx, employeeID, componentID)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return util.ErrNotFound
+		}
+		if err := txs.repository.Unassign(ctx, employeeID, componentID); err != nil {
+			return err
+		}
+		return recordAudit(ctx, txs.auditRepository, domain.AuditActionDelete, domain.AuditEntityEmployeeComponent,
+			assignmentID(employeeID, componentID), before, nil)
+	})
+}
+
+// assignment finds the employee's assignment of the component, if any.
+func (s componentService) assignment(ctx context.Context, employeeID, componentID int64) (domain.EmployeeComponent, bool, error) {
+	assigned, err := s.repository.ListAssignments(ctx, employeeID)
+	if err != nil {
+		return domain.EmployeeComponent{}, false, err
+	}
+	for _, ec := range assigned {
+		if ec.ComponentID == componentID {
+			return ec, true, nil
+		}
+	}
+	return domain.EmployeeComponent{}, false, nil
+}
+
+// assignmentID identifies an assignment in the audit log.
+func assignmentID(employeeID, componentID int64) string {
+	return fmt.Sprintf("%d/%d", employeeID, componentID)
+}
+
+// parseComponentFormula parses src and checks that every variable it reads
+// is a formula variable or one of the component codes in known.
+func parseComponentFormula(src string, known map[string]*formula.Expr) (*formula.Expr, error) {
+	expr, err := formula.Parse(src)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", util.ErrInvalid, err)
+	}
+	for _, v := range expr.Variables() {
+		if _, ok := known[v]; ok || slices.Contains(formulaVariables, v) {
+			continue
+		}
+		return nil, fmt.Errorf("%w: %w %q", util.ErrInvalid, formula.ErrUnknownVariable, v)
+	}
+	return expr, nil
+}
+
+// evaluateComponents computes an employee's component lines for a period.
+// Formulas are evaluated in dependency order; a component reading the code
+// of a catalog component the employee is not assigned sees zero.
+func evaluateComponents(catalog []domain.PayComponent, assigned []domain.EmployeeComponent, vars map[string]float64) ([]domain.PayslipLine, error) {
+	for _, c := range catalog {
+		vars[c.Code] = 0
+	}
+
+	exprs := map[string]*formula.Expr{}
+	byCode := map[string]domain.EmployeeComponent{}
+	for _, ec := range assigned {
+		expr, err := formula.Parse(ec.Formula())
+		if err != nil {
+			return nil, fmt.Errorf("component %s: %w", ec.Component.Code, err)
+		}
+		exprs[ec.Component.Code] = expr
+		byCode[ec.Component.Code] = ec
+	}
+
+	order, err := formula.Order(exprs)
+	if err != nil {
+		return nil, err
+	}
+
+	var lines []domain.PayslipLine
+	for _, code := range order {
+		v, err := exprs[code].Eval(vars)
+		if err != nil {
+			return nil, fmt.Errorf("component %s: %w", code, err)
+		}
+		// A formula that overflows gives ±Inf, and Inf - Inf gives NaN;
+		// neither, nor anything past int64, rounds to an amount.
+		if math.IsInf(v, 0) || math.IsNaN(v) || math.Abs(v) >= math.MaxInt64 {
+			return nil, fmt.Errorf("%w: component %s: formula %q gave %v, not an amount", util.ErrInvalid, code, exprs[code], v)
+		}
+		amount := int64(math.Round(v))
+		if amount < 0 {
+			return nil, fmt.Errorf("component %s: formula %q gave a negative amount %d", code, exprs[code], amount)
+		}
+		vars[code] = float64(amount)
+
+		if amount == 0 {
+			continue
+		}
+		c := byCode[code].Component
+		lines = append(lines, domain.PayslipLine{
+			Code:    c.Code,
+			Label:   c.Name,
+			Type:    c.Type,
+			Amount:  amount,
+			Taxable: c.Taxable,
+			Source:  domain.LineSourceComponent,
+		})
+	}
+	return lines, nil
+}
+
+// employeeVariables builds the formula variables for an employee in a
+// period. Attendance variables are only set when attendance was recorded,
+// so a formula that needs them fails instead of paying on a guess.
+// prorationFactor is the share of the period the employee was employed.
+func employeeVariables(e domain.Employee, period domain.PayrollPeriod, attendance *domain.Attendance, prorationFactor float64) map[string]float64 {
+	months := monthsBetween(e.HireDate, period.EndDate)
+	vars := map[string]float64{
+		varBaseSalary:      float64(e.BaseSalary),
+		varAllowance:       float64(e.Allowance),
+		varMonthsOfService: float64(months),
+		varYearsOfService:  float64(months / 12),
+		varProrationFactor: prorationFactor,
+	}
+	if attendance != nil {
+		vars[varWorkingDays] = float64(attendance.WorkingDays)
+		vars[varDaysPresent] = float64(attendance.DaysPresent)
+		vars[varDaysAbsent] = float64(attendance.WorkingDays - attendance.DaysPresent)
+	}
+	return vars
+}
+
+// monthsBetween counts the whole months from start to end.
+func monthsBetween(start, end time.Time) int {
+	if end.Before(start) {
+		return 0
+	}
+	months := (end.Year()-start.Year())*12 + int(end.Month()-start.Month())
+	if end.Day() < start.Day() {
+		months--
+	}
+	return max(months, 0)
+}
+
+func NewComponentService(repository repository.ComponentRepository, employeeRepository repository.EmployeeRepository,
+	auditRepository repository.AuditRepository, transactor repository.Transactor) ComponentService {
+	return &componentService{
+		repository:         repository,
+		employeeRepository: employeeRepository,
+		auditRepository:    auditRepository,
+		transactor:         transactor,
+	}
+}
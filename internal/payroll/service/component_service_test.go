@@ -0,0 +1,121 @@
+package service
+
+import (
+	"errors"
+	"go-payroll-service/internal/payroll/formula"
+	"go-payroll-service/internal/payroll/model/domain"
+	"go-payroll-service/internal/payroll/util"
+	"strings"
+	"testing"
+)
+
+func TestCheckFormula(t *testing.T) {
+	catalog := map[string]string{
+		"meal":      "days_present * 50000",
+		"transport": "days_present * 25000",
+		"bonus":     "meal + transport",
+	}
+	tests := []struct {
+		name    string
+		code    string
+		src     string
+		wantErr bool
+	}{
+		{"reads variables", "meal", "working_days * 40000", false},
+		{"reads another component", "meal", "transport * 2", false},
+		{"unknown name", "meal", "lunch * 2", true},
+		{"syntax error", "meal", "days_present *", true},
+		// transport is read by bonus, so reading bonus closes a cycle.
+		{"cycle through another", "transport", "bonus / 2", true},
+		{"reads itself", "meal", "meal + 1", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			others := map[string]*formula.Expr{}
+			for code, src := range catalog {
+				if code != tt.code {
+					others[code] = mustParse(t, src)
+				}
+			}
+			err := checkFormula(tt.code, tt.src, others)
+			if tt.wantErr != (err != nil) || (err != nil && !errors.Is(err, util.ErrInvalid)) {
+				t.Errorf("checkFormula(%q) error = %v, want error %v", tt.src, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestEvaluateComponents(t *testing.T) {
+	component := func(code, src string) domain.EmployeeComponent {
+		return domain.EmployeeComponent{Component: domain.PayComponent{Code: code, Name: code, Type: domain.LineTypeEarning, Formula: src}}
+	}
+	// huge squared overflows a float64.
+	huge := "1" + strings.Repeat("0", 200)
+	tests := []struct {
+		name      string
+		assigned  []domain.EmployeeComponent
+		want      map[string]int64
+		wantError string
+	}{
+		{
+			"dependency order",
+			[]domain.EmployeeComponent{component("bonus", "meal * 2"), component("meal", "days_present * 50000")},
+			map[string]int64{"meal": 1000000, "bonus": 2000000},
+			"",
+		},
+		{"zero amounts left out", []domain.EmployeeComponent{component("meal", "days_absent * 50000")}, map[string]int64{}, ""},
+		{"rounded", []domain.EmployeeComponent{component("meal", "base_salary / 3")}, map[string]int64{"meal": 3333333}, ""},
+		{"negative", []domain.EmployeeComponent{component("meal", "0 - 1")}, nil, "component meal: formula"},
+		{"infinite", []domain.EmployeeComponent{component("meal", huge+" * "+huge)}, nil, "component meal: formula"},
+		{"not a number", []domain.EmployeeComponent{component("meal", huge+" * "+huge+" - "+huge+" * "+huge)}, nil, "component meal: formula"},
+		{"past int64", []domain.EmployeeComponent{component("meal", "10000000000000000000")}, nil, "component meal: formula"},
+		{"cycle", []domain.EmployeeComponent{component("a", "b"), component("b", "a")}, nil, "cycle"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			vars := map[string]float64{varBaseSalary: 10000000, varDaysPresent: 20, varDaysAbsent: 0}
+			lines, err := evaluateComponents(nil, tt.assigned, vars)
+			if tt.wantError != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantError) {
+					t.Fatalf("evaluateComponents() error = %v, want one naming %q", err, tt.wantError)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("evaluateComponents() error = %v", err)
+			}
+			got := map[string]int64{}
+			for _, l := range lines {
+				got[l.Code] = l.Amount
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("evaluateComponents() = %v, want %v", got, tt.want)
+			}
+			for code, amount := range tt.want {
+				if got[code] != amount {
+					t.Errorf("evaluateComponents() %s = %d, want %d", code, got[code], amount)
+				}
+			}
+		})
+	}
+}
+
+func TestEvaluateComponentsNotAnAmount(t *testing.T) {
+	huge := "1" + strings.Repeat("0", 200)
+	for _, src := range []string{huge + " * " + huge, huge + " * " + huge + " - " + huge + " * " + huge} {
+		assigned := []domain.EmployeeComponent{{Component: domain.PayComponent{Code: "meal", Formula: src}}}
+		_, err := evaluateComponents(nil, assigned, map[string]float64{})
+		if !errors.Is(err, util.ErrInvalid) || !strings.Contains(err.Error(), "not an amount") {
+			t.Errorf("evaluateComponents() error = %v, want util.ErrInvalid", err)
+		}
+	}
+}
+
+func mustParse(t *testing.T, src string) *formula.Expr {
+	t.Helper()
+	expr, err := formula.Parse(src)
+	if err != nil {
+		t.Fatalf("formula.Parse(%q) error = %v", src, err)
+	}
+	return expr
+}
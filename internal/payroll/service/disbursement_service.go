@@ -0,0 +1,194 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"go-payroll-service/internal/payroll/disbursement"
+	"go-payroll-service/internal/payroll/model/domain"
+	"go-payroll-service/internal/payroll/model/request"
+	"go-payroll-service/internal/payroll/repository"
+	"go-payroll-service/internal/payroll/util"
+	"io"
+	"strings"
+	"time"
+)
+
+type DisbursementService interface {
+	GetBankAccount(ctx context.Context, employeeID int64) (domain.BankAccount, error)
+	SaveBankAccount(ctx context.Context, employeeID int64, req request.SaveBankAccountRequest) (domain.BankAccount, error)
+	// Export writes the net salaries of the period's run type to w as a
+	// bank file in the given format.
+	Export(ctx context.Context, periodCode, runType, format string, w io.Writer) (disbursement.File, error)
+}
+
+type disbursementService struct {
+	bankAccountRepository repository.BankAccountRepository
+	employeeRepository    repository.EmployeeRepository
+	periodRepository      repository.PeriodRepository
+	payrollRepository     repository.PayrollRepository
+	runRepository         repository.RunRepository
+	auditRepository       repository.AuditRepository
+	transactor            repository.Transactor
+	exporters             *disbursement.Registry
+	debtor                disbursement.Account
+}
+
+func (s disbursementService) withTx(tx *sql.Tx) disbursementService {
+	s.bankAccountRepository = s.bankAccountRepository.WithTx(tx)
+	s.auditRepository = s.auditRepository.WithTx(tx)
+	return s
+}
+
+func (s disbursementService) GetBankAccount(ctx context.Context, employeeID int64) (domain.BankAccount, error) {
+	return s.bankAccountRepository.GetByEmployeeID(ctx, employeeID)
+}
+
+func (s disbursementService) SaveBankAccount(ctx context.Context, employeeID int64, req request.SaveBankAccountRequest) (domain.BankAccount, error) {
+	if _, err := s.employeeRepository.GetByID(ctx, employeeID); err != nil {
+		return domain.BankAccount{}, err
+	}
+
+	var saved domain.BankAccount
+	err := s.transactor.WithinTx(ctx, func(tx *sql.Tx) error {
+		txs := s.withTx(tx)
+		var before any
+		action := domain.AuditActionCreate
+		existing, err := txs.bankAccountRepository.GetByEmployeeID(ctx, employeeID)
+		switch {
+		case err == nil:
+			before, action = existing, domain.AuditActionUpdate
+		case !errors.Is(err, util.ErrNotFound):
+			return err
+		}
+		saved, err = txs.bankAccountRepository.Upsert(ctx, domain.BankAccount{
+			EmployeeID:    employeeID,
+			BankCode:      req.BankCode,
+			AccountNumber: req.AccountNumber,
+			AccountName:   strings.TrimSpace(req.AccountName),
+		})
+		if err != nil {
+			return err
+		}
+		return recordAudit(ctx, txs.auditRepository, action, domain.AuditEntityBankAccount, employeeID, before, saved)
+	})
+	if err != nil {
+		return domain.BankAccount{}, err
+	}
+	return saved, nil
+}
+
+// Export only pays approved runs of locked or closed periods, so the amounts
+// cannot change after the file is handed to the bank. Employees with a zero
+// net salary are left out and listed in the file's Skipped. The whole batch is validated first;
+// a *disbursement.ValidationError lists every payment that must be fixed.
+func (s disbursementService) Export(ctx context.Context, periodCode, runType, format string, w io.Writer) (disbursement.File, error) {
+	exporter, ok := s.exporters.Get(format)
+	if !ok {
+		return disbursement.File{}, fmt.Errorf("%w: unknown format %q, expected one of %s",
+			util.ErrInvalid, format, strings.Join(s.exporters.Formats(), ", "))
+	}
+
+	period, err := s.periodRepository.GetByCode(ctx, periodCode)
+	if err != nil {
+		return disbursement.File{}, err
+	}
+	if period.Status != domain.PeriodStatusLocked && period.Status != domain.PeriodStatusClosed {
+		return disbursement.File{}, fmt.Errorf("%w: period %s is %s", util.ErrPeriodUnlocked, period.Code, period.Status)
+	}
+	run, err := s.runRepository.Get(ctx, period.ID, runType)
+	if err != nil {
+		return disbursement.File{}, err
+	}
+	if run.Status != domain.RunStatusApproved {
+		return disbursement.File{}, fmt.Errorf("%w: %s run of period %s is %s", util.ErrRunNotApproved, runType, period.Code, run.Status)
+	}
+
+	batch, err := s.batch(ctx, period, runType)
+	if err != nil {
+		return disbursement.File{}, err
+	}
+	if err := disbursement.Validate(exporter, batch); err != nil {
+		return disbursement.File{}, err
+	}
+	if err := exporter.Export(w, batch); err != nil {
+		return disbursement.File{}, err
+	}
+
+	name := "salaries-" + period.Code
+	if runType != domain.RunTypeRegular {
+		name += "-" + runType
+	}
+	return disbursement.File{
+		Name:        fmt.Sprintf("%s-%s.%s", name, exporter.Format(), exporter.FileExtension()),
+		ContentType: exporter.ContentType(),
+		Control:     batch.ControlTotal(),
+		Skipped:     batch.Skipped,
+	}, nil
+}
+
+func (s disbursementService) batch(ctx context.Context, period domain.PayrollPeriod, runType string) (disbursement.Batch, error) {
+	payslips, err := s.payrollRepository.ListPayslipByPeriodCode(ctx, period.Code, runType)
+	if err != nil {
+		return disbursement.Batch{}, err
+	}
+	accounts, err := s.bankAccountRepository.List(ctx)
+	if err != nil {
+		return disbursement.Batch{}, err
+	}
+	employees, err := s.employeeRepository.List(ctx)
+	if err != nil {
+		return disbursement.Batch{}, err
+	}
+	codes := map[int64]string{}
+	for _, e := range employees {
+		codes[e.ID] = e.Code
+	}
+
+	now := time.Now()
+	batch := disbursement.Batch{
+		ID:         fmt.Sprintf("SAL-%s-%s", period.Code, now.Format("20060102150405")),
+		PeriodCode: period.Code,
+		PayDate:    period.PayDate,
+		CreatedAt:  now,
+		Debtor:     s.debtor,
+	}
+	for _, p := range payslips {
+		if p.NetSalary() == 0 {
+			batch.Skipped = append(batch.Skipped, codes[p.EmployeeID])
+			continue
+		}
+		// A missing account is left empty so validation reports it.
+		acc := accounts[p.EmployeeID]
+		batch.Payments = append(batch.Payments, disbursement.Payment{
+			EmployeeCode: codes[p.EmployeeID],
+			EmployeeName: p.EmployeeName,
+			Account: disbursement.Account{
+				Name:     acc.AccountName,
+				BankCode: acc.BankCode,
+				Number:   acc.AccountNumber,
+			},
+			Amount:    p.NetSalary(),
+			Reference: fmt.Sprintf("PS%d", p.ID),
+		})
+	}
+	return batch, nil
+}
+
+func NewDisbursementService(bankAccountRepository repository.BankAccountRepository, employeeRepository repository.EmployeeRepository,
+	periodRepository repository.PeriodRepository, payrollRepository repository.PayrollRepository,
+	runRepository repository.RunRepository, auditRepository repository.AuditRepository, transactor repository.Transactor,
+	exporters *disbursement.Registry, debtor disbursement.Account) DisbursementService {
+	return &disbursementService{
+		bankAccountRepository: bankAccountRepository,
+		employeeRepository:    employeeRepository,
+		periodRepository:      periodRepository,
+		payrollRepository:     payrollRepository,
+		runRepository:         runRepository,
+		auditRepository:       auditRepository,
+		transactor:            transactor,
+		exporters:             exporters,
+		debtor:                debtor,
+	}
+}
@@ -0,0 +1,97 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"go-payroll-service/internal/payroll/importer"
+	"go-payroll-service/internal/payroll/model/domain"
+	"go-payroll-service/internal/payroll/model/request"
+	"go-payroll-service/internal/payroll/util"
+	"strings"
+)
+
+const maxImportRows = 5000
+
+// Import checks every row against the create rules and for codes and
+// emails already taken, in the file or the database, then creates the
+// valid employees in one transaction. A dry run stops after the checks;
+// with AllOrNothing, one invalid row rejects the file.
+func (s employeeService) Import(ctx context.Context, data []byte, req request.ImportEmployeesRequest) (domain.EmployeeImport, error) {
+	format := strings.ToLower(req.Format)
+	if format == "" {
+		format = importer.FormatOf(req.FileName)
+	}
+	if format == "" {
+		return domain.EmployeeImport{}, fmt.Errorf("%w: format must be %s or %s", util.ErrInvalid, importer.FormatCSV, importer.FormatXLSX)
+	}
+	rows, err := importer.Read(format, data, maxImportRows)
+	if err != nil {
+		return domain.EmployeeImport{}, fmt.Errorf("%w: %w", util.ErrInvalid, err)
+	}
+
+	existing, err := s.repository.List(ctx)
+	if err != nil {
+		return domain.EmployeeImport{}, err
+	}
+	// Deleted employees keep their code and email.
+	takenCodes := map[string]bool{}
+	takenEmails := map[string]bool{}
+	for _, e := range existing {
+		takenCodes[e.Code] = true
+		takenEmails[strings.ToLower(e.Email)] = true
+	}
+
+	result := domain.EmployeeImport{Format: format, DryRun: req.DryRun}
+	requests := make([]request.CreateEmployeeRequest, len(rows))
+	codeLines := map[string]int{}
+	emailLines := map[string]int{}
+	for i, row := range rows {
+		r, problems := importer.ParseEmployee(row)
+		if r.Code != "" {
+			if line, ok := codeLines[r.Code]; ok {
+				problems = append(problems, fmt.Sprintf("code: %s is also on line %d", r.Code, line))
+			} else if takenCodes[r.Code] {
+				problems = append(problems, fmt.Sprintf("code: %s already exists", r.Code))
+			}
+			codeLines[r.Code] = row.Line
+		}
+		if email := strings.ToLower(r.Email); email != "" {
+			if line, ok := emailLines[email]; ok {
+				problems = append(problems, fmt.Sprintf("email: %s is also on line %d", r.Email, line))
+			} else if takenEmails[email] {
+				problems = append(problems, fmt.Sprintf("email: %s already exists", r.Email))
+			}
+			emailLines[email] = row.Line
+		}
+		requests[i] = r
+		result.Rows = append(result.Rows, domain.EmployeeImportRow{Line: row.Line, Code: r.Code, Errors: problems})
+	}
+
+	if result.DryRun {
+		return result, nil
+	}
+	if req.AllOrNothing && result.Invalid() > 0 {
+		result.Rejected = true
+		return result, nil
+	}
+
+	err = s.transactor.WithinTx(ctx, func(tx *sql.Tx) error {
+		txs := s.withTx(tx)
+		for i := range result.Rows {
+			if !result.Rows[i].Valid() {
+				continue
+			}
+			e, err := txs.create(ctx, requests[i])
+			if err != nil {
+				return fmt.Errorf("line %d: %w", result.Rows[i].Line, err)
+			}
+			result.Rows[i].EmployeeID = e.ID
+		}
+		return nil
+	})
+	if err != nil {
+		return domain.EmployeeImport{}, err
+	}
+	return result, nil
+}
@@ -1,77 +1,359 @@
 package service
 
 import (
+	"cmp"
 	"context"
+	"database/sql"
+	"fmt"
 	"go-payroll-service/internal/payroll/model/domain"
 	"go-payroll-service/internal/payroll/model/request"
 	"go-payroll-service/internal/payroll/repository"
+	"go-payroll-service/internal/payroll/util"
+	"slices"
+	"strings"
+	"time"
 )
 
+const (
+	defaultPTKPStatus   = "TK/0"
+	initialSalaryReason = "Initial salary"
+	updateSalaryReason  = "Changed on employee record"
+
+	defaultEmployeePageSize = 50
+	maxEmployeePageSize     = 200
+)
+
+var employeeSorts = []string{
+	domain.EmployeeSortID, domain.EmployeeSortCode, domain.EmployeeSortFullName,
+	domain.EmployeeSortHireDate, domain.EmployeeSortBaseSalary,
+}
+
 type EmployeeService interface {
-	List(ctx context.Context) ([]domain.Employee, error)
+	List(ctx context.Context, req request.ListEmployeesRequest) (domain.EmployeePage, error)
 	Create(ctx context.Context, req request.CreateEmployeeRequest) (domain.Employee, error)
+	// Import validates the rows of a CSV or XLSX file of employees and
+	// creates the valid ones in one transaction.
+	Import(ctx context.Context, data []byte, req request.ImportEmployeesRequest) (domain.EmployeeImport, error)
 	GetByID(ctx context.Context, id int64) (domain.Employee, error)
 	Update(ctx context.Context, id int64, req request.UpdateEmployeeRequest) (domain.Employee, error)
+	Terminate(ctx context.Context, id int64, req request.TerminateEmployeeRequest) (domain.Employee, error)
+	// Delete soft deletes the employee: they drop out of listings and
+	// payroll runs, while their payslips stay.
 	Delete(ctx context.Context, id int64) error
 }
 
 type employeeService struct {
-	repository repository.EmployeeRepository
+	repository       repository.EmployeeRepository
+	salaryRepository repository.SalaryRepository
+	auditRepository  repository.AuditRepository
+	transactor       repository.Transactor
+}
+
+func (s employeeService) withTx(tx *sql.Tx) employeeService {
+	s.repository = s.repository.WithTx(tx)
+	s.salaryRepository = s.salaryRepository.WithTx(tx)
+	s.auditRepository = s.auditRepository.WithTx(tx)
+	return s
 }
 
-func (s employeeService) List(ctx context.Context) ([]domain.Employee, error) {
-	return s.repository.List(ctx)
+// List returns a page of the employees matching the request, by ID unless
+// another sort is asked for. Deleted employees are left out.
+func (s employeeService) List(ctx context.Context, req request.ListEmployeesRequest) (domain.EmployeePage, error) {
+	q := domain.EmployeeQuery{
+		Active:     req.Active,
+		MinSalary:  req.MinSalary,
+		MaxSalary:  req.MaxSalary,
+		Search:     strings.TrimSpace(req.Search),
+		Department: strings.TrimSpace(req.Department),
+		SortBy:     domain.EmployeeSortID,
+		Limit:      defaultEmployeePageSize,
+	}
+	if req.Limit > 0 {
+		q.Limit = min(req.Limit, maxEmployeePageSize)
+	}
+	if req.Sort != "" {
+		q.SortBy, q.SortDesc = strings.CutPrefix(req.Sort, "-")
+		if !slices.Contains(employeeSorts, q.SortBy) {
+			return domain.EmployeePage{}, fmt.Errorf("%w: sort must be one of %s, optionally prefixed with -",
+				util.ErrInvalid, strings.Join(employeeSorts, ", "))
+		}
+	}
+	if req.HiredFrom != "" {
+		from, err := time.Parse(dateLayout, req.HiredFrom)
+		if err != nil {
+			return domain.EmployeePage{}, fmt.Errorf("%w: hired_from: %w", util.ErrInvalid, err)
+		}
+		q.HiredFrom = &from
+	}
+	if req.HiredTo != "" {
+		to, err := time.Parse(dateLayout, req.HiredTo)
+		if err != nil {
+			return domain.EmployeePage{}, fmt.Errorf("%w: hired_to: %w", util.ErrInvalid, err)
+		}
+		q.HiredTo = &to
+	}
+	if q.HiredFrom != nil && q.HiredTo != nil && q.HiredTo.Before(*q.HiredFrom) {
+		return domain.EmployeePage{}, fmt.Errorf("%w: hired_to is before hired_from", util.ErrInvalid)
+	}
+	if q.MinSalary != nil && q.MaxSalary != nil && *q.MaxSalary < *q.MinSalary {
+		return domain.EmployeePage{}, fmt.Errorf("%w: max_salary is below min_salary", util.ErrInvalid)
+	}
+	if req.Cursor != "" {
+		c, err := domain.ParseEmployeeCursor(req.Cursor)
+		if err != nil {
+			return domain.EmployeePage{}, fmt.Errorf("%w: malformed cursor", util.ErrInvalid)
+		}
+		if sort := cmp.Or(req.Sort, domain.EmployeeSortID); c.Sort != sort {
+			return domain.EmployeePage{}, fmt.Errorf("%w: cursor belongs to sort %q, not %q", util.ErrInvalid, c.Sort, sort)
+		}
+		q.After = &c
+	}
+	return s.repository.Page(ctx, q)
 }
 
 func (s employeeService) Create(ctx context.Context, req request.CreateEmployeeRequest) (domain.Employee, error) {
+	var created domain.Employee
+	err := s.transactor.WithinTx(ctx, func(tx *sql.Tx) error {
+		var err error
+		created, err = s.withTx(tx).create(ctx, req)
+		return err
+	})
+	if err != nil {
+		return domain.Employee{}, err
+	}
+	return created, nil
+}
+
+// create inserts the employee with their starting salary as the first
+// record of the salary history.
+func (s employeeService) create(ctx context.Context, req request.CreateEmployeeRequest) (domain.Employee, error) {
 	e := domain.Employee{
 		Code:       req.Code,
 		FullName:   req.FullName,
 		Email:      req.Email,
+		Department: strings.TrimSpace(req.Department),
+		Religion:   req.Religion,
 		BaseSalary: req.BaseSalary,
 		Allowance:  req.Allowance,
+		PTKPStatus: req.PTKPStatus,
+		NPWP:       req.NPWP,
 		HireDate:   req.HireDate,
 	}
+	if e.PTKPStatus == "" {
+		e.PTKPStatus = defaultPTKPStatus
+	}
 
-	return s.repository.Create(ctx, e)
+	created, err := s.repository.Create(ctx, e)
+	if err != nil {
+		return domain.Employee{}, err
+	}
+	_, err = s.salaryRepository.Create(ctx, domain.SalaryRecord{
+		EmployeeID:    created.ID,
+		BaseSalary:    e.BaseSalary,
+		Allowance:     e.Allowance,
+		EffectiveFrom: e.HireDate,
+		Reason:        initialSalaryReason,
+	})
+	if err != nil {
+		return domain.Employee{}, err
+	}
+	if err := recordAudit(ctx, s.auditRepository, domain.AuditActionCreate, domain.AuditEntityEmployee, created.ID, nil, created); err != nil {
+		return domain.Employee{}, err
+	}
+	return created, nil
 }
 
 func (s employeeService) GetByID(ctx context.Context, id int64) (domain.Employee, error) {
 	return s.repository.GetByID(ctx, id)
 }
 
+// Update changes an employee's details. A new base salary or allowance is
+// not written over the old one but recorded in the salary history as a
+// change effective today, correcting the change already made today if any.
 func (s employeeService) Update(ctx context.Context, id int64, req request.UpdateEmployeeRequest) (domain.Employee, error) {
+	var updated domain.Employee
+	err := s.transactor.WithinTx(ctx, func(tx *sql.Tx) error {
+		var err error
+		updated, err = s.withTx(tx).update(ctx, id, req)
+		return err
+	})
+	if err != nil {
+		return domain.Employee{}, err
+	}
+	return updated, nil
+}
+
+func (s employeeService) update(ctx context.Context, id int64, req request.UpdateEmployeeRequest) (domain.Employee, error) {
 	current, err := s.repository.GetByID(ctx, id)
 
 	if err != nil {
 		return domain.Employee{}, err
 	}
+	if current.Deleted() {
+		return domain.Employee{}, util.ErrNotFound
+	}
+	if err := requireEmploymentUnchanged(current, req); err != nil {
+		return domain.Employee{}, err
+	}
+	before := current
 	if req.FullName != nil {
 		current.FullName = *req.FullName
 	}
 	if req.Email != nil {
 		current.Email = *req.Email
 	}
+	if req.Department != nil {
+		current.Department = strings.TrimSpace(*req.Department)
+	}
+	if req.Religion != nil {
+		current.Religion = *req.Religion
+	}
+	salaryChanged := req.BaseSalary != nil || req.Allowance != nil
 	if req.BaseSalary != nil {
 		current.BaseSalary = *req.BaseSalary
 	}
 	if req.Allowance != nil {
 		current.Allowance = *req.Allowance
 	}
+	if req.PTKPStatus != nil {
+		current.PTKPStatus = *req.PTKPStatus
+	}
+	if req.NPWP != nil {
+		current.NPWP = *req.NPWP
+	}
 	if req.HireDate != nil {
 		current.HireDate = *req.HireDate
 	}
-	if req.IsActive != nil {
-		current.IsActive = *req.IsActive
+	if current.TerminationDate != nil && current.TerminationDate.Before(current.HireDate) {
+		return domain.Employee{}, fmt.Errorf("%w: termination_date is before hire_date", util.ErrInvalid)
+	}
+
+	if salaryChanged {
+		rec := domain.SalaryRecord{
+			EmployeeID:    id,
+			BaseSalary:    current.BaseSalary,
+			Allowance:     current.Allowance,
+			EffectiveFrom: today(),
+			Reason:        updateSalaryReason,
+			ApprovedBy:    actorFrom(ctx),
+		}
+		history, err := s.salaryRepository.ListByEmployee(ctx, id)
+		if err != nil {
+			return domain.Employee{}, err
+		}
+		for i, h := range history {
+			if h.EffectiveFrom.Equal(rec.EffectiveFrom) {
+				rec.ID = h.ID
+				history = append(history[:i:i], history[i+1:]...)
+				break
+			}
+		}
+		if err := checkSalaryChange(current, history, rec); err != nil {
+			return domain.Employee{}, err
+		}
+		if rec.ID != 0 {
+			err = s.salaryRepository.Update(ctx, rec)
+		} else {
+			_, err = s.salaryRepository.Create(ctx, rec)
+		}
+		if err != nil {
+			return domain.Employee{}, err
+		}
+	}
+
+	updated, err := s.repository.Update(ctx, current)
+	if err != nil {
+		return domain.Employee{}, err
+	}
+	if err := recordAudit(ctx, s.auditRepository, domain.AuditActionUpdate, domain.AuditEntityEmployee, id, before, updated); err != nil {
+		return domain.Employee{}, err
+	}
+	return updated, nil
+}
+
+// Terminate records the employee's last working day. They stay in payroll
+// runs up to the period containing it, prorated to that day, and then drop
+// out; with FinalPay false they are left out of that last period's run too.
+func (s employeeService) Terminate(ctx context.Context, id int64, req request.TerminateEmployeeRequest) (domain.Employee, error) {
+	reason := strings.TrimSpace(req.Reason)
+	if reason == "" {
+		return domain.Employee{}, fmt.Errorf("%w: reason is required", util.ErrInvalid)
 	}
 
-	return s.repository.Update(ctx, current)
+	var terminated domain.Employee
+	err := s.transactor.WithinTx(ctx, func(tx *sql.Tx) error {
+		txs := s.withTx(tx)
+		current, err := txs.repository.GetByID(ctx, id)
+		if err != nil {
+			return err
+		}
+		if current.Deleted() {
+			return util.ErrNotFound
+		}
+		if current.TerminationDate != nil {
+			return fmt.Errorf("%w: employee is already terminated", util.ErrInvalid)
+		}
+		if req.TerminationDate.Before(current.HireDate) {
+			return fmt.Errorf("%w: termination_date is before hire_date", util.ErrInvalid)
+		}
+
+		before := current
+		date := req.TerminationDate
+		current.TerminationDate = &date
+		current.TerminationReason = reason
+		current.FinalPay = req.FinalPay == nil || *req.FinalPay
+		if _, err := txs.repository.Update(ctx, current); err != nil {
+			return err
+		}
+		// Re-read for IsActive, which turns false from the day after date.
+		if terminated, err = txs.repository.GetByID(ctx, id); err != nil {
+			return err
+		}
+		return recordAudit(ctx, txs.auditRepository, domain.AuditActionTerminate, domain.AuditEntityEmployee, id, before, terminated)
+	})
+	if err != nil {
+		return domain.Employee{}, err
+	}
+	return terminated, nil
+}
+
+// requireEmploymentUnchanged rejects an update that would end or resume
+// employment, which only Terminate does. Sending back the current values is
+// allowed, so a client may echo the employee it read.
+func requireEmploymentUnchanged(current domain.Employee, req request.UpdateEmployeeRequest) error {
+	if req.IsActive != nil && *req.IsActive != current.IsActive {
+		return fmt.Errorf("%w: is_active cannot be updated, terminate the employee with POST /employees/:id/terminate", util.ErrInvalid)
+	}
+	if t := req.TerminationDate; t != nil && (current.TerminationDate == nil || !t.Equal(*current.TerminationDate)) {
+		return fmt.Errorf("%w: termination_date cannot be updated, terminate the employee with POST /employees/:id/terminate", util.ErrInvalid)
+	}
+	return nil
 }
 
 func (s employeeService) Delete(ctx context.Context, id int64) error {
-	return s.repository.Delete(ctx, id)
+	return s.transactor.WithinTx(ctx, func(tx *sql.Tx) error {
+		txs := s.withTx(tx)
+		before, err := txs.repository.GetByID(ctx, id)
+		if err != nil {
+			return err
+		}
+		if err := txs.repository.Delete(ctx, id); err != nil {
+			return err
+		}
+		after, err := txs.repository.GetByID(ctx, id)
+		if err != nil {
+			return err
+		}
+		return recordAudit(ctx, txs.auditRepository, domain.AuditActionDelete, domain.AuditEntityEmployee, id, before, after)
+	})
 }
 
-func NewEmployeeService(repository repository.EmployeeRepository) EmployeeService {
-	return &employeeService{repository: repository}
+func NewEmployeeService(repository repository.EmployeeRepository, salaryRepository repository.SalaryRepository,
+	auditRepository repository.AuditRepository, transactor repository.Transactor) EmployeeService {
+	return &employeeService{
+		repository:       repository,
+		salaryRepository: salaryRepository,
+		auditRepository:  auditRepository,
+		transactor:       transactor,
+	}
 }
@@ -0,0 +1,38 @@
+package service
+
+import (
+	"errors"
+	"go-payroll-service/internal/payroll/model/domain"
+	"go-payroll-service/internal/payroll/model/request"
+	"go-payroll-service/internal/payroll/util"
+	"testing"
+)
+
+func TestRequireEmploymentUnchanged(t *testing.T) {
+	left := date("2024-06-20")
+	other := date("2024-06-21")
+	active := domain.Employee{IsActive: true}
+	terminated := domain.Employee{TerminationDate: &left}
+	yes, no := true, false
+	tests := []struct {
+		name    string
+		current domain.Employee
+		req     request.UpdateEmployeeRequest
+		wantErr bool
+	}{
+		{"neither sent", active, request.UpdateEmployeeRequest{}, false},
+		{"current values echoed", terminated, request.UpdateEmployeeRequest{IsActive: &no, TerminationDate: &left}, false},
+		{"deactivated", active, request.UpdateEmployeeRequest{IsActive: &no}, true},
+		{"reactivated", terminated, request.UpdateEmployeeRequest{IsActive: &yes}, true},
+		{"termination date set", active, request.UpdateEmployeeRequest{TerminationDate: &left}, true},
+		{"termination date moved", terminated, request.UpdateEmployeeRequest{TerminationDate: &other}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := requireEmploymentUnchanged(tt.current, tt.req)
+			if tt.wantErr != (err != nil) || (err != nil && !errors.Is(err, util.ErrInvalid)) {
+				t.Errorf("requireEmploymentUnchanged() error = %v, want error %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,438 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"go-payroll-service/internal/payroll/leave"
+	"go-payroll-service/internal/payroll/model/domain"
+	"go-payroll-service/internal/payroll/model/request"
+	"go-payroll-service/internal/payroll/repository"
+	"go-payroll-service/internal/payroll/util"
+	"regexp"
+	"time"
+)
+
+var leaveTypeCodeExpr = regexp.MustCompile(`^[A-Z][A-Z0-9_]{0,49}$`)
+
+type LeaveService interface {
+	ListTypes(ctx context.Context) ([]domain.LeaveType, error)
+	CreateType(ctx context.Context, req request.CreateLeaveTypeRequest) (domain.LeaveType, error)
+	UpdateType(ctx context.Context, id int64, req request.UpdateLeaveTypeRequest) (domain.LeaveType, error)
+
+	ListRequests(ctx context.Context, req request.ListLeaveRequestsRequest) ([]domain.LeaveRequest, error)
+	// Request files a pending leave request for the employee.
+	Request(ctx context.Context, employeeID int64, req request.CreateLeaveRequest) (domain.LeaveRequest, error)
+	Approve(ctx context.Context, id int64, req request.DecideLeaveRequest) (domain.LeaveRequest, error)
+	Reject(ctx context.Context, id int64, req request.DecideLeaveRequest) (domain.LeaveRequest, error)
+	// Cancel withdraws the employee's request, if it is pending or approved
+	// leave that has not started.
+	Cancel(ctx context.Context, employeeID, id int64) (domain.LeaveRequest, error)
+	// Balances returns the employee's balance of every active type for
+	// year, as of today or the nearest day of the year.
+	Balances(ctx context.Context, employeeID int64, year int) ([]domain.LeaveBalance, error)
+}
+
+type leaveService struct {
+	repository         repository.LeaveRepository
+	employeeRepository repository.EmployeeRepository
+	periodRepository   repository.PeriodRepository
+	runRepository      repository.RunRepository
+	auditRepository    repository.AuditRepository
+	transactor         repository.Transactor
+	workWeek           int
+}
+
+func (s leaveService) withTx(tx *sql.Tx) leaveService {
+	s.repository = s.repository.WithTx(tx)
+	s.employeeRepository = s.employeeRepository.WithTx(tx)
+	s.periodRepository = s.periodRepository.WithTx(tx)
+	s.runRepository = s.runRepository.WithTx(tx)
+	s.auditRepository = s.auditRepository.WithTx(tx)
+	return s
+}
+
+func (s leaveService) ListTypes(ctx context.Context) ([]domain.LeaveType, error) {
+	return s.repository.ListTypes(ctx)
+}
+
+func (s leaveService) CreateType(ctx context.Context, req request.CreateLeaveTypeRequest) (domain.LeaveType, error) {
+	if !leaveTypeCodeExpr.MatchString(req.Code) {
+		return domain.LeaveType{}, fmt.Errorf("%w: code must be upper case letters, digits and underscores", util.ErrInvalid)
+	}
+	if _, err := s.repository.GetTypeByCode(ctx, req.Code); err == nil {
+		return domain.LeaveType{}, fmt.Errorf("%w: leave type %s already exists", util.ErrInvalid, req.Code)
+	}
+
+	t := domain.LeaveType{
+		Code:                  req.Code,
+		Name:                  req.Name,
+		Paid:                  true,
+		AnnualDays:            req.AnnualDays,
+		EligibleAfterMonths:   req.EligibleAfterMonths,
+		CarryOverDays:         req.CarryOverDays,
+		CarryOverExpiryMonths: req.CarryOverExpiryMonths,
+	}
+	if req.Paid != nil {
+		t.Paid = *req.Paid
+	}
+
+	var created domain.LeaveType
+	err := s.transactor.WithinTx(ctx, func(tx *sql.Tx) error {
+		txs := s.withTx(tx)
+		var err error
+		if created, err = txs.repository.CreateType(ctx, t); err != nil {
+			return err
+		}
+		return recordAudit(ctx, txs.auditRepository, domain.AuditActionCreate, domain.AuditEntityLeaveType, created.ID, nil, created)
+	})
+	if err != nil {
+		return domain.LeaveType{}, err
+	}
+	return created, nil
+}
+
+// UpdateType changes a type. Balances follow the new policy, for past
+// years too, as they are worked out from it.
+func (s leaveService) UpdateType(ctx context.Context, id int64, req request.UpdateLeaveTypeRequest) (domain.LeaveType, error) {
+	current, err := s.repository.GetType(ctx, id)
+	if err != nil {
+		return domain.LeaveType{}, err
+	}
+	before := current
+	if req.Name != nil {
+		current.Name = *req.Name
+	}
+	if req.Paid != nil {
+		current.Paid = *req.Paid
+	}
+	if req.AnnualDays != nil {
+		current.AnnualDays = *req.AnnualDays
+	}
+	if req.EligibleAfterMonths != nil {
+		current.EligibleAfterMonths = *req.EligibleAfterMonths
+	}
+	if req.CarryOverDays != nil {
+		current.CarryOverDays = *req.CarryOverDays
+	}
+	if req.CarryOverExpiryMonths != nil {
+		current.CarryOverExpiryMonths = *req.CarryOverExpiryMonths
+	}
+	if req.IsActive != nil {
+		current.IsActive = *req.IsActive
+	}
+
+	var updated domain.LeaveType
+	err = s.transactor.WithinTx(ctx, func(tx *sql.Tx) error {
+		txs := s.withTx(tx)
+		var err error
+		if updated, err = txs.repository.UpdateType(ctx, current); err != nil {
+			return err
+		}
+		return recordAudit(ctx, txs.auditRepository, domain.AuditActionUpdate, domain.AuditEntityLeaveType, id, before, updated)
+	})
+	if err != nil {
+		return domain.LeaveType{}, err
+	}
+	return updated, nil
+}
+
+func (s leaveService) ListRequests(ctx context.Context, req request.ListLeaveRequestsRequest) ([]domain.LeaveRequest, error) {
+	f := domain.LeaveRequestFilter{EmployeeID: req.EmployeeID, Status: req.Status}
+	for _, d := range []struct {
+		value string
+		dst   **time.Time
+	}{{req.From, &f.From}, {req.To, &f.To}} {
+		if d.value == "" {
+			continue
+		}
+		t, err := time.Parse(dateLayout, d.value)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %w", util.ErrInvalid, err)
+		}
+		*d.dst = &t
+	}
+	return s.repository.ListRequests(ctx, f)
+}
+
+func (s leaveService) Request(ctx context.Context, employeeID int64, req request.CreateLeaveRequest) (domain.LeaveRequest, error) {
+	e, err := s.employeeRepository.GetByID(ctx, employeeID)
+	if err != nil {
+		return domain.LeaveRequest{}, err
+	}
+	if e.Deleted() {
+		return domain.LeaveRequest{}, util.ErrNotFound
+	}
+	t, err := s.repository.GetTypeByCode(ctx, req.LeaveType)
+	if err != nil {
+		return domain.LeaveRequest{}, err
+	}
+	if !t.IsActive {
+		return domain.LeaveRequest{}, fmt.Errorf("%w: leave type %s is not active", util.ErrInvalid, t.Code)
+	}
+
+	start, err := time.Parse(dateLayout, req.StartDate)
+	if err != nil {
+		return domain.LeaveRequest{}, fmt.Errorf("%w: start_date: %w", util.ErrInvalid, err)
+	}
+	end, err := time.Parse(dateLayout, req.EndDate)
+	if err != nil {
+		return domain.LeaveRequest{}, fmt.Errorf("%w: end_date: %w", util.ErrInvalid, err)
+	}
+	switch {
+	case end.Before(start):
+		return domain.LeaveRequest{}, fmt.Errorf("%w: end_date is before start_date", util.ErrInvalid)
+	case start.Year() != end.Year():
+		return domain.LeaveRequest{}, fmt.Errorf("%w: leave cannot span two years, request each year separately", util.ErrInvalid)
+	case start.Before(e.HireDate) || (e.TerminationDate != nil && end.After(*e.TerminationDate)):
+		return domain.LeaveRequest{}, fmt.Errorf("%w: leave falls outside the employment", util.ErrInvalid)
+	}
+	lr := domain.LeaveRequest{
+		EmployeeID:  employeeID,
+		LeaveTypeID: t.ID,
+		StartDate:   start,
+		EndDate:     end,
+		Days:        leave.Days(s.workWeek, start, end),
+		Reason:      req.Reason,
+		Status:      domain.LeaveStatusPending,
+		RequestedBy: actorFrom(ctx),
+		LeaveType:   t,
+	}
+	if lr.Days == 0 {
+		return domain.LeaveRequest{}, fmt.Errorf("%w: leave covers no working day", util.ErrInvalid)
+	}
+
+	var created domain.LeaveRequest
+	err = s.transactor.WithinTx(ctx, func(tx *sql.Tx) error {
+		txs := s.withTx(tx)
+		if err := txs.checkRequest(ctx, e, lr); err != nil {
+			return err
+		}
+		var err error
+		if created, err = txs.repository.CreateRequest(ctx, lr); err != nil {
+			return err
+		}
+		return recordAudit(ctx, txs.auditRepository, domain.AuditActionCreate, domain.AuditEntityLeaveRequest, created.ID, nil, created)
+	})
+	if err != nil {
+		return domain.LeaveRequest{}, err
+	}
+	return created, nil
+}
+
+// Approve grants a pending request after checking it again, as the
+// balance may have been spent since it was filed.
+func (s leaveService) Approve(ctx context.Context, id int64, req request.DecideLeaveRequest) (domain.LeaveRequest, error) {
+	return s.decide(ctx, id, domain.AuditActionApprove, func(txs leaveService, lr domain.LeaveRequest) (domain.LeaveRequest, error) {
+		if lr.Status != domain.LeaveStatusPending {
+			return lr, fmt.Errorf("%w: request is %s", util.ErrTransition, lr.Status)
+		}
+		e, err := txs.employeeRepository.GetByID(ctx, lr.EmployeeID)
+		if err != nil {
+			return lr, err
+		}
+		if err := txs.checkRequest(ctx, e, lr); err != nil {
+			return lr, err
+		}
+		if !lr.LeaveType.Paid {
+			if err := txs.requireUnpaidDeductible(ctx, lr); err != nil {
+				return lr, err
+			}
+		}
+		return withDecision(ctx, lr, domain.LeaveStatusApproved, req.Note), nil
+	})
+}
+
+func (s leaveService) Reject(ctx context.Context, id int64, req request.DecideLeaveRequest) (domain.LeaveRequest, error) {
+	return s.decide(ctx, id, domain.AuditActionReject, func(_ leaveService, lr domain.LeaveRequest) (domain.LeaveRequest, error) {
+		if lr.Status != domain.LeaveStatusPending {
+			return lr, fmt.Errorf("%w: request is %s", util.ErrTransition, lr.Status)
+		}
+		return withDecision(ctx, lr, domain.LeaveStatusRejected, req.Note), nil
+	})
+}
+
+func (s leaveService) Cancel(ctx context.Context, employeeID, id int64) (domain.LeaveRequest, error) {
+	return s.decide(ctx, id, domain.AuditActionCancel, func(txs leaveService, lr domain.LeaveRequest) (domain.LeaveRequest, error) {
+		if lr.EmployeeID != employeeID {
+			return lr, util.ErrNotFound
+		}
+		switch {
+		case lr.Status == domain.LeaveStatusPending:
+		case lr.Status == domain.LeaveStatusApproved && lr.StartDate.After(today()):
+			if !lr.LeaveType.Paid {
+				if err := txs.requireUnpaidDeductible(ctx, lr); err != nil {
+					return lr, err
+				}
+			}
+		default:
+			return lr, fmt.Errorf("%w: request is %s", util.ErrTransition, lr.Status)
+		}
+		return withDecision(ctx, lr, domain.LeaveStatusCancelled, ""), nil
+	})
+}
+
+// decide moves request id to the status change returns, holding the
+// employee's lock.
+func (s leaveService) decide(ctx context.Context, id int64, action string,
+	change func(txs leaveService, lr domain.LeaveRequest) (domain.LeaveRequest, error)) (domain.LeaveRequest, error) {
+	var updated domain.LeaveRequest
+	err := s.transactor.WithinTx(ctx, func(tx *sql.Tx) error {
+		txs := s.withTx(tx)
+		lr, err := txs.repository.GetRequest(ctx, id)
+		if err != nil {
+			return err
+		}
+		if err := txs.repository.LockEmployee(ctx, lr.EmployeeID); err != nil {
+			return err
+		}
+		// Read again under the lock, in case it was decided meanwhile.
+		if lr, err = txs.repository.GetRequest(ctx, id); err != nil {
+			return err
+		}
+		next, err := change(txs, lr)
+		if err != nil {
+			return err
+		}
+		if updated, err = txs.repository.UpdateRequestStatus(ctx, next); err != nil {
+			return err
+		}
+		return recordAudit(ctx, txs.auditRepository, action, domain.AuditEntityLeaveRequest, id, lr, updated)
+	})
+	if err != nil {
+		return domain.LeaveRequest{}, err
+	}
+	return updated, nil
+}
+
+func withDecision(ctx context.Context, lr domain.LeaveRequest, status, note string) domain.LeaveRequest {
+	now := time.Now()
+	lr.Status = status
+	lr.DecidedBy = actorFrom(ctx)
+	lr.DecisionNote = note
+	lr.DecidedAt = &now
+	return lr
+}
+
+// checkRequest checks lr against the employee's other open requests: it
+// must not overlap any, and the balance as of its first day must cover it.
+// It locks the employee until the transaction ends.
+func (s leaveService) checkRequest(ctx context.Context, e domain.Employee, lr domain.LeaveRequest) error {
+	if err := s.repository.LockEmployee(ctx, e.ID); err != nil {
+		return err
+	}
+	existing, err := s.repository.ListRequests(ctx, domain.LeaveRequestFilter{EmployeeID: e.ID})
+	if err != nil {
+		return err
+	}
+
+	var sameType []domain.LeaveRequest
+	for _, other := range existing {
+		if other.ID == lr.ID || !other.Open() {
+			continue
+		}
+		if other.Overlaps(lr.StartDate, lr.EndDate) {
+			return fmt.Errorf("%w: overlaps %s leave from %s to %s", util.ErrInvalid,
+				other.LeaveType.Code, other.StartDate.Format(dateLayout), other.EndDate.Format(dateLayout))
+		}
+		if other.LeaveTypeID == lr.LeaveTypeID {
+			sameType = append(sameType, other)
+		}
+	}
+
+	if !lr.LeaveType.Tracked() {
+		return nil
+	}
+	b := leave.Balance(lr.LeaveType, e.HireDate, sameType, lr.StartDate.Year(), lr.StartDate)
+	if available := b.Available(); lr.Days > available {
+		return fmt.Errorf("%w: %d days of %s requested, %d available", util.ErrInvalid, lr.Days, lr.LeaveType.Code, max(available, 0))
+	}
+	return nil
+}
+
+// requireUnpaidDeductible checks that the payslips deducting the unpaid
+// leave can still change: no period it falls in may be locked or closed,
+// nor have a run paying the month submitted or approved, whose figures
+// would no longer match the leave.
+func (s leaveService) requireUnpaidDeductible(ctx context.Context, lr domain.LeaveRequest) error {
+	periods, err := s.periodRepository.List(ctx)
+	if err != nil {
+		return err
+	}
+	for _, p := range periods {
+		if !lr.Overlaps(p.StartDate, p.EndDate) {
+			continue
+		}
+		switch p.Status {
+		case domain.PeriodStatusClosed:
+			return fmt.Errorf("%w: unpaid leave falls in closed period %s", util.ErrPeriodClosed, p.Code)
+		case domain.PeriodStatusLocked:
+			return fmt.Errorf("%w: unpaid leave falls in locked period %s", util.ErrPeriodNotOpen, p.Code)
+		}
+		for _, runType := range []string{domain.RunTypeRegular, domain.RunTypeFinalPay} {
+			run, err := s.runRepository.GetForUpdate(ctx, p.ID, runType)
+			if errors.Is(err, util.ErrNotFound) {
+				continue
+			}
+			if err != nil {
+				return err
+			}
+			if run.Status == domain.RunStatusSubmitted || run.Status == domain.RunStatusApproved {
+				return fmt.Errorf("%w: the %s run of period %s is %s; reject it before changing unpaid leave in it",
+					util.ErrTransition, runType, p.Code, run.Status)
+			}
+		}
+	}
+	return nil
+}
+
+func (s leaveService) Balances(ctx context.Context, employeeID int64, year int) ([]domain.LeaveBalance, error) {
+	e, err := s.employeeRepository.GetByID(ctx, employeeID)
+	if err != nil {
+		return nil, err
+	}
+	types, err := s.repository.ListTypes(ctx)
+	if err != nil {
+		return nil, err
+	}
+	requests, err := s.repository.ListRequests(ctx, domain.LeaveRequestFilter{EmployeeID: employeeID})
+	if err != nil {
+		return nil, err
+	}
+	byType := map[int64][]domain.LeaveRequest{}
+	for _, lr := range requests {
+		byType[lr.LeaveTypeID] = append(byType[lr.LeaveTypeID], lr)
+	}
+
+	// Today, within the year asked for.
+	asOf := today()
+	if yearStart := time.Date(year, time.January, 1, 0, 0, 0, 0, time.UTC); asOf.Before(yearStart) {
+		asOf = yearStart
+	}
+	if yearEnd := time.Date(year, time.December, 31, 0, 0, 0, 0, time.UTC); asOf.After(yearEnd) {
+		asOf = yearEnd
+	}
+	var balances []domain.LeaveBalance
+	for _, t := range types {
+		if !t.IsActive {
+			continue
+		}
+		balances = append(balances, leave.Balance(t, e.HireDate, byType[t.ID], year, asOf))
+	}
+	return balances, nil
+}
+
+func NewLeaveService(repository repository.LeaveRepository, employeeRepository repository.EmployeeRepository,
+	periodRepository repository.PeriodRepository, runRepository repository.RunRepository,
+	auditRepository repository.AuditRepository, transactor repository.Transactor, workWeek int) LeaveService {
+	return &leaveService{
+		repository:         repository,
+		employeeRepository: employeeRepository,
+		periodRepository:   periodRepository,
+		runRepository:      runRepository,
+		auditRepository:    auditRepository,
+		transactor:         transactor,
+		workWeek:           workWeek,
+	}
+}
@@ -0,0 +1,137 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"go-payroll-service/internal/payroll/ledger"
+	"go-payroll-service/internal/payroll/model/domain"
+	"go-payroll-service/internal/payroll/model/request"
+	"go-payroll-service/internal/payroll/repository"
+	"go-payroll-service/internal/payroll/util"
+	"regexp"
+)
+
+var glCodeExpr = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9_]{0,49}$`)
+
+type LedgerService interface {
+	ListMappings(ctx context.Context) ([]domain.GLMapping, error)
+	SaveMapping(ctx context.Context, code string, req request.SaveGLMappingRequest) (domain.GLMapping, error)
+	DeleteMapping(ctx context.Context, code string) error
+	// Journal builds the general ledger journal of a closed period.
+	Journal(ctx context.Context, periodCode string) (ledger.Journal, error)
+}
+
+type ledgerService struct {
+	repository        repository.GLMappingRepository
+	periodRepository  repository.PeriodRepository
+	payrollRepository repository.PayrollRepository
+	auditRepository   repository.AuditRepository
+	transactor        repository.Transactor
+}
+
+func (s ledgerService) withTx(tx *sql.Tx) ledgerService {
+	s.repository = s.repository.WithTx(tx)
+	s.auditRepository = s.auditRepository.WithTx(tx)
+	return s
+}
+
+func (s ledgerService) ListMappings(ctx context.Context) ([]domain.GLMapping, error) {
+	return s.repository.List(ctx)
+}
+
+func (s ledgerService) SaveMapping(ctx context.Context, code string, req request.SaveGLMappingRequest) (domain.GLMapping, error) {
+	if !glCodeExpr.MatchString(code) {
+		return domain.GLMapping{}, fmt.Errorf("%w: code must be a payslip line code", util.ErrInvalid)
+	}
+	if req.ExpenseAccount == "" && req.PayableAccount == "" {
+		return domain.GLMapping{}, fmt.Errorf("%w: expense_account or payable_account is required", util.ErrInvalid)
+	}
+
+	var saved domain.GLMapping
+	err := s.transactor.WithinTx(ctx, func(tx *sql.Tx) error {
+		txs := s.withTx(tx)
+		// A mapping saved for the first time is recorded as created.
+		var before any
+		action := domain.AuditActionCreate
+		existing, err := txs.repository.GetByCode(ctx, code)
+		switch {
+		case err == nil:
+			before, action = existing, domain.AuditActionUpdate
+		case !errors.Is(err, util.ErrNotFound):
+			return err
+		}
+		saved, err = txs.repository.Upsert(ctx, domain.GLMapping{
+			Code:           code,
+			Description:    req.Description,
+			ExpenseAccount: req.ExpenseAccount,
+			PayableAccount: req.PayableAccount,
+			CostCenter:     req.CostCenter,
+		})
+		if err != nil {
+			return err
+		}
+		return recordAudit(ctx, txs.auditRepository, action, domain.AuditEntityGLMapping, code, before, saved)
+	})
+	if err != nil {
+		return domain.GLMapping{}, err
+	}
+	return saved, nil
+}
+
+func (s ledgerService) DeleteMapping(ctx context.Context, code string) error {
+	return s.transactor.WithinTx(ctx, func(tx *sql.Tx) error {
+		txs := s.withTx(tx)
+		before, err := txs.repository.GetByCode(ctx, code)
+		if err != nil {
+			return err
+		}
+		if err := txs.repository.Delete(ctx, code); err != nil {
+			return err
+		}
+		return recordAudit(ctx, txs.auditRepository, domain.AuditActionDelete, domain.AuditEntityGLMapping, code, before, nil)
+	})
+}
+
+// Journal is dated at the end of the period, when the salaries are owed.
+func (s ledgerService) Journal(ctx context.Context, periodCode string) (ledger.Journal, error) {
+	period, err := s.periodRepository.GetByCode(ctx, periodCode)
+	if err != nil {
+		return ledger.Journal{}, err
+	}
+	if period.Status != domain.PeriodStatusClosed {
+		return ledger.Journal{}, fmt.Errorf("%w: period %s is %s", util.ErrPeriodNotClosed, period.Code, period.Status)
+	}
+
+	list, err := s.repository.List(ctx)
+	if err != nil {
+		return ledger.Journal{}, err
+	}
+	mappings := map[string]domain.GLMapping{}
+	for _, m := range list {
+		mappings[m.Code] = m
+	}
+
+	rows, err := s.payrollRepository.ListPayslipByPeriodCode(ctx, periodCode, "")
+	if err != nil {
+		return ledger.Journal{}, err
+	}
+	payslips := make([]domain.Payslip, len(rows))
+	for i, p := range rows {
+		payslips[i] = p.Payslip
+	}
+	return ledger.Build(period.Code, period.EndDate, mappings, payslips)
+}
+
+func NewLedgerService(repository repository.GLMappingRepository, periodRepository repository.PeriodRepository,
+	payrollRepository repository.PayrollRepository, auditRepository repository.AuditRepository,
+	transactor repository.Transactor) LedgerService {
+	return &ledgerService{
+		repository:        repository,
+		periodRepository:  periodRepository,
+		payrollRepository: payrollRepository,
+		auditRepository:   auditRepository,
+		transactor:        transactor,
+	}
+}
@@ -0,0 +1,214 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"go-payroll-service/internal/payroll/loan"
+	"go-payroll-service/internal/payroll/model/domain"
+	"go-payroll-service/internal/payroll/model/request"
+	"go-payroll-service/internal/payroll/repository"
+	"go-payroll-service/internal/payroll/util"
+	"strings"
+)
+
+type LoanService interface {
+	List(ctx context.Context, employeeID int64) ([]domain.LoanBalance, error)
+	// Get returns the loan's balance with the installments deducted so far.
+	Get(ctx context.Context, id int64) (domain.LoanBalance, error)
+	Create(ctx context.Context, employeeID int64, req request.CreateLoanRequest) (domain.LoanBalance, error)
+	PayOff(ctx context.Context, id int64, req request.PayOffLoanRequest) (domain.LoanBalance, error)
+}
+
+type loanService struct {
+	repository         repository.LoanRepository
+	employeeRepository repository.EmployeeRepository
+	periodRepository   repository.PeriodRepository
+	auditRepository    repository.AuditRepository
+	transactor         repository.Transactor
+}
+
+func (s loanService) withTx(tx *sql.Tx) loanService {
+	s.repository = s.repository.WithTx(tx)
+	s.employeeRepository = s.employeeRepository.WithTx(tx)
+	s.periodRepository = s.periodRepository.WithTx(tx)
+	s.auditRepository = s.auditRepository.WithTx(tx)
+	return s
+}
+
+func (s loanService) List(ctx context.Context, employeeID int64) ([]domain.LoanBalance, error) {
+	if _, err := s.employeeRepository.GetByID(ctx, employeeID); err != nil {
+		return nil, err
+	}
+	loans, err := s.repository.ListByEmployee(ctx, employeeID)
+	if err != nil {
+		return nil, err
+	}
+	result := make([]domain.LoanBalance, 0, len(loans))
+	for _, l := range loans {
+		result = append(result, loanBalance(l))
+	}
+	return result, nil
+}
+
+func (s loanService) Get(ctx context.Context, id int64) (domain.LoanBalance, error) {
+	l, err := s.repository.GetByID(ctx, id)
+	if err != nil {
+		return domain.LoanBalance{}, err
+	}
+	b := loanBalance(l)
+	if b.Repayments, err = s.repository.ListRepayments(ctx, id); err != nil {
+		return domain.LoanBalance{}, err
+	}
+	return b, nil
+}
+
+// Create records a loan the employee has been paid. Its first installment
+// is deducted in the start period, which must still be open to change.
+func (s loanService) Create(ctx context.Context, employeeID int64, req request.CreateLoanRequest) (domain.LoanBalance, error) {
+	e, err := s.employeeRepository.GetByID(ctx, employeeID)
+	if err != nil {
+		return domain.LoanBalance{}, err
+	}
+	if e.Deleted() {
+		return domain.LoanBalance{}, util.ErrNotFound
+	}
+	period, err := s.changeablePeriod(ctx, req.StartPeriodCode)
+	if err != nil {
+		return domain.LoanBalance{}, err
+	}
+	if e.TerminationDate != nil && e.TerminationDate.Before(period.StartDate) {
+		return domain.LoanBalance{}, fmt.Errorf("%w: employee left before period %s", util.ErrInvalid, period.Code)
+	}
+
+	var saved domain.Loan
+	err = s.transactor.WithinTx(ctx, func(tx *sql.Tx) error {
+		txs := s.withTx(tx)
+		var err error
+		saved, err = txs.repository.Create(ctx, domain.Loan{
+			EmployeeID:      employeeID,
+			Kind:            req.Kind,
+			Principal:       req.Principal,
+			InterestRateBP:  req.InterestRateBP,
+			Installments:    req.Installments,
+			StartPeriodCode: period.Code,
+			Note:            strings.TrimSpace(req.Note),
+		})
+		if err != nil {
+			return err
+		}
+		return recordAudit(ctx, txs.auditRepository, domain.AuditActionCreate, domain.AuditEntityLoan, saved.ID, nil, saved)
+	})
+	if err != nil {
+		return domain.LoanBalance{}, err
+	}
+	return loanBalance(saved), nil
+}
+
+// PayOff settles the rest of the loan early from the payoff period on.
+// Installments deducted before it stand; payslips of the payoff period or
+// later that already deduct one must be generated without it first, or
+// the employee would repay it twice.
+func (s loanService) PayOff(ctx context.Context, id int64, req request.PayOffLoanRequest) (domain.LoanBalance, error) {
+	var b domain.LoanBalance
+	err := s.transactor.WithinTx(ctx, func(tx *sql.Tx) error {
+		txs := s.withTx(tx)
+		before, err := txs.repository.GetByID(ctx, id)
+		if err != nil {
+			return err
+		}
+		if before.PayoffPeriodCode != nil {
+			return fmt.Errorf("%w: loan was paid off in %s", util.ErrTransition, *before.PayoffPeriodCode)
+		}
+		period, err := txs.changeablePeriod(ctx, req.PeriodCode)
+		if err != nil {
+			return err
+		}
+		start, err := txs.periodRepository.GetByCode(ctx, before.StartPeriodCode)
+		if err != nil {
+			return err
+		}
+		if period.StartDate.Before(start.StartDate) {
+			return fmt.Errorf("%w: period %s is before the loan's start period %s", util.ErrInvalid, period.Code, start.Code)
+		}
+		repayments, err := txs.repository.ListRepayments(ctx, id)
+		if err != nil {
+			return err
+		}
+		for _, rp := range repayments {
+			paidIn, err := txs.periodRepository.GetByCode(ctx, rp.PeriodCode)
+			if err != nil {
+				return err
+			}
+			if !paidIn.StartDate.Before(period.StartDate) {
+				return fmt.Errorf("%w: payslip %d of period %s already deducts an installment; pay the loan off from a later period",
+					util.ErrTransition, rp.PayslipID, rp.PeriodCode)
+			}
+		}
+
+		if err := txs.repository.SetPayoff(ctx, id, period.Code); err != nil {
+			return err
+		}
+		after, err := txs.repository.GetByID(ctx, id)
+		if err != nil {
+			return err
+		}
+		if b = loanBalance(after); b.PaidOff == 0 {
+			return fmt.Errorf("%w: loan was repaid before %s", util.ErrTransition, period.Code)
+		}
+		return recordAudit(ctx, txs.auditRepository, domain.AuditActionPayOff, domain.AuditEntityLoan, id, before, after)
+	})
+	if err != nil {
+		return domain.LoanBalance{}, err
+	}
+	return b, nil
+}
+
+// changeablePeriod returns the period if its payslips may still change,
+// so installments can be deducted in it or dropped from it.
+func (s loanService) changeablePeriod(ctx context.Context, code string) (domain.PayrollPeriod, error) {
+	period, err := s.periodRepository.GetByCode(ctx, code)
+	if err != nil {
+		return domain.PayrollPeriod{}, err
+	}
+	switch period.Status {
+	case domain.PeriodStatusClosed:
+		return domain.PayrollPeriod{}, util.ErrPeriodClosed
+	case domain.PeriodStatusLocked:
+		return domain.PayrollPeriod{}, fmt.Errorf("%w: period %s is %s", util.ErrPeriodNotOpen, period.Code, period.Status)
+	}
+	return period, nil
+}
+
+// loanBalance works out where the loan stands from what payslips repaid.
+func loanBalance(l domain.Loan) domain.LoanBalance {
+	b := domain.LoanBalance{
+		Loan:   l,
+		Total:  loan.Total(l.Principal, l.InterestRateBP, l.Installments),
+		Status: domain.LoanStatusActive,
+	}
+	b.Installment = loan.Installment(b.Total, l.Installments)
+	b.Outstanding = max(b.Total-l.Repaid, 0)
+	switch {
+	case l.PayoffPeriodCode != nil:
+		b.PaidOff, b.Outstanding = b.Outstanding, 0
+		b.Status = domain.LoanStatusPaidOff
+	case b.Outstanding == 0:
+		b.Status = domain.LoanStatusRepaid
+	default:
+		b.Schedule = loan.Schedule(b.Total, l.Installments, l.Repaid)
+	}
+	return b
+}
+
+func NewLoanService(repository repository.LoanRepository, employeeRepository repository.EmployeeRepository,
+	periodRepository repository.PeriodRepository, auditRepository repository.AuditRepository,
+	transactor repository.Transactor) LoanService {
+	return &loanService{
+		repository:         repository,
+		employeeRepository: employeeRepository,
+		periodRepository:   periodRepository,
+		auditRepository:    auditRepository,
+		transactor:         transactor,
+	}
+}
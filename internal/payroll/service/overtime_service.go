@@ -0,0 +1,171 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"go-payroll-service/internal/payroll/model/domain"
+	"go-payroll-service/internal/payroll/model/request"
+	"go-payroll-service/internal/payroll/overtime"
+	"go-payroll-service/internal/payroll/repository"
+	"go-payroll-service/internal/payroll/util"
+	"time"
+)
+
+type OvertimeService interface {
+	// List returns the employee's overtime, of one period if periodCode is
+	// set.
+	List(ctx context.Context, employeeID int64, periodCode string) ([]domain.OvertimeEntry, error)
+	Record(ctx context.Context, employeeID int64, req request.RecordOvertimeRequest) (domain.OvertimeEntry, error)
+	Delete(ctx context.Context, employeeID, entryID int64) error
+}
+
+type overtimeService struct {
+	repository         repository.OvertimeRepository
+	employeeRepository repository.EmployeeRepository
+	periodRepository   repository.PeriodRepository
+	auditRepository    repository.AuditRepository
+	transactor         repository.Transactor
+	workWeek           int
+}
+
+func (s overtimeService) withTx(tx *sql.Tx) overtimeService {
+	s.repository = s.repository.WithTx(tx)
+	s.employeeRepository = s.employeeRepository.WithTx(tx)
+	s.periodRepository = s.periodRepository.WithTx(tx)
+	s.auditRepository = s.auditRepository.WithTx(tx)
+	return s
+}
+
+func (s overtimeService) List(ctx context.Context, employeeID int64, periodCode string) ([]domain.OvertimeEntry, error) {
+	if _, err := s.employeeRepository.GetByID(ctx, employeeID); err != nil {
+		return nil, err
+	}
+	return s.repository.ListByEmployee(ctx, employeeID, periodCode)
+}
+
+// Record sets the overtime of one day. The day may be before the period,
+// for overtime worked after the previous period's cutoff, but not after it.
+func (s overtimeService) Record(ctx context.Context, employeeID int64, req request.RecordOvertimeRequest) (domain.OvertimeEntry, error) {
+	e, err := s.employeeRepository.GetByID(ctx, employeeID)
+	if err != nil {
+		return domain.OvertimeEntry{}, err
+	}
+	if e.Deleted() {
+		return domain.OvertimeEntry{}, util.ErrNotFound
+	}
+	period, err := s.periodRepository.GetByCode(ctx, req.PeriodCode)
+	if err != nil {
+		return domain.OvertimeEntry{}, err
+	}
+	if period.Status == domain.PeriodStatusClosed {
+		return domain.OvertimeEntry{}, util.ErrPeriodClosed
+	}
+
+	day, err := time.Parse(dateLayout, req.WorkDate)
+	if err != nil {
+		return domain.OvertimeEntry{}, fmt.Errorf("%w: work_date: %w", util.ErrInvalid, err)
+	}
+	if day.After(period.EndDate) {
+		return domain.OvertimeEntry{}, fmt.Errorf("%w: work_date is after the end of period %s", util.ErrInvalid, period.Code)
+	}
+	if !e.EmployedDuring(day, day) {
+		return domain.OvertimeEntry{}, fmt.Errorf("%w: employee was not employed on %s", util.ErrInvalid, req.WorkDate)
+	}
+	limit, err := overtime.MaxMinutes(s.workWeek, req.DayType)
+	if err != nil {
+		return domain.OvertimeEntry{}, fmt.Errorf("%w: %w", util.ErrInvalid, err)
+	}
+	if req.Minutes > limit {
+		return domain.OvertimeEntry{}, fmt.Errorf("%w: at most %d minutes of overtime on a %s", util.ErrInvalid, limit, req.DayType)
+	}
+
+	var saved domain.OvertimeEntry
+	err = s.transactor.WithinTx(ctx, func(tx *sql.Tx) error {
+		txs := s.withTx(tx)
+		if err := txs.requireNotClosed(ctx, period.Code); err != nil {
+			return err
+		}
+		recorded, err := txs.repository.ListByEmployee(ctx, employeeID, "")
+		if err != nil {
+			return err
+		}
+		var before any
+		action := domain.AuditActionCreate
+		for _, o := range recorded {
+			if !o.WorkDate.Equal(day) {
+				continue
+			}
+			// Moving a day out of a closed period would change what was
+			// paid.
+			if o.PeriodCode != period.Code {
+				if err := txs.requireNotClosed(ctx, o.PeriodCode); err != nil {
+					return err
+				}
+			}
+			before, action = o, domain.AuditActionUpdate
+		}
+		saved, err = txs.repository.Upsert(ctx, domain.OvertimeEntry{
+			EmployeeID: employeeID,
+			PeriodCode: period.Code,
+			WorkDate:   day,
+			DayType:    req.DayType,
+			Minutes:    req.Minutes,
+			Note:       req.Note,
+		})
+		if err != nil {
+			return err
+		}
+		return recordAudit(ctx, txs.auditRepository, action, domain.AuditEntityOvertime, saved.ID, before, saved)
+	})
+	if err != nil {
+		return domain.OvertimeEntry{}, err
+	}
+	return saved, nil
+}
+
+func (s overtimeService) Delete(ctx context.Context, employeeID, entryID int64) error {
+	return s.transactor.WithinTx(ctx, func(tx *sql.Tx) error {
+		txs := s.withTx(tx)
+		o, err := txs.repository.GetByID(ctx, entryID)
+		if err != nil {
+			return err
+		}
+		if o.EmployeeID != employeeID {
+			return util.ErrNotFound
+		}
+		if err := txs.requireNotClosed(ctx, o.PeriodCode); err != nil {
+			return err
+		}
+		if err := txs.repository.Delete(ctx, entryID); err != nil {
+			return err
+		}
+		return recordAudit(ctx, txs.auditRepository, domain.AuditActionDelete, domain.AuditEntityOvertime, entryID, o, nil)
+	})
+}
+
+// requireNotClosed checks that the overtime paid in the period may still
+// change, locking the period so it cannot close before the transaction ends.
+func (s overtimeService) requireNotClosed(ctx context.Context, periodCode string) error {
+	period, err := s.periodRepository.GetByCodeForUpdate(ctx, periodCode)
+	if err != nil {
+		return err
+	}
+	if period.Status == domain.PeriodStatusClosed {
+		return util.ErrPeriodClosed
+	}
+	return nil
+}
+
+func NewOvertimeService(repository repository.OvertimeRepository, employeeRepository repository.EmployeeRepository,
+	periodRepository repository.PeriodRepository, auditRepository repository.AuditRepository,
+	transactor repository.Transactor, workWeek int) OvertimeService {
+	return &overtimeService{
+		repository:         repository,
+		employeeRepository: employeeRepository,
+		periodRepository:   periodRepository,
+		auditRepository:    auditRepository,
+		transactor:         transactor,
+		workWeek:           workWeek,
+	}
+}
@@ -2,72 +2,1084 @@ package service
 
 import (
 	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"go-payroll-service/internal/payroll/bpjs"
+	"go-payroll-service/internal/payroll/loan"
 	"go-payroll-service/internal/payroll/model/domain"
 	"go-payroll-service/internal/payroll/model/request"
+	"go-payroll-service/internal/payroll/overtime"
+	"go-payroll-service/internal/payroll/proration"
 	repository2 "go-payroll-service/internal/payroll/repository"
+	"go-payroll-service/internal/payroll/tax"
+	"go-payroll-service/internal/payroll/thr"
+	"go-payroll-service/internal/payroll/util"
+	"slices"
+	"strings"
 	"time"
 )
 
+const (
+	lineCodeBasic     = "BASIC"
+	lineCodeAllowance = "ALLOWANCE"
+	lineCodeOvertime  = "OVERTIME"
+	lineCodeUnpaid    = "UNPAID_LEAVE"
+	lineCodeTHR       = "THR"
+	lineCodeLoan      = "LOAN"
+	lineCodePPh21     = "PPH21"
+)
+
+// prorationServiceMonths is the proration method of THR payslips, which pay
+// for months of service rather than days of the period.
+const prorationServiceMonths = "service_months"
+
 type PayrollService interface {
-	GeneratePayroll(ctx context.Context, req request.GeneratePayrollRequest) (int, error)
-	ListPayslips(ctx context.Context, periodCode string) ([]domain.PayslipWithEmployee, error)
+	GeneratePayroll(ctx context.Context, req request.GeneratePayrollRequest) (domain.PayrollRunSummary, error)
+	// GenerateTHR calculates the THR payslips of the period for a religious
+	// holiday.
+	GenerateTHR(ctx context.Context, req request.GenerateTHRRequest) (domain.PayrollRunSummary, error)
+	// GenerateOffCycle calculates the payslips of an off-cycle run of the
+	// period.
+	GenerateOffCycle(ctx context.Context, req request.GenerateOffCycleRequest) (domain.PayrollRunSummary, error)
+	// ListPayslips lists the period's payslips of runType, or of every run
+	// type if it is empty.
+	ListPayslips(ctx context.Context, periodCode, runType string) ([]domain.PayslipWithEmployee, error)
+	// ListEmployeePayslips lists the employee's published payslips, those of
+	// approved runs.
+	ListEmployeePayslips(ctx context.Context, employeeID int64) ([]domain.PayslipWithEmployee, error)
+	// PayslipYTD totals the employee's published payslips of every period of
+	// the year.
+	PayslipYTD(ctx context.Context, employeeID int64, year int) (domain.PayslipYTD, error)
+	RecordAttendance(ctx context.Context, req request.RecordAttendanceRequest) (domain.Attendance, error)
 }
 
 type payrollService struct {
-	employeeRepository repository2.EmployeeRepository
-	payrollRepository  repository2.PayrollRepository
+	employeeRepository   repository2.EmployeeRepository
+	payrollRepository    repository2.PayrollRepository
+	periodRepository     repository2.PeriodRepository
+	taxRepository        repository2.TaxRepository
+	bpjsRepository       repository2.BPJSRepository
+	componentRepository  repository2.ComponentRepository
+	attendanceRepository repository2.AttendanceRepository
+	salaryRepository     repository2.SalaryRepository
+	overtimeRepository   repository2.OvertimeRepository
+	leaveRepository      repository2.LeaveRepository
+	loanRepository       repository2.LoanRepository
+	runRepository        repository2.RunRepository
+	auditRepository      repository2.AuditRepository
+	transactor           repository2.Transactor
+	prorationMethod      string
+	overtimeWorkWeek     int
 }
 
-func (s payrollService) GeneratePayroll(ctx context.Context, req request.GeneratePayrollRequest) (int, error) {
-	periodCode := req.PeriodCode
+// withTx returns a copy of the service whose repositories all run in tx.
+func (s payrollService) withTx(tx *sql.Tx) payrollService {
+	s.employeeRepository = s.employeeRepository.WithTx(tx)
+	s.payrollRepository = s.payrollRepository.WithTx(tx)
+	s.periodRepository = s.periodRepository.WithTx(tx)
+	s.taxRepository = s.taxRepository.WithTx(tx)
+	s.bpjsRepository = s.bpjsRepository.WithTx(tx)
+	s.componentRepository = s.componentRepository.WithTx(tx)
+	s.attendanceRepository = s.attendanceRepository.WithTx(tx)
+	s.salaryRepository = s.salaryRepository.WithTx(tx)
+	s.overtimeRepository = s.overtimeRepository.WithTx(tx)
+	s.leaveRepository = s.leaveRepository.WithTx(tx)
+	s.loanRepository = s.loanRepository.WithTx(tx)
+	s.runRepository = s.runRepository.WithTx(tx)
+	s.auditRepository = s.auditRepository.WithTx(tx)
+	return s
+}
 
-	//example the period is 30 days
-	start := time.Now().AddDate(0, 0, -30)
-	end := time.Now()
+// payrollRun holds everything a generation loads once per period before
+// calculating each employee.
+type payrollRun struct {
+	period     domain.PayrollPeriod
+	rates      domain.TaxRates
+	programs   []domain.BPJSProgram
+	catalog    []domain.PayComponent
+	assigned   map[int64][]domain.EmployeeComponent
+	attendance map[int64]domain.Attendance
+	salaries   map[int64][]domain.SalaryRecord
+	overtime   map[int64][]domain.OvertimeEntry
+	unpaid     map[int64][]domain.LeaveRequest
+	loans      map[int64][]domain.Loan
+}
 
-	period, err := s.payrollRepository.GetOrCreatePeriod(ctx, periodCode, start, end)
+// GeneratePayroll calculates a draft payslip for every employee who worked in
+// the period; salary is prorated for employees hired or terminated inside it. It
+// can be re-run for the same period: existing payslips are recalculated in
+// place, and payslips of employees no longer in the run are removed, until
+// the run is submitted; it must then be rejected to be generated again.
+// The whole run is one transaction; any failure leaves the period untouched.
+func (s payrollService) GeneratePayroll(ctx context.Context, req request.GeneratePayrollRequest) (domain.PayrollRunSummary, error) {
+	var summary domain.PayrollRunSummary
+	err := s.transactor.WithinTx(ctx, func(tx *sql.Tx) error {
+		var err error
+		txs := s.withTx(tx)
+		if summary, err = txs.generate(ctx, req); err != nil {
+			return err
+		}
+		return recordAudit(ctx, txs.auditRepository, domain.AuditActionGenerate, domain.AuditEntityPayroll, req.PeriodCode, nil, summary)
+	})
 	if err != nil {
-		return 0, err
+		return domain.PayrollRunSummary{}, err
+	}
+	return summary, nil
+}
+
+func (s payrollService) generate(ctx context.Context, req request.GeneratePayrollRequest) (domain.PayrollRunSummary, error) {
+	var summary domain.PayrollRunSummary
+	period, err := s.periodRepository.GetByCodeForUpdate(ctx, req.PeriodCode)
+	if err != nil {
+		return summary, err
+	}
+	if err := requireOpen(period); err != nil {
+		return summary, err
+	}
+
+	run, err := s.loadRun(ctx, period)
+	if err != nil {
+		return summary, err
 	}
 
 	employees, err := s.employeeRepository.List(ctx)
 	if err != nil {
-		return 0, err
+		return summary, err
+	}
+	var inPeriod []domain.Employee
+	for _, e := range employees {
+		if inRun(e, period) {
+			inPeriod = append(inPeriod, e)
+		}
+	}
+
+	return s.savePayslips(ctx, period, domain.RunTypeRegular, inPeriod, nil, func(e domain.Employee) (domain.Payslip, error) {
+		return s.calculatePayslip(ctx, run, e)
+	})
+}
+
+// savePayslips calculates the payslip of each employee for the period's
+// runType run and saves it, leaving unchanged payslips alone. Payslips of
+// the run whose employee was not calculated are removed when scope is nil
+// or accepts their employee. A run whose payslips changed goes back to
+// draft for approval. A submitted run is refused untouched until it is
+// rejected, and an approved one for good.
+func (s payrollService) savePayslips(ctx context.Context, period domain.PayrollPeriod, runType string, employees []domain.Employee,
+	scope func(employeeID int64) bool, calculate func(e domain.Employee) (domain.Payslip, error)) (domain.PayrollRunSummary, error) {
+	var summary domain.PayrollRunSummary
+	var run *domain.PayrollRun
+	current, err := s.runRepository.GetForUpdate(ctx, period.ID, runType)
+	switch {
+	case errors.Is(err, util.ErrNotFound):
+	case err != nil:
+		return summary, err
+	case current.Status == domain.RunStatusSubmitted || current.Status == domain.RunStatusApproved:
+		return summary, fmt.Errorf("%w: the %s run of period %s is %s; only a draft or rejected run can be generated again",
+			util.ErrTransition, runType, period.Code, current.Status)
+	default:
+		run = &current
+	}
+
+	existing, err := s.payrollRepository.ListPayslipByPeriodID(ctx, period.ID, runType)
+	if err != nil {
+		return summary, err
+	}
+	previous := map[int64]domain.Payslip{}
+	for _, p := range existing {
+		previous[p.EmployeeID] = p
 	}
 
-	count := 0
 	for _, e := range employees {
-		if !e.IsActive {
+		runErr := &util.PayrollRunError{PeriodCode: period.Code, EmployeeID: e.ID, EmployeeCode: e.Code}
+
+		p, err := calculate(e)
+		if err != nil {
+			runErr.Err = fmt.Errorf("%w: %w", util.ErrCalculation, err)
+			return summary, runErr
+		}
+
+		old, ok := previous[e.ID]
+		delete(previous, e.ID)
+		switch {
+		case !ok:
+			if _, err := s.payrollRepository.CreatePayslip(ctx, p); err != nil {
+				runErr.Err = err
+				return summary, runErr
+			}
+			summary.Created++
+		case old.SameFigures(p):
+			summary.Unchanged++
+		default:
+			p.ID = old.ID
+			if _, err := s.payrollRepository.UpdatePayslip(ctx, p); err != nil {
+				runErr.Err = err
+				return summary, runErr
+			}
+			summary.Updated++
+		}
+	}
+
+	for _, stale := range previous {
+		if scope != nil && !scope(stale.EmployeeID) {
 			continue
 		}
-		base := e.BaseSalary
-		allow := e.Allowance
-		deduction := int64(0) // this can be chance with pajak
-		net := base + allow - deduction
+		if err := s.payrollRepository.DeletePayslip(ctx, stale.ID); err != nil {
+			return summary, err
+		}
+		summary.Removed++
+	}
+	return summary, s.markGenerated(ctx, period, runType, run, len(existing)+summary.Created-summary.Removed, summary)
+}
 
-		p := domain.Payslip{
-			EmployeeID:      e.ID,
+// markGenerated records who generated the period's runType run, which now
+// holds count payslips; run is the existing one, nil if there is none, and
+// is never submitted or approved, as savePayslips refuses those. A new run
+// starts as a draft; a run whose payslips changed returns to draft, as its
+// approvals were for other figures. A run left without payslips has nothing
+// to approve and is removed.
+func (s payrollService) markGenerated(ctx context.Context, period domain.PayrollPeriod, runType string, run *domain.PayrollRun,
+	count int, summary domain.PayrollRunSummary) error {
+	actor := actorFrom(ctx)
+	from := domain.RunStatusDraft
+	switch {
+	case run == nil:
+		if count == 0 {
+			return nil
+		}
+		created, err := s.runRepository.Create(ctx, domain.PayrollRun{
 			PayrollPeriodID: period.ID,
-			BaseSalary:      base,
-			Allowance:       allow,
-			Deduction:       deduction,
-			NetSalary:       net,
+			RunType:         runType,
+			Status:          domain.RunStatusDraft,
+			GeneratedBy:     actor,
+		})
+		if err != nil {
+			return err
 		}
-		if _, err := s.payrollRepository.CreatePayslip(ctx, p); err != nil {
-			return count, err
+		run = &created
+	case run.Status == domain.RunStatusSubmitted || run.Status == domain.RunStatusApproved:
+		return fmt.Errorf("%w: the %s run of period %s is %s", util.ErrTransition, runType, period.Code, run.Status)
+	case count == 0:
+		return s.runRepository.Delete(ctx, run.ID)
+	case summary.Created+summary.Updated+summary.Removed == 0:
+		return nil
+	default:
+		from = run.Status
+		run.Status = domain.RunStatusDraft
+		run.GeneratedBy = actor
+		updated, err := s.runRepository.Update(ctx, *run)
+		if err != nil {
+			return err
 		}
-		count++
+		run = &updated
 	}
-	return count, nil
+	_, err := s.runRepository.AddEvent(ctx, domain.PayrollRunEvent{
+		PayrollRunID: run.ID,
+		Round:        run.Round,
+		Action:       domain.RunActionGenerate,
+		FromStatus:   from,
+		ToStatus:     domain.RunStatusDraft,
+		Actor:        actor,
+	})
+	return err
 }
 
-func (s payrollService) ListPayslips(ctx context.Context, periodCode string) ([]domain.PayslipWithEmployee, error) {
-	return s.payrollRepository.ListPayslipByPeriodCode(ctx, periodCode)
+// GenerateTHR pays the religious holiday allowance on holiday_date in the
+// period as a THR run beside the regular one, to the employees of the
+// selected religions, or to everyone if none are selected. The period has a
+// single THR run for all religions: it can be re-run, recalculating or
+// removing only THR payslips of the selected religions, until it is
+// submitted, like any run, so every religion's THR must be generated before
+// it is submitted.
+func (s payrollService) GenerateTHR(ctx context.Context, req request.GenerateTHRRequest) (domain.PayrollRunSummary, error) {
+	var summary domain.PayrollRunSummary
+	err := s.transactor.WithinTx(ctx, func(tx *sql.Tx) error {
+		var err error
+		txs := s.withTx(tx)
+		if summary, err = txs.generateTHR(ctx, req); err != nil {
+			return err
+		}
+		return recordAudit(ctx, txs.auditRepository, domain.AuditActionGenerate, domain.AuditEntityPayroll,
+			req.PeriodCode+"/"+domain.RunTypeTHR, nil, summary)
+	})
+	if err != nil {
+		return domain.PayrollRunSummary{}, err
+	}
+	return summary, nil
+}
+
+func (s payrollService) generateTHR(ctx context.Context, req request.GenerateTHRRequest) (domain.PayrollRunSummary, error) {
+	var summary domain.PayrollRunSummary
+	holiday, err := time.Parse(dateLayout, req.HolidayDate)
+	if err != nil {
+		return summary, fmt.Errorf("%w: holiday_date must be YYYY-MM-DD", util.ErrInvalid)
+	}
+	period, err := s.periodRepository.GetByCodeForUpdate(ctx, req.PeriodCode)
+	if err != nil {
+		return summary, err
+	}
+	if err := requireOpen(period); err != nil {
+		return summary, err
+	}
+	if due := holiday.AddDate(0, 0, -thr.DueDaysBefore); period.PayDate.After(due) {
+		return summary, fmt.Errorf("%w: period %s pays on %s, THR for a holiday on %s is due by %s",
+			util.ErrInvalid, period.Code, period.PayDate.Format(dateLayout), req.HolidayDate, due.Format(dateLayout))
+	}
+
+	rates, err := s.taxRepository.GetRates(ctx, period.EndDate.Year())
+	if err != nil {
+		return summary, fmt.Errorf("load tax rates for %d: %w", period.EndDate.Year(), err)
+	}
+	salaries, err := s.salaryRepository.ListEffective(ctx, holiday, holiday)
+	if err != nil {
+		return summary, err
+	}
+
+	employees, err := s.employeeRepository.List(ctx)
+	if err != nil {
+		return summary, err
+	}
+	selected := func(e domain.Employee) bool {
+		return len(req.Religions) == 0 || slices.Contains(req.Religions, e.Religion)
+	}
+	byID := map[int64]domain.Employee{}
+	var eligible []domain.Employee
+	for _, e := range employees {
+		byID[e.ID] = e
+		if selected(e) && !e.Deleted() && e.EmployedDuring(holiday, holiday) &&
+			monthsBetween(e.HireDate, holiday) >= thr.MinServiceMonths {
+			eligible = append(eligible, e)
+		}
+	}
+
+	scope := func(employeeID int64) bool {
+		e, ok := byID[employeeID]
+		return len(req.Religions) == 0 || ok && selected(e)
+	}
+	return s.savePayslips(ctx, period, domain.RunTypeTHR, eligible, scope, func(e domain.Employee) (domain.Payslip, error) {
+		history := salaries[e.ID]
+		if len(history) == 0 {
+			return domain.Payslip{}, fmt.Errorf("no salary in effect on %s", req.HolidayDate)
+		}
+		return s.calculateTHR(ctx, period, rates, e, rateOn(history, holiday), monthsBetween(e.HireDate, holiday))
+	})
+}
+
+// calculateTHR builds an employee's THR payslip without saving it. The
+// payslip's days record the months of service paid for out of a full year.
+func (s payrollService) calculateTHR(ctx context.Context, period domain.PayrollPeriod, rates domain.TaxRates, e domain.Employee,
+	rate domain.SalaryRecord, serviceMonths int) (domain.Payslip, error) {
+	amount, months := thr.Amount(rate.BaseSalary+rate.Allowance, serviceMonths)
+	p := domain.Payslip{
+		EmployeeID:      e.ID,
+		PayrollPeriodID: period.ID,
+		RunType:         domain.RunTypeTHR,
+		Status:          domain.PayslipStatusDraft,
+		ProrationMethod: prorationServiceMonths,
+		ProratedDays:    months,
+		PeriodDays:      thr.FullServiceMonths,
+	}
+	p.Lines = append(p.Lines, domain.PayslipLine{
+		Code:    lineCodeTHR,
+		Label:   "Tunjangan Hari Raya",
+		Type:    domain.LineTypeEarning,
+		Amount:  amount,
+		Taxable: true,
+		Source:  domain.LineSourceTHR,
+	})
+	if err := s.withholdTax(ctx, period, rates, e, &p); err != nil {
+		return domain.Payslip{}, err
+	}
+	return p, nil
+}
+
+// GenerateOffCycle pays a bonus, correction or final pay run of the period
+// beside the regular one: only the selected employees, and only the listed
+// components, evaluated with the employee's assignment if they have one and
+// the catalog formula otherwise. The request describes the whole run, so
+// re-running it removes the payslips of employees left out.
+//
+// A final pay run settles what the employee still owes on loans. For an
+// employee who left during the period without final pay in the regular
+// run, it also pays the month as the regular run would have.
+func (s payrollService) GenerateOffCycle(ctx context.Context, req request.GenerateOffCycleRequest) (domain.PayrollRunSummary, error) {
+	var summary domain.PayrollRunSummary
+	err := s.transactor.WithinTx(ctx, func(tx *sql.Tx) error {
+		var err error
+		txs := s.withTx(tx)
+		if summary, err = txs.generateOffCycle(ctx, req); err != nil {
+			return err
+		}
+		return recordAudit(ctx, txs.auditRepository, domain.AuditActionGenerate, domain.AuditEntityPayroll,
+			req.PeriodCode+"/"+req.RunType, nil, summary)
+	})
+	if err != nil {
+		return domain.PayrollRunSummary{}, err
+	}
+	return summary, nil
+}
+
+func (s payrollService) generateOffCycle(ctx context.Context, req request.GenerateOffCycleRequest) (domain.PayrollRunSummary, error) {
+	var summary domain.PayrollRunSummary
+	period, err := s.periodRepository.GetByCodeForUpdate(ctx, req.PeriodCode)
+	if err != nil {
+		return summary, err
+	}
+	if err := requireOpen(period); err != nil {
+		return summary, err
+	}
+
+	catalog, err := s.componentRepository.List(ctx)
+	if err != nil {
+		return summary, err
+	}
+	byCode := map[string]domain.PayComponent{}
+	for _, c := range catalog {
+		byCode[c.Code] = c
+	}
+	var problems []string
+	components := make([]domain.PayComponent, 0, len(req.Components))
+	for _, code := range req.Components {
+		c, ok := byCode[code]
+		switch {
+		case !ok || !c.IsActive:
+			problems = append(problems, fmt.Sprintf("component %s is unknown or inactive", code))
+		case slices.ContainsFunc(components, func(p domain.PayComponent) bool { return p.Code == code }):
+			problems = append(problems, fmt.Sprintf("component %s is listed twice", code))
+		default:
+			components = append(components, c)
+		}
+	}
+
+	employees, err := s.employeeRepository.List(ctx)
+	if err != nil {
+		return summary, err
+	}
+	byID := map[int64]domain.Employee{}
+	for _, e := range employees {
+		byID[e.ID] = e
+	}
+	selected := make([]domain.Employee, 0, len(req.Employees))
+	amounts := map[int64]map[string]int64{}
+	for _, sel := range req.Employees {
+		e, ok := byID[sel.EmployeeID]
+		if _, dup := amounts[sel.EmployeeID]; dup {
+			problems = append(problems, fmt.Sprintf("employee %d is listed twice", sel.EmployeeID))
+			continue
+		}
+		if problem := offCycleProblem(req.RunType, period, e, ok); problem != "" {
+			problems = append(problems, fmt.Sprintf("employee %d %s", sel.EmployeeID, problem))
+			continue
+		}
+		for code := range sel.Amounts {
+			if !slices.Contains(req.Components, code) {
+				problems = append(problems, fmt.Sprintf("employee %d has an amount for %s, which the run does not pay", e.ID, code))
+			}
+		}
+		selected = append(selected, e)
+		amounts[e.ID] = sel.Amounts
+	}
+	if len(problems) > 0 {
+		return summary, fmt.Errorf("%w: %s", util.ErrInvalid, strings.Join(problems, "; "))
+	}
+
+	rates, err := s.taxRepository.GetRates(ctx, period.EndDate.Year())
+	if err != nil {
+		return summary, fmt.Errorf("load tax rates for %d: %w", period.EndDate.Year(), err)
+	}
+	assignments, err := s.componentRepository.ListActiveAssignments(ctx)
+	if err != nil {
+		return summary, err
+	}
+	assigned := map[int64]map[string]domain.EmployeeComponent{}
+	for _, ec := range assignments {
+		if assigned[ec.EmployeeID] == nil {
+			assigned[ec.EmployeeID] = map[string]domain.EmployeeComponent{}
+		}
+		assigned[ec.EmployeeID][ec.Component.Code] = ec
+	}
+	attendance, err := s.attendanceRepository.ListByPeriodCode(ctx, period.Code)
+	if err != nil {
+		return summary, err
+	}
+	salaries, err := s.salaryRepository.ListEffective(ctx, period.StartDate, period.EndDate)
+	if err != nil {
+		return summary, err
+	}
+	var month payrollRun
+	if slices.ContainsFunc(selected, func(e domain.Employee) bool { return paysMonth(req.RunType, e, period) }) {
+		if month, err = s.loadRun(ctx, period); err != nil {
+			return summary, err
+		}
+	}
+
+	return s.savePayslips(ctx, period, req.RunType, selected, nil, func(e domain.Employee) (domain.Payslip, error) {
+		history := salaries[e.ID]
+		if len(history) == 0 {
+			return domain.Payslip{}, fmt.Errorf("no salary in effect during %s", period.Code)
+		}
+		var att *domain.Attendance
+		if a, ok := attendance[e.ID]; ok {
+			att = &a
+		}
+
+		run := make([]domain.EmployeeComponent, 0, len(components))
+		for _, c := range components {
+			ec, ok := assigned[e.ID][c.Code]
+			if !ok {
+				ec = domain.EmployeeComponent{EmployeeID: e.ID, ComponentID: c.ID, Component: c}
+			}
+			if amount, ok := amounts[e.ID][c.Code]; ok {
+				ec.AmountOverride = &amount
+			}
+			run = append(run, ec)
+		}
+		if paysMonth(req.RunType, e, period) {
+			return s.calculateFinalMonth(ctx, month, e, req.Components, run)
+		}
+		return s.calculateOffCycle(ctx, period, rates, req.RunType, e, history, catalog, run, att)
+	})
+}
+
+// paysMonth reports whether the off-cycle run pays the employee's month:
+// it is a final pay run and the employee left during the period without
+// their final pay in the regular run.
+func paysMonth(runType string, e domain.Employee, period domain.PayrollPeriod) bool {
+	return runType == domain.RunTypeFinalPay && leavesIn(e, period) && !e.FinalPay
+}
+
+// calculateFinalMonth builds the final payslip of an employee the regular
+// run left out: the month as the regular run calculates it, with the run's
+// components in place of the assignments of the same code, and the loans
+// settled instead of an installment deducted.
+func (s payrollService) calculateFinalMonth(ctx context.Context, run payrollRun, e domain.Employee, codes []string,
+	components []domain.EmployeeComponent) (domain.Payslip, error) {
+	for _, ec := range run.assigned[e.ID] {
+		if !slices.Contains(codes, ec.Component.Code) {
+			components = append(components, ec)
+		}
+	}
+	p, err := s.calculateMonth(run, e, domain.RunTypeFinalPay, components)
+	if err != nil {
+		return domain.Payslip{}, err
+	}
+	if err := s.withholdTax(ctx, run.period, run.rates, e, &p); err != nil {
+		return domain.Payslip{}, err
+	}
+	return p, s.settleOutstanding(ctx, run.period, e, &p)
+}
+
+// offCycleProblem says why e cannot be paid in an off-cycle run of the
+// period, or returns "". A final pay run may settle an employee who left
+// before the period; the other runs pay employees who worked in it.
+func offCycleProblem(runType string, period domain.PayrollPeriod, e domain.Employee, found bool) string {
+	switch {
+	case !found || e.Deleted():
+		return "does not exist"
+	case runType == domain.RunTypeFinalPay:
+		if e.TerminationDate == nil || e.TerminationDate.After(period.EndDate) {
+			return "has not left by the end of the period"
+		}
+	case !e.EmployedDuring(period.StartDate, period.EndDate):
+		return "was not employed during the period"
+	}
+	return ""
+}
+
+// calculateOffCycle builds an employee's off-cycle payslip from the run's
+// components without saving it. Formulas see the same variables as in the
+// regular run; a component reading one the run does not pay sees zero.
+func (s payrollService) calculateOffCycle(ctx context.Context, period domain.PayrollPeriod, rates domain.TaxRates, runType string,
+	e domain.Employee, history []domain.SalaryRecord, catalog []domain.PayComponent, components []domain.EmployeeComponent,
+	att *domain.Attendance) (domain.Payslip, error) {
+	last := period.EndDate
+	if e.TerminationDate != nil && e.TerminationDate.Before(last) {
+		last = *e.TerminationDate
+	}
+	rate := rateOn(history, last)
+	e.BaseSalary, e.Allowance = rate.BaseSalary, rate.Allowance
+
+	basic, _ := s.prorateSalary(period, e, history)
+	p := domain.Payslip{
+		EmployeeID:      e.ID,
+		PayrollPeriodID: period.ID,
+		RunType:         runType,
+		Status:          domain.PayslipStatusDraft,
+		ProrationMethod: basic.Method,
+		ProratedDays:    basic.Days,
+		PeriodDays:      basic.PeriodDays,
+	}
+	lines, err := evaluateComponents(catalog, components, employeeVariables(e, period, att, p.ProrationFactor()))
+	if err != nil {
+		return domain.Payslip{}, err
+	}
+	p.Lines = lines
+
+	if err := s.withholdTax(ctx, period, rates, e, &p); err != nil {
+		return domain.Payslip{}, err
+	}
+	if runType == domain.RunTypeFinalPay {
+		return p, s.settleOutstanding(ctx, period, e, &p)
+	}
+	return p, nil
+}
+
+// settleOutstanding deducts what the employee still owes on loans from
+// their final payslip.
+func (s payrollService) settleOutstanding(ctx context.Context, period domain.PayrollPeriod, e domain.Employee, p *domain.Payslip) error {
+	loans, err := s.loanRepository.ListOutstanding(ctx, e.ID, period, domain.RunTypeFinalPay)
+	if err != nil {
+		return err
+	}
+	settleLoans(p, loans)
+	return nil
+}
+
+func (s payrollService) loadRun(ctx context.Context, period domain.PayrollPeriod) (payrollRun, error) {
+	run := payrollRun{period: period}
+
+	rates, err := s.taxRepository.GetRates(ctx, period.EndDate.Year())
+	if err != nil {
+		return run, fmt.Errorf("load tax rates for %d: %w", period.EndDate.Year(), err)
+	}
+	run.rates = rates
+
+	if run.programs, err = s.bpjsRepository.ListPrograms(ctx, period.EndDate); err != nil {
+		return run, fmt.Errorf("load BPJS programs: %w", err)
+	}
+
+	if run.catalog, err = s.componentRepository.List(ctx); err != nil {
+		return run, err
+	}
+	assignments, err := s.componentRepository.ListActiveAssignments(ctx)
+	if err != nil {
+		return run, err
+	}
+	run.assigned = map[int64][]domain.EmployeeComponent{}
+	for _, ec := range assignments {
+		run.assigned[ec.EmployeeID] = append(run.assigned[ec.EmployeeID], ec)
+	}
+
+	if run.attendance, err = s.attendanceRepository.ListByPeriodCode(ctx, period.Code); err != nil {
+		return run, err
+	}
+	if run.unpaid, err = s.leaveRepository.ListApprovedUnpaid(ctx, period.StartDate, period.EndDate); err != nil {
+		return run, err
+	}
+	if run.loans, err = s.loanRepository.ListDue(ctx, period); err != nil {
+		return run, err
+	}
+	// Overtime paid in the period may have been worked before it started.
+	if run.overtime, err = s.overtimeRepository.ListByPeriodCode(ctx, period.Code); err != nil {
+		return run, err
+	}
+	from := period.StartDate
+	for _, entries := range run.overtime {
+		if entries[0].WorkDate.Before(from) {
+			from = entries[0].WorkDate
+		}
+	}
+	if run.salaries, err = s.salaryRepository.ListEffective(ctx, from, period.EndDate); err != nil {
+		return run, err
+	}
+	return run, nil
+}
+
+// calculatePayslip builds an employee's payslip for the run's period without
+// saving it.
+func (s payrollService) calculatePayslip(ctx context.Context, run payrollRun, e domain.Employee) (domain.Payslip, error) {
+	p, err := s.calculateMonth(run, e, domain.RunTypeRegular, run.assigned[e.ID])
+	if err != nil {
+		return domain.Payslip{}, err
+	}
+	if err := s.withholdTax(ctx, run.period, run.rates, e, &p); err != nil {
+		return domain.Payslip{}, err
+	}
+	deductLoans(&p, run.loans[e.ID])
+	return p, nil
+}
+
+// calculateMonth builds the lines of an employee's pay for the run's period
+// before tax and loans: salary, unpaid leave, overtime, the given components
+// and BPJS.
+func (s payrollService) calculateMonth(run payrollRun, e domain.Employee, runType string,
+	components []domain.EmployeeComponent) (domain.Payslip, error) {
+	history := run.salaries[e.ID]
+	if len(history) == 0 {
+		return domain.Payslip{}, fmt.Errorf("no salary in effect during %s", run.period.Code)
+	}
+	// Formulas and BPJS use the rate in effect on the employee's last day in
+	// the period.
+	last := run.period.EndDate
+	if e.TerminationDate != nil && e.TerminationDate.Before(last) {
+		last = *e.TerminationDate
+	}
+	rate := rateOn(history, last)
+	e.BaseSalary, e.Allowance = rate.BaseSalary, rate.Allowance
+
+	basic, allowance := s.prorateSalary(run.period, e, history)
+	p := domain.Payslip{
+		EmployeeID:      e.ID,
+		PayrollPeriodID: run.period.ID,
+		RunType:         runType,
+		Status:          domain.PayslipStatusDraft,
+		ProrationMethod: basic.Method,
+		ProratedDays:    basic.Days,
+		PeriodDays:      basic.PeriodDays,
+	}
+	p.Lines = append(p.Lines, domain.PayslipLine{
+		Code:    lineCodeBasic,
+		Label:   "Base salary",
+		Type:    domain.LineTypeEarning,
+		Amount:  basic.Amount,
+		Taxable: true,
+		Source:  domain.LineSourceSalary,
+	})
+	if allowance.Amount != 0 {
+		p.Lines = append(p.Lines, domain.PayslipLine{
+			Code:    lineCodeAllowance,
+			Label:   "Allowance",
+			Type:    domain.LineTypeEarning,
+			Amount:  allowance.Amount,
+			Taxable: true,
+			Source:  domain.LineSourceSalary,
+		})
+	}
+
+	if leaves := run.unpaid[e.ID]; len(leaves) > 0 {
+		p.Lines = append(p.Lines, domain.PayslipLine{
+			Code:    lineCodeUnpaid,
+			Label:   "Unpaid leave",
+			Type:    domain.LineTypeDeduction,
+			Amount:  s.unpaidLeave(run.period, e, leaves, basic.Amount+allowance.Amount),
+			Taxable: true,
+			Source:  domain.LineSourceLeave,
+		})
+	}
+
+	if entries := run.overtime[e.ID]; len(entries) > 0 {
+		days := make([]overtime.Day, 0, len(entries))
+		for _, o := range entries {
+			wage := rateOn(history, o.WorkDate)
+			days = append(days, overtime.Day{Type: o.DayType, Minutes: o.Minutes, MonthlyWage: wage.BaseSalary + wage.Allowance})
+		}
+		amount, err := overtime.Pay(s.overtimeWorkWeek, days)
+		if err != nil {
+			return domain.Payslip{}, err
+		}
+		p.Lines = append(p.Lines, domain.PayslipLine{
+			Code:    lineCodeOvertime,
+			Label:   "Overtime",
+			Type:    domain.LineTypeEarning,
+			Amount:  amount,
+			Taxable: true,
+			Source:  domain.LineSourceOvertime,
+		})
+	}
+
+	var att *domain.Attendance
+	if a, ok := run.attendance[e.ID]; ok {
+		att = &a
+	}
+	vars := employeeVariables(e, run.period, att, p.ProrationFactor())
+	componentLines, err := evaluateComponents(run.catalog, components, vars)
+	if err != nil {
+		return domain.Payslip{}, err
+	}
+	p.Lines = append(p.Lines, componentLines...)
+
+	// BPJS is contributed on the full monthly fixed wage, base salary plus
+	// fixed allowance, even in a prorated month.
+	p.Lines = append(p.Lines, bpjs.Contributions(run.programs, e.BaseSalary+e.Allowance)...)
+	return p, nil
+}
+
+// deductLoans deducts an installment of each loan from net pay, oldest loan
+// first. An installment that would leave net pay negative is skipped, which
+// moves the rest of that loan's schedule back a period.
+func deductLoans(p *domain.Payslip, loans []domain.Loan) {
+	for _, l := range loans {
+		due := loan.Due(loan.Total(l.Principal, l.InterestRateBP, l.Installments), l.Installments, l.Repaid)
+		if due == 0 || p.NetSalary() < due {
+			continue
+		}
+		p.Lines = append(p.Lines, loanLine(l, due))
+	}
+}
+
+// settleLoans deducts what is left of each loan from a final payslip, oldest
+// loan first, as far as net pay allows. Whatever net pay cannot cover stays
+// outstanding and is settled outside payroll.
+func settleLoans(p *domain.Payslip, loans []domain.Loan) {
+	for _, l := range loans {
+		due := min(loan.Total(l.Principal, l.InterestRateBP, l.Installments)-l.Repaid, p.NetSalary())
+		if due <= 0 {
+			continue
+		}
+		p.Lines = append(p.Lines, loanLine(l, due))
+	}
+}
+
+func loanLine(l domain.Loan, amount int64) domain.PayslipLine {
+	label := "Loan repayment"
+	if l.Kind == domain.LoanKindKasbon {
+		label = "Kasbon repayment"
+	}
+	return domain.PayslipLine{
+		Code:   lineCodeLoan,
+		Label:  label,
+		Type:   domain.LineTypeDeduction,
+		Amount: amount,
+		Source: domain.LineSourceLoan,
+		LoanID: &l.ID,
+	}
+}
+
+// withholdTax adds the PPh 21 of the payslip's taxable lines. The month is
+// taxed together with the employee's payslips of the other run types of the
+// period, so whichever is calculated last withholds what the others did not.
+func (s payrollService) withholdTax(ctx context.Context, period domain.PayrollPeriod, rates domain.TaxRates, e domain.Employee, p *domain.Payslip) error {
+	taxable, pension := taxBasis(p.Lines)
+
+	ytd, err := s.payrollRepository.GetTaxYTD(ctx, e.ID, period)
+	if err != nil {
+		return err
+	}
+	month, err := s.payrollRepository.GetTaxMonth(ctx, e.ID, period.ID, p.RunType)
+	if err != nil {
+		return err
+	}
+
+	pph21, err := tax.Calculate(rates, tax.Input{
+		PTKPStatus:          e.PTKPStatus,
+		HasNPWP:             e.NPWP != "",
+		GrossIncome:         taxable,
+		PensionContribution: pension,
+		Annualize:           period.EndDate.Month() == time.December || leavesIn(e, period),
+		YTD:                 ytd,
+		Month:               month,
+	})
+	if err != nil {
+		return err
+	}
+
+	p.TaxableIncome = taxable
+	p.IncomeTax = pph21.Tax
+	p.TaxMethod = pph21.Method
+	p.TaxLines = pph21.Lines
+	p.Lines = append(p.Lines, domain.PayslipLine{
+		Code:   lineCodePPh21,
+		Label:  "PPh 21",
+		Type:   domain.LineTypeDeduction,
+		Amount: pph21.Tax,
+		Source: domain.LineSourceTax,
+	})
+	return nil
+}
+
+// prorateSalary pays the base salary and allowance for the part of the
+// period the employee was employed, each salary record for the days it was
+// in effect.
+func (s payrollService) prorateSalary(period domain.PayrollPeriod, e domain.Employee, history []domain.SalaryRecord) (basic, allowance proration.Result) {
+	var basicSegs, allowanceSegs []proration.Segment
+	for i, rec := range history {
+		from := rec.EffectiveFrom
+		if from.Before(e.HireDate) {
+			from = e.HireDate
+		}
+		var to time.Time
+		if i+1 < len(history) {
+			to = history[i+1].EffectiveFrom.AddDate(0, 0, -1)
+		}
+		if e.TerminationDate != nil && (to.IsZero() || e.TerminationDate.Before(to)) {
+			to = *e.TerminationDate
+		}
+		basicSegs = append(basicSegs, proration.Segment{From: from, To: to, Amount: rec.BaseSalary})
+		allowanceSegs = append(allowanceSegs, proration.Segment{From: from, To: to, Amount: rec.Allowance})
+	}
+	basic = proration.Prorate(s.prorationMethod, period.StartDate, period.EndDate, basicSegs)
+	allowance = proration.Prorate(s.prorationMethod, period.StartDate, period.EndDate, allowanceSegs)
+	return basic, allowance
+}
+
+// unpaidLeave is the salary of the unpaid leave days inside the period,
+// counted as the proration method counts days and paid at the fixed wage
+// of the employee's last day. It is at most the salary paid, so leave
+// covering the whole period leaves nothing.
+func (s payrollService) unpaidLeave(period domain.PayrollPeriod, e domain.Employee, leaves []domain.LeaveRequest, paid int64) int64 {
+	segs := make([]proration.Segment, 0, len(leaves))
+	for _, lr := range leaves {
+		segs = append(segs, proration.Segment{From: lr.StartDate, To: lr.EndDate, Amount: e.BaseSalary + e.Allowance})
+	}
+	return min(proration.Prorate(s.prorationMethod, period.StartDate, period.EndDate, segs).Amount, paid)
+}
+
+// rateOn returns the record of history, sorted oldest first, in effect on
+// day, or the first record if none had started yet.
+func rateOn(history []domain.SalaryRecord, day time.Time) domain.SalaryRecord {
+	rate := history[0]
+	for _, rec := range history[1:] {
+		if rec.EffectiveFrom.After(day) {
+			break
+		}
+		rate = rec
+	}
+	return rate
+}
+
+// inRun reports whether the employee gets a payslip for the period: they
+// are not deleted, were employed for part of it, and are either active,
+// leave after it, or leave during it with their final pay in the regular
+// run.
+func inRun(e domain.Employee, period domain.PayrollPeriod) bool {
+	if e.Deleted() || !e.EmployedDuring(period.StartDate, period.EndDate) {
+		return false
+	}
+	if leavesIn(e, period) {
+		return e.FinalPay
+	}
+	// Someone who has since left is inactive today but worked through
+	// the period.
+	return e.IsActive || e.TerminationDate != nil
+}
+
+// leavesIn reports whether the employee's last day falls inside the period,
+// which makes it their final payslip of the tax year.
+func leavesIn(e domain.Employee, period domain.PayrollPeriod) bool {
+	t := e.TerminationDate
+	return t != nil && !t.Before(period.StartDate) && !t.After(period.EndDate)
+}
+
+// requireOpen checks that payslips of the period may still be changed.
+func requireOpen(period domain.PayrollPeriod) error {
+	switch period.Status {
+	case domain.PeriodStatusOpen:
+		return nil
+	case domain.PeriodStatusClosed:
+		return util.ErrPeriodClosed
+	default:
+		return fmt.Errorf("%w: period %s is %s", util.ErrPeriodNotOpen, period.Code, period.Status)
+	}
+}
+
+// taxBasis splits a payslip's taxable lines into the month's gross income for
+// PPh 21 and the employee's JHT/JP contributions that are deductible from it.
+// Unpaid leave is salary not earned, so it reduces the gross income instead.
+// Other deductions, such as dues or loan installments, are paid from net
+// income and do not lower the tax.
+func taxBasis(lines []domain.PayslipLine) (gross, deductible int64) {
+	for _, l := range lines {
+		if !l.Taxable {
+			continue
+		}
+		switch {
+		case l.Type == domain.LineTypeEarning, l.Type == domain.LineTypeEmployerContribution:
+			gross += l.Amount
+		case l.Source == domain.LineSourceLeave:
+			gross -= l.Amount
+		case l.Type == domain.LineTypeDeduction && l.Source == domain.LineSourceBPJS:
+			deductible += l.Amount
+		}
+	}
+	return gross, deductible
+}
+
+func (s payrollService) ListPayslips(ctx context.Context, periodCode, runType string) ([]domain.PayslipWithEmployee, error) {
+	return s.payrollRepository.ListPayslipByPeriodCode(ctx, periodCode, runType)
+}
+
+func (s payrollService) ListEmployeePayslips(ctx context.Context, employeeID int64) ([]domain.PayslipWithEmployee, error) {
+	list, err := s.payrollRepository.ListPayslipByEmployee(ctx, employeeID)
+	if err != nil {
+		return nil, err
+	}
+	var published []domain.PayslipWithEmployee
+	for _, p := range list {
+		if p.Published() {
+			published = append(published, p)
+		}
+	}
+	return published, nil
+}
+
+func (s payrollService) PayslipYTD(ctx context.Context, employeeID int64, year int) (domain.PayslipYTD, error) {
+	yearEnd := domain.PayrollPeriod{EndDate: time.Date(year, time.December, 31, 0, 0, 0, 0, time.UTC)}
+	return s.payrollRepository.GetPayslipYTD(ctx, employeeID, yearEnd, true)
+}
+
+func (s payrollService) RecordAttendance(ctx context.Context, req request.RecordAttendanceRequest) (domain.Attendance, error) {
+	period, err := s.periodRepository.GetByCode(ctx, req.PeriodCode)
+	if err != nil {
+		return domain.Attendance{}, err
+	}
+	if period.Status == domain.PeriodStatusClosed {
+		return domain.Attendance{}, util.ErrPeriodClosed
+	}
+	if _, err := s.employeeRepository.GetByID(ctx, req.EmployeeID); err != nil {
+		return domain.Attendance{}, err
+	}
+
+	var saved domain.Attendance
+	err = s.transactor.WithinTx(ctx, func(tx *sql.Tx) error {
+		txs := s.withTx(tx)
+		// Read again under lock, so the period cannot close meanwhile.
+		period, err := txs.periodRepository.GetByCodeForUpdate(ctx, req.PeriodCode)
+		if err != nil {
+			return err
+		}
+		if period.Status == domain.PeriodStatusClosed {
+			return util.ErrPeriodClosed
+		}
+		recorded, err := txs.attendanceRepository.ListByPeriodCode(ctx, req.PeriodCode)
+		if err != nil {
+			return err
+		}
+		var before any
+		action := domain.AuditActionCreate
+		if a, ok := recorded[req.EmployeeID]; ok {
+			before, action = a, domain.AuditActionUpdate
+		}
+		saved, err = txs.attendanceRepository.Upsert(ctx, domain.Attendance{
+			EmployeeID:  req.EmployeeID,
+			PeriodCode:  req.PeriodCode,
+			WorkingDays: req.WorkingDays,
+			DaysPresent: req.DaysPresent,
+		})
+		if err != nil {
+			return err
+		}
+		return recordAudit(ctx, txs.auditRepository, action, domain.AuditEntityAttendance,
+			fmt.Sprintf("%d/%s", req.EmployeeID, req.PeriodCode), before, saved)
+	})
+	if err != nil {
+		return domain.Attendance{}, err
+	}
+	return saved, nil
 }
 
-func NewPayrollService(employeeRepository repository2.EmployeeRepository, payrollRepository repository2.PayrollRepository) PayrollService {
+func NewPayrollService(employeeRepository repository2.EmployeeRepository, payrollRepository repository2.PayrollRepository,
+	periodRepository repository2.PeriodRepository, taxRepository repository2.TaxRepository, bpjsRepository repository2.BPJSRepository,
+	componentRepository repository2.ComponentRepository, attendanceRepository repository2.AttendanceRepository,
+	salaryRepository repository2.SalaryRepository, overtimeRepository repository2.OvertimeRepository,
+	leaveRepository repository2.LeaveRepository, loanRepository repository2.LoanRepository, runRepository repository2.RunRepository,
+	auditRepository repository2.AuditRepository,
+	transactor repository2.Transactor, prorationMethod string, overtimeWorkWeek int) PayrollService {
 	return &payrollService{
-		employeeRepository: employeeRepository,
-		payrollRepository:  payrollRepository,
+		employeeRepository:   employeeRepository,
+		payrollRepository:    payrollRepository,
+		periodRepository:     periodRepository,
+		taxRepository:        taxRepository,
+		bpjsRepository:       bpjsRepository,
+		componentRepository:  componentRepository,
+		attendanceRepository: attendanceRepository,
+		salaryRepository:     salaryRepository,
+		overtimeRepository:   overtimeRepository,
+		leaveRepository:      leaveRepository,
+		loanRepository:       loanRepository,
+		runRepository:        runRepository,
+		auditRepository:      auditRepository,
+		transactor:           transactor,
+		prorationMethod:      prorationMethod,
+		overtimeWorkWeek:     overtimeWorkWeek,
 	}
 }
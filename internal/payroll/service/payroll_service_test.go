@@ -0,0 +1,236 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"go-payroll-service/internal/payroll/model/domain"
+	"go-payroll-service/internal/payroll/proration"
+	"go-payroll-service/internal/payroll/repository"
+	"go-payroll-service/internal/payroll/util"
+	"testing"
+	"time"
+)
+
+func date(s string) time.Time {
+	d, err := time.Parse(dateLayout, s)
+	if err != nil {
+		panic(err)
+	}
+	return d
+}
+
+func TestProrateSalary(t *testing.T) {
+	june := domain.PayrollPeriod{StartDate: date("2024-06-01"), EndDate: date("2024-06-30")}
+	terminated := date("2024-06-20")
+	tests := []struct {
+		name          string
+		method        string
+		employee      domain.Employee
+		history       []domain.SalaryRecord
+		wantBasic     int64
+		wantAllowance int64
+		wantDays      int
+	}{
+		{
+			"whole month",
+			proration.CalendarDays,
+			domain.Employee{HireDate: date("2020-01-01")},
+			[]domain.SalaryRecord{{EffectiveFrom: date("2020-01-01"), BaseSalary: 10000000, Allowance: 2000000}},
+			10000000, 2000000, 30,
+		},
+		{
+			// The first record predates the hire date; pay starts on it.
+			"hired mid-month",
+			proration.CalendarDays,
+			domain.Employee{HireDate: date("2024-06-16")},
+			[]domain.SalaryRecord{{EffectiveFrom: date("2024-06-01"), BaseSalary: 10000000, Allowance: 2000000}},
+			5000000, 1000000, 15,
+		},
+		{
+			"terminated mid-month",
+			proration.CalendarDays,
+			domain.Employee{HireDate: date("2020-01-01"), TerminationDate: &terminated},
+			[]domain.SalaryRecord{{EffectiveFrom: date("2020-01-01"), BaseSalary: 9000000, Allowance: 3000000}},
+			6000000, 2000000, 20,
+		},
+		{
+			"raise mid-month",
+			proration.CalendarDays,
+			domain.Employee{HireDate: date("2020-01-01")},
+			[]domain.SalaryRecord{
+				{EffectiveFrom: date("2020-01-01"), BaseSalary: 10000000, Allowance: 2000000},
+				{EffectiveFrom: date("2024-06-16"), BaseSalary: 12000000, Allowance: 2000000},
+			},
+			11000000, 2000000, 30,
+		},
+		{
+			"raise after termination",
+			proration.CalendarDays,
+			domain.Employee{HireDate: date("2020-01-01"), TerminationDate: &terminated},
+			[]domain.SalaryRecord{
+				{EffectiveFrom: date("2020-01-01"), BaseSalary: 9000000},
+				{EffectiveFrom: date("2024-06-25"), BaseSalary: 12000000},
+			},
+			6000000, 0, 20,
+		},
+		{
+			"working days",
+			proration.WorkingDays,
+			domain.Employee{HireDate: date("2024-06-17")},
+			[]domain.SalaryRecord{{EffectiveFrom: date("2024-06-17"), BaseSalary: 10000000, Allowance: 2000000}},
+			5000000, 1000000, 10,
+		},
+		{
+			"fixed 30",
+			proration.Fixed30,
+			domain.Employee{HireDate: date("2020-01-01"), TerminationDate: &terminated},
+			[]domain.SalaryRecord{{EffectiveFrom: date("2020-01-01"), BaseSalary: 9000000, Allowance: 3000000}},
+			6000000, 2000000, 20,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := payrollService{prorationMethod: tt.method}
+			basic, allowance := s.prorateSalary(june, tt.employee, tt.history)
+			if basic.Amount != tt.wantBasic || allowance.Amount != tt.wantAllowance {
+				t.Errorf("prorateSalary() = %d, %d, want %d, %d", basic.Amount, allowance.Amount, tt.wantBasic, tt.wantAllowance)
+			}
+			if basic.Days != tt.wantDays {
+				t.Errorf("prorateSalary() days = %d, want %d", basic.Days, tt.wantDays)
+			}
+		})
+	}
+}
+
+func TestInRun(t *testing.T) {
+	june := domain.PayrollPeriod{StartDate: date("2024-06-01"), EndDate: date("2024-06-30")}
+	at := func(s string) *time.Time { d := date(s); return &d }
+	deleted := time.Now()
+	tests := []struct {
+		name     string
+		employee domain.Employee
+		want     bool
+	}{
+		{"active", domain.Employee{HireDate: date("2020-01-01"), IsActive: true}, true},
+		{"hired after the period", domain.Employee{HireDate: date("2024-07-01"), IsActive: true}, false},
+		{"deleted", domain.Employee{HireDate: date("2020-01-01"), DeletedAt: &deleted}, false},
+		{"left before the period", domain.Employee{HireDate: date("2020-01-01"), TerminationDate: at("2024-05-31")}, false},
+		{"leaves during it with final pay", domain.Employee{HireDate: date("2020-01-01"), TerminationDate: at("2024-06-20"), FinalPay: true}, true},
+		{"leaves during it, paid off-cycle", domain.Employee{HireDate: date("2020-01-01"), TerminationDate: at("2024-06-20")}, false},
+		// Inactive today, as their last day has passed, but employed all
+		// of June.
+		{"left after the period", domain.Employee{HireDate: date("2020-01-01"), TerminationDate: at("2024-07-15")}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := inRun(tt.employee, june); got != tt.want {
+				t.Errorf("inRun() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// fakeRuns holds at most one run; methods the tests do not reach panic on
+// the nil embedded interface.
+type fakeRuns struct {
+	repository.RunRepository
+	run     *domain.PayrollRun
+	deleted bool
+}
+
+func (f *fakeRuns) GetForUpdate(context.Context, int64, string) (domain.PayrollRun, error) {
+	if f.run == nil {
+		return domain.PayrollRun{}, util.ErrNotFound
+	}
+	return *f.run, nil
+}
+
+func (f *fakeRuns) Create(_ context.Context, r domain.PayrollRun) (domain.PayrollRun, error) {
+	f.run = &r
+	return r, nil
+}
+
+func (f *fakeRuns) Update(_ context.Context, r domain.PayrollRun) (domain.PayrollRun, error) {
+	f.run = &r
+	return r, nil
+}
+
+func (f *fakeRuns) Delete(context.Context, int64) error {
+	f.run, f.deleted = nil, true
+	return nil
+}
+
+func (f *fakeRuns) AddEvent(_ context.Context, ev domain.PayrollRunEvent) (domain.PayrollRunEvent, error) {
+	return ev, nil
+}
+
+type fakePayslips struct {
+	repository.PayrollRepository
+	payslips []domain.Payslip
+	created  int
+	deleted  int
+}
+
+func (f *fakePayslips) ListPayslipByPeriodID(context.Context, int64, string) ([]domain.Payslip, error) {
+	return f.payslips, nil
+}
+
+func (f *fakePayslips) CreatePayslip(_ context.Context, p domain.Payslip) (domain.Payslip, error) {
+	f.created++
+	return p, nil
+}
+
+func (f *fakePayslips) DeletePayslip(context.Context, int64) error {
+	f.deleted++
+	return nil
+}
+
+func TestSavePayslipsRunStatus(t *testing.T) {
+	june := domain.PayrollPeriod{ID: 1, Code: "2024-06"}
+	stale := []domain.Payslip{{ID: 7, EmployeeID: 2}}
+	newcomer := []domain.Employee{{ID: 3}}
+	tests := []struct {
+		name        string
+		status      string // empty for no run yet
+		employees   []domain.Employee
+		wantErr     error
+		wantStatus  string // empty when no run is left
+		wantDeleted bool
+	}{
+		{"first generation", "", newcomer, nil, domain.RunStatusDraft, false},
+		{"draft regenerated", domain.RunStatusDraft, newcomer, nil, domain.RunStatusDraft, false},
+		{"rejected regenerated", domain.RunStatusRejected, newcomer, nil, domain.RunStatusDraft, false},
+		{"draft left empty", domain.RunStatusDraft, nil, nil, "", true},
+		{"submitted", domain.RunStatusSubmitted, newcomer, util.ErrTransition, domain.RunStatusSubmitted, false},
+		{"approved", domain.RunStatusApproved, newcomer, util.ErrTransition, domain.RunStatusApproved, false},
+		// Regenerating with nobody in the run must not remove it.
+		{"approved left empty", domain.RunStatusApproved, nil, util.ErrTransition, domain.RunStatusApproved, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			runs := &fakeRuns{}
+			if tt.status != "" {
+				runs.run = &domain.PayrollRun{ID: 5, PayrollPeriodID: june.ID, RunType: domain.RunTypeTHR, Status: tt.status}
+			}
+			payslips := &fakePayslips{payslips: stale}
+			s := payrollService{runRepository: runs, payrollRepository: payslips}
+
+			_, err := s.savePayslips(context.Background(), june, domain.RunTypeTHR, tt.employees, nil, func(e domain.Employee) (domain.Payslip, error) {
+				return domain.Payslip{EmployeeID: e.ID}, nil
+			})
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("savePayslips() error = %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantErr != nil && (payslips.created != 0 || payslips.deleted != 0) {
+				t.Errorf("savePayslips() changed payslips of a %s run", tt.status)
+			}
+			status := ""
+			if runs.run != nil {
+				status = runs.run.Status
+			}
+			if status != tt.wantStatus || runs.deleted != tt.wantDeleted {
+				t.Errorf("run status = %q, deleted %v, want %q, deleted %v", status, runs.deleted, tt.wantStatus, tt.wantDeleted)
+			}
+		})
+	}
+}
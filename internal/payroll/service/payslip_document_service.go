@@ -0,0 +1,146 @@
+package service
+
+import (
+	"context"
+	"go-payroll-service/internal/payroll/document"
+	"go-payroll-service/internal/payroll/model/domain"
+	"go-payroll-service/internal/payroll/repository"
+	"go-payroll-service/internal/payroll/util"
+	"io"
+	"strings"
+)
+
+type PayslipDocumentService interface {
+	// RenderPayslip writes one employee's payslip PDF of the run type to w
+	// and returns its file name. With publishedOnly, as employees see it, the
+	// payslip and its year to date totals are of approved runs only.
+	RenderPayslip(ctx context.Context, periodCode, runType string, employeeID int64, publishedOnly bool, w io.Writer) (string, error)
+	// RenderPeriod writes a zip of every payslip PDF of the period's run
+	// type to w and returns its file name.
+	RenderPeriod(ctx context.Context, periodCode, runType string, w io.Writer) (string, error)
+}
+
+type payslipDocumentService struct {
+	payrollRepository  repository.PayrollRepository
+	periodRepository   repository.PeriodRepository
+	employeeRepository repository.EmployeeRepository
+	renderer           *document.Renderer
+}
+
+func (s payslipDocumentService) RenderPayslip(ctx context.Context, periodCode, runType string, employeeID int64, publishedOnly bool,
+	w io.Writer) (string, error) {
+	docs, err := s.documents(ctx, periodCode, runType, publishedOnly, func(p domain.PayslipWithEmployee) bool {
+		return p.EmployeeID == employeeID && (!publishedOnly || p.Published())
+	})
+	if err != nil {
+		return "", err
+	}
+	if len(docs) == 0 {
+		return "", util.ErrNotFound
+	}
+
+	name, err := s.renderer.FileName(docs[0])
+	if err != nil {
+		return "", err
+	}
+	return name, s.renderer.Render(w, docs[0])
+}
+
+func (s payslipDocumentService) RenderPeriod(ctx context.Context, periodCode, runType string, w io.Writer) (string, error) {
+	docs, err := s.documents(ctx, periodCode, runType, false, nil)
+	if err != nil {
+		return "", err
+	}
+	name := "payslips-" + periodCode
+	if runType != domain.RunTypeRegular {
+		name += "-" + runType
+	}
+	return name + ".zip", s.renderer.RenderBundle(w, docs)
+}
+
+// documents loads the payslips of the period's run type accepted by keep, or
+// all of them when keep is nil, with what the document prints besides the
+// payslip. The year to date totals count published payslips only if
+// publishedOnly is set.
+func (s payslipDocumentService) documents(ctx context.Context, periodCode, runType string, publishedOnly bool,
+	keep func(domain.PayslipWithEmployee) bool) ([]document.Payslip, error) {
+	period, err := s.periodRepository.GetByCode(ctx, periodCode)
+	if err != nil {
+		return nil, err
+	}
+	payslips, err := s.payrollRepository.ListPayslipByPeriodCode(ctx, periodCode, runType)
+	if err != nil {
+		return nil, err
+	}
+
+	var docs []document.Payslip
+	for _, p := range payslips {
+		if keep != nil && !keep(p) {
+			continue
+		}
+		e, err := s.employeeRepository.GetByID(ctx, p.EmployeeID)
+		if err != nil {
+			return nil, err
+		}
+		ytd, err := s.payrollRepository.GetPayslipYTD(ctx, p.EmployeeID, period, publishedOnly)
+		if err != nil {
+			return nil, err
+		}
+		docs = append(docs, toPayslipDocument(period, e, p.Payslip, ytd))
+	}
+	return docs, nil
+}
+
+func toPayslipDocument(period domain.PayrollPeriod, e domain.Employee, p domain.Payslip, ytd domain.PayslipYTD) document.Payslip {
+	doc := document.Payslip{
+		PeriodCode:      period.Code,
+		Run:             runLabel(p.RunType),
+		PeriodStart:     period.StartDate,
+		PeriodEnd:       period.EndDate,
+		PayDate:         period.PayDate,
+		EmployeeCode:    e.Code,
+		EmployeeName:    e.FullName,
+		NPWP:            e.NPWP,
+		PTKPStatus:      e.PTKPStatus,
+		TotalEarnings:   p.Total(domain.LineTypeEarning),
+		TotalDeductions: p.Total(domain.LineTypeDeduction),
+		NetSalary:       p.NetSalary(),
+		TaxMethod:       p.TaxMethod,
+		ProrationFactor: p.ProrationFactor(),
+		YTD: document.YTD{
+			GrossIncome: ytd.Earnings,
+			IncomeTax:   ytd.IncomeTax,
+			NetSalary:   ytd.NetSalary(),
+		},
+	}
+	for _, l := range p.Lines {
+		line := document.Line{Label: l.Label, Amount: l.Amount}
+		switch l.Type {
+		case domain.LineTypeEarning:
+			doc.Earnings = append(doc.Earnings, line)
+		case domain.LineTypeDeduction:
+			doc.Deductions = append(doc.Deductions, line)
+		case domain.LineTypeEmployerContribution:
+			doc.EmployerContributions = append(doc.EmployerContributions, line)
+		}
+	}
+	return doc
+}
+
+// runLabel is how documents name a run type; the regular run is unnamed.
+func runLabel(runType string) string {
+	if runType == domain.RunTypeRegular {
+		return ""
+	}
+	return strings.ToUpper(runType)
+}
+
+func NewPayslipDocumentService(payrollRepository repository.PayrollRepository, periodRepository repository.PeriodRepository,
+	employeeRepository repository.EmployeeRepository, renderer *document.Renderer) PayslipDocumentService {
+	return &payslipDocumentService{
+		payrollRepository:  payrollRepository,
+		periodRepository:   periodRepository,
+		employeeRepository: employeeRepository,
+		renderer:           renderer,
+	}
+}
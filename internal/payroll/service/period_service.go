@@ -0,0 +1,201 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"go-payroll-service/internal/payroll/model/domain"
+	"go-payroll-service/internal/payroll/model/request"
+	"go-payroll-service/internal/payroll/repository"
+	"go-payroll-service/internal/payroll/util"
+	"slices"
+	"strings"
+	"time"
+)
+
+const dateLayout = "2006-01-02"
+
+const (
+	PeriodActionOpen   = "open"
+	PeriodActionLock   = "lock"
+	PeriodActionClose  = "close"
+	PeriodActionReopen = "reopen"
+)
+
+// periodTransitions is the period state machine: for each action, the
+// statuses it may start from and the status it leads to.
+var periodTransitions = map[string]struct {
+	from []string
+	to   string
+}{
+	PeriodActionOpen:   {from: []string{domain.PeriodStatusDraft}, to: domain.PeriodStatusOpen},
+	PeriodActionLock:   {from: []string{domain.PeriodStatusOpen}, to: domain.PeriodStatusLocked},
+	PeriodActionClose:  {from: []string{domain.PeriodStatusLocked}, to: domain.PeriodStatusClosed},
+	PeriodActionReopen: {from: []string{domain.PeriodStatusLocked, domain.PeriodStatusClosed}, to: domain.PeriodStatusOpen},
+}
+
+type PeriodService interface {
+	List(ctx context.Context) ([]domain.PayrollPeriod, error)
+	Create(ctx context.Context, req request.CreatePeriodRequest) (domain.PayrollPeriod, error)
+	GetByCode(ctx context.Context, code string) (domain.PayrollPeriod, error)
+	Transition(ctx context.Context, code, action string, req request.PeriodTransitionRequest) (domain.PayrollPeriod, error)
+}
+
+type periodService struct {
+	repository      repository.PeriodRepository
+	runRepository   repository.RunRepository
+	auditRepository repository.AuditRepository
+	transactor      repository.Transactor
+}
+
+func (s periodService) List(ctx context.Context) ([]domain.PayrollPeriod, error) {
+	return s.repository.List(ctx)
+}
+
+func (s periodService) Create(ctx context.Context, req request.CreatePeriodRequest) (domain.PayrollPeriod, error) {
+	start, err := time.Parse(dateLayout, req.StartDate)
+	if err != nil {
+		return domain.PayrollPeriod{}, fmt.Errorf("%w: start_date: %w", util.ErrInvalid, err)
+	}
+	end, err := time.Parse(dateLayout, req.EndDate)
+	if err != nil {
+		return domain.PayrollPeriod{}, fmt.Errorf("%w: end_date: %w", util.ErrInvalid, err)
+	}
+	payDate, err := time.Parse(dateLayout, req.PayDate)
+	if err != nil {
+		return domain.PayrollPeriod{}, fmt.Errorf("%w: pay_date: %w", util.ErrInvalid, err)
+	}
+	if end.Before(start) {
+		return domain.PayrollPeriod{}, fmt.Errorf("%w: end_date is before start_date", util.ErrInvalid)
+	}
+	if payDate.Before(start) {
+		return domain.PayrollPeriod{}, fmt.Errorf("%w: pay_date is before start_date", util.ErrInvalid)
+	}
+
+	var created domain.PayrollPeriod
+	err = s.transactor.WithinTx(ctx, func(tx *sql.Tx) error {
+		repo := s.repository.WithTx(tx)
+		overlaps, err := repo.ExistsOverlapping(ctx, start, end)
+		if err != nil {
+			return err
+		}
+		if overlaps {
+			return fmt.Errorf("%w: period overlaps an existing period", util.ErrInvalid)
+		}
+		created, err = repo.Create(ctx, domain.PayrollPeriod{
+			Code:      req.Code,
+			StartDate: start,
+			EndDate:   end,
+			PayDate:   payDate,
+			Status:    domain.PeriodStatusDraft,
+		})
+		if err != nil {
+			return err
+		}
+		return recordAudit(ctx, s.auditRepository.WithTx(tx), domain.AuditActionCreate, domain.AuditEntityPeriod, created.Code, nil, created)
+	})
+	if err != nil {
+		return domain.PayrollPeriod{}, err
+	}
+	return created, nil
+}
+
+func (s periodService) GetByCode(ctx context.Context, code string) (domain.PayrollPeriod, error) {
+	p, err := s.repository.GetByCode(ctx, code)
+	if err != nil {
+		return domain.PayrollPeriod{}, err
+	}
+	p.Events, err = s.repository.ListEvents(ctx, p.ID)
+	if err != nil {
+		return domain.PayrollPeriod{}, err
+	}
+	return p, nil
+}
+
+// Transition applies a lifecycle action to a period and records it.
+// Reopening requires a reason; closing requires every run of the period to
+// be approved.
+func (s periodService) Transition(ctx context.Context, code, action string, req request.PeriodTransitionRequest) (domain.PayrollPeriod, error) {
+	t, ok := periodTransitions[action]
+	if !ok {
+		return domain.PayrollPeriod{}, fmt.Errorf("%w: unknown action %q", util.ErrInvalid, action)
+	}
+	reason := strings.TrimSpace(req.Reason)
+	if action == PeriodActionReopen && reason == "" {
+		return domain.PayrollPeriod{}, fmt.Errorf("%w: a reason is required to reopen a period", util.ErrInvalid)
+	}
+
+	var result domain.PayrollPeriod
+	err := s.transactor.WithinTx(ctx, func(tx *sql.Tx) error {
+		repo := s.repository.WithTx(tx)
+
+		p, err := repo.GetByCodeForUpdate(ctx, code)
+		if err != nil {
+			return err
+		}
+		if !slices.Contains(t.from, p.Status) {
+			return fmt.Errorf("%w: cannot %s a period that is %s", util.ErrTransition, action, p.Status)
+		}
+		if action == PeriodActionClose {
+			if err := requireApproved(ctx, s.runRepository.WithTx(tx), p); err != nil {
+				return err
+			}
+		}
+
+		before := p
+		from := p.Status
+		p.Status = t.to
+		if p, err = repo.UpdateStatus(ctx, p); err != nil {
+			return err
+		}
+		if _, err := repo.AddEvent(ctx, domain.PayrollPeriodEvent{
+			PayrollPeriodID: p.ID,
+			Action:          action,
+			FromStatus:      from,
+			ToStatus:        p.Status,
+			Reason:          reason,
+		}); err != nil {
+			return err
+		}
+		if err := recordAudit(ctx, s.auditRepository.WithTx(tx), action, domain.AuditEntityPeriod, p.Code, before, p); err != nil {
+			return err
+		}
+		if p.Events, err = repo.ListEvents(ctx, p.ID); err != nil {
+			return err
+		}
+		result = p
+		return nil
+	})
+	if err != nil {
+		return domain.PayrollPeriod{}, err
+	}
+	return result, nil
+}
+
+// requireApproved checks that every run of the period is approved.
+func requireApproved(ctx context.Context, runs repository.RunRepository, p domain.PayrollPeriod) error {
+	list, err := runs.ListByPeriodID(ctx, p.ID)
+	if err != nil {
+		return err
+	}
+	var pending []string
+	for _, run := range list {
+		if run.Status != domain.RunStatusApproved {
+			pending = append(pending, run.RunType+" run is "+run.Status)
+		}
+	}
+	if len(pending) > 0 {
+		return fmt.Errorf("%w: cannot close period %s: %s", util.ErrTransition, p.Code, strings.Join(pending, ", "))
+	}
+	return nil
+}
+
+func NewPeriodService(repository repository.PeriodRepository, runRepository repository.RunRepository,
+	auditRepository repository.AuditRepository, transactor repository.Transactor) PeriodService {
+	return &periodService{
+		repository:      repository,
+		runRepository:   runRepository,
+		auditRepository: auditRepository,
+		transactor:      transactor,
+	}
+}
@@ -0,0 +1,196 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"go-payroll-service/internal/payroll/approval"
+	"go-payroll-service/internal/payroll/model/domain"
+	"go-payroll-service/internal/payroll/model/request"
+	"go-payroll-service/internal/payroll/repository"
+	"go-payroll-service/internal/payroll/util"
+	"slices"
+	"strings"
+)
+
+// runReviewFrom lists, for each review action, the run statuses it may start
+// from.
+var runReviewFrom = map[string][]string{
+	domain.RunActionSubmit:  {domain.RunStatusDraft, domain.RunStatusRejected},
+	domain.RunActionApprove: {domain.RunStatusSubmitted},
+	domain.RunActionReject:  {domain.RunStatusSubmitted},
+}
+
+type RunService interface {
+	// List returns the runs of the period with their review history.
+	List(ctx context.Context, periodCode string) ([]domain.PayrollRun, error)
+	Get(ctx context.Context, periodCode, runType string) (domain.PayrollRun, error)
+	// Review applies a review action to the run and records it.
+	Review(ctx context.Context, periodCode, runType, action string, req request.ReviewRunRequest) (domain.PayrollRun, error)
+}
+
+type runService struct {
+	repository        repository.RunRepository
+	periodRepository  repository.PeriodRepository
+	payrollRepository repository.PayrollRepository
+	auditRepository   repository.AuditRepository
+	transactor        repository.Transactor
+	policy            approval.Policy
+}
+
+func (s runService) withTx(tx *sql.Tx) runService {
+	s.repository = s.repository.WithTx(tx)
+	s.periodRepository = s.periodRepository.WithTx(tx)
+	s.payrollRepository = s.payrollRepository.WithTx(tx)
+	s.auditRepository = s.auditRepository.WithTx(tx)
+	return s
+}
+
+func (s runService) List(ctx context.Context, periodCode string) ([]domain.PayrollRun, error) {
+	period, err := s.periodRepository.GetByCode(ctx, periodCode)
+	if err != nil {
+		return nil, err
+	}
+	runs, err := s.repository.ListByPeriodID(ctx, period.ID)
+	if err != nil {
+		return nil, err
+	}
+	for i := range runs {
+		if runs[i].Events, err = s.repository.ListEvents(ctx, runs[i].ID); err != nil {
+			return nil, err
+		}
+	}
+	return runs, nil
+}
+
+func (s runService) Get(ctx context.Context, periodCode, runType string) (domain.PayrollRun, error) {
+	period, err := s.periodRepository.GetByCode(ctx, periodCode)
+	if err != nil {
+		return domain.PayrollRun{}, err
+	}
+	run, err := s.repository.Get(ctx, period.ID, runType)
+	if err != nil {
+		return domain.PayrollRun{}, err
+	}
+	if run.Events, err = s.repository.ListEvents(ctx, run.ID); err != nil {
+		return domain.PayrollRun{}, err
+	}
+	return run, nil
+}
+
+// Review submits a draft or rejected run for approval, fixing how many
+// approvals it needs by its total net pay, or approves or rejects a
+// submitted one. The run is approved once it has as many approvals as it
+// needs; a rejection requires a comment. Whoever generated or submitted the
+// run may not approve or reject it, and an approver counts once per round.
+func (s runService) Review(ctx context.Context, periodCode, runType, action string, req request.ReviewRunRequest) (domain.PayrollRun, error) {
+	from, ok := runReviewFrom[action]
+	if !ok {
+		return domain.PayrollRun{}, fmt.Errorf("%w: unknown action %q", util.ErrInvalid, action)
+	}
+	comment := strings.TrimSpace(req.Comment)
+	if action == domain.RunActionReject && comment == "" {
+		return domain.PayrollRun{}, fmt.Errorf("%w: a comment is required to reject a run", util.ErrInvalid)
+	}
+	actor := actorFrom(ctx)
+
+	var result domain.PayrollRun
+	err := s.transactor.WithinTx(ctx, func(tx *sql.Tx) error {
+		txs := s.withTx(tx)
+
+		period, err := txs.periodRepository.GetByCodeForUpdate(ctx, periodCode)
+		if err != nil {
+			return err
+		}
+		if period.Status == domain.PeriodStatusClosed {
+			return util.ErrPeriodClosed
+		}
+		run, err := txs.repository.GetForUpdate(ctx, period.ID, runType)
+		if err != nil {
+			return err
+		}
+		if !slices.Contains(from, run.Status) {
+			return fmt.Errorf("%w: cannot %s a run that is %s", util.ErrTransition, action, run.Status)
+		}
+		if run.Events, err = txs.repository.ListEvents(ctx, run.ID); err != nil {
+			return err
+		}
+		if action != domain.RunActionSubmit {
+			if actor == run.GeneratedBy || actor == run.SubmittedBy {
+				return fmt.Errorf("%w: %s generated or submitted the run and cannot %s it", util.ErrForbidden, actor, action)
+			}
+			if slices.Contains(run.Approvers(), actor) {
+				return fmt.Errorf("%w: %s already approved the run", util.ErrForbidden, actor)
+			}
+		}
+
+		before := run
+		before.Events = nil
+		switch action {
+		case domain.RunActionSubmit:
+			payslips, err := txs.payrollRepository.ListPayslipByPeriodID(ctx, period.ID, runType)
+			if err != nil {
+				return err
+			}
+			if len(payslips) == 0 {
+				return fmt.Errorf("%w: the run has no payslips", util.ErrInvalid)
+			}
+			run.TotalNet = 0
+			for _, p := range payslips {
+				run.TotalNet += p.NetSalary()
+			}
+			run.RequiredLevels = s.policy.Levels(run.TotalNet)
+			run.Round++
+			run.SubmittedBy = actor
+			run.Status = domain.RunStatusSubmitted
+		case domain.RunActionApprove:
+			if len(run.Approvers())+1 >= run.RequiredLevels {
+				run.Status = domain.RunStatusApproved
+			}
+		case domain.RunActionReject:
+			run.Status = domain.RunStatusRejected
+		}
+
+		if run, err = txs.repository.Update(ctx, run); err != nil {
+			return err
+		}
+		if _, err := txs.repository.AddEvent(ctx, domain.PayrollRunEvent{
+			PayrollRunID: run.ID,
+			Round:        run.Round,
+			Action:       action,
+			FromStatus:   before.Status,
+			ToStatus:     run.Status,
+			Actor:        actor,
+			Comment:      comment,
+		}); err != nil {
+			return err
+		}
+		after := run
+		after.Events = nil
+		if err := recordAudit(ctx, txs.auditRepository, action, domain.AuditEntityPayrollRun, periodCode+"/"+runType, before, after); err != nil {
+			return err
+		}
+		if run.Events, err = txs.repository.ListEvents(ctx, run.ID); err != nil {
+			return err
+		}
+		result = run
+		return nil
+	})
+	if err != nil {
+		return domain.PayrollRun{}, err
+	}
+	return result, nil
+}
+
+func NewRunService(repository repository.RunRepository, periodRepository repository.PeriodRepository,
+	payrollRepository repository.PayrollRepository, auditRepository repository.AuditRepository,
+	transactor repository.Transactor, policy approval.Policy) RunService {
+	return &runService{
+		repository:        repository,
+		periodRepository:  periodRepository,
+		payrollRepository: payrollRepository,
+		auditRepository:   auditRepository,
+		transactor:        transactor,
+		policy:            policy,
+	}
+}
@@ -0,0 +1,114 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"go-payroll-service/internal/payroll/model/domain"
+	"go-payroll-service/internal/payroll/model/request"
+	"go-payroll-service/internal/payroll/repository"
+	"go-payroll-service/internal/payroll/util"
+	"time"
+)
+
+type SalaryService interface {
+	History(ctx context.Context, employeeID int64) ([]domain.SalaryRecord, error)
+	Schedule(ctx context.Context, employeeID int64, req request.ScheduleSalaryRequest) (domain.SalaryRecord, error)
+}
+
+type salaryService struct {
+	repository         repository.SalaryRepository
+	employeeRepository repository.EmployeeRepository
+	auditRepository    repository.AuditRepository
+	transactor         repository.Transactor
+}
+
+func (s salaryService) withTx(tx *sql.Tx) salaryService {
+	s.repository = s.repository.WithTx(tx)
+	s.employeeRepository = s.employeeRepository.WithTx(tx)
+	s.auditRepository = s.auditRepository.WithTx(tx)
+	return s
+}
+
+func (s salaryService) History(ctx context.Context, employeeID int64) ([]domain.SalaryRecord, error) {
+	if _, err := s.employeeRepository.GetByID(ctx, employeeID); err != nil {
+		return nil, err
+	}
+	return s.repository.ListByEmployee(ctx, employeeID)
+}
+
+// Schedule records a salary change. Past dates are allowed for back pay;
+// payslips of open periods pick it up when payroll is generated again.
+func (s salaryService) Schedule(ctx context.Context, employeeID int64, req request.ScheduleSalaryRequest) (domain.SalaryRecord, error) {
+	e, err := s.employeeRepository.GetByID(ctx, employeeID)
+	if err != nil {
+		return domain.SalaryRecord{}, err
+	}
+	from, err := time.Parse(dateLayout, req.EffectiveFrom)
+	if err != nil {
+		return domain.SalaryRecord{}, fmt.Errorf("%w: effective_from: %w", util.ErrInvalid, err)
+	}
+
+	rec := domain.SalaryRecord{
+		EmployeeID:    employeeID,
+		BaseSalary:    req.BaseSalary,
+		Allowance:     req.Allowance,
+		EffectiveFrom: from,
+		Reason:        req.Reason,
+		ApprovedBy:    actorFrom(ctx),
+	}
+	history, err := s.repository.ListByEmployee(ctx, employeeID)
+	if err != nil {
+		return domain.SalaryRecord{}, err
+	}
+	if err := checkSalaryChange(e, history, rec); err != nil {
+		return domain.SalaryRecord{}, err
+	}
+
+	var created domain.SalaryRecord
+	err = s.transactor.WithinTx(ctx, func(tx *sql.Tx) error {
+		txs := s.withTx(tx)
+		if created, err = txs.repository.Create(ctx, rec); err != nil {
+			return err
+		}
+		return recordAudit(ctx, txs.auditRepository, domain.AuditActionCreate, domain.AuditEntitySalary, employeeID, nil, created)
+	})
+	if err != nil {
+		return domain.SalaryRecord{}, err
+	}
+	return created, nil
+}
+
+// checkSalaryChange validates a new record against the employee's
+// employment dates and existing history.
+func checkSalaryChange(e domain.Employee, history []domain.SalaryRecord, rec domain.SalaryRecord) error {
+	if rec.EffectiveFrom.Before(e.HireDate) {
+		return fmt.Errorf("%w: effective_from is before hire_date", util.ErrInvalid)
+	}
+	if e.TerminationDate != nil && rec.EffectiveFrom.After(*e.TerminationDate) {
+		return fmt.Errorf("%w: effective_from is after termination_date", util.ErrInvalid)
+	}
+	for _, h := range history {
+		if h.EffectiveFrom.Equal(rec.EffectiveFrom) {
+			return fmt.Errorf("%w: a salary change already takes effect on %s", util.ErrInvalid, rec.EffectiveFrom.Format(dateLayout))
+		}
+	}
+	return nil
+}
+
+// today is the current calendar date, in the same form as dates read from
+// the database.
+func today() time.Time {
+	y, m, d := time.Now().Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+}
+
+func NewSalaryService(repository repository.SalaryRepository, employeeRepository repository.EmployeeRepository,
+	auditRepository repository.AuditRepository, transactor repository.Transactor) SalaryService {
+	return &salaryService{
+		repository:         repository,
+		employeeRepository: employeeRepository,
+		auditRepository:    auditRepository,
+		transactor:         transactor,
+	}
+}
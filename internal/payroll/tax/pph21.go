@@ -0,0 +1,180 @@
+package tax
+
+import (
+	"errors"
+	"fmt"
+	"go-payroll-service/internal/payroll/model/domain"
+)
+
+const (
+	MethodTER    = "TER"
+	MethodAnnual = "ANNUAL"
+)
+
+var (
+	ErrUnknownPTKPStatus = errors.New("unknown PTKP status")
+	ErrNoTERTable        = errors.New("no TER table for category")
+)
+
+// Input describes one employee's month. GrossIncome is every taxable
+// earning for the month, including employer-paid insurance premiums.
+// PensionContribution is the employee's own JHT/JP contribution, which is
+// deductible in the annual calculation.
+type Input struct {
+	PTKPStatus          string
+	HasNPWP             bool
+	GrossIncome         int64
+	PensionContribution int64
+	// Annualize switches to the annual true-up used for December and for an
+	// employee's final month of the year.
+	Annualize bool
+	YTD       domain.TaxYTD
+	// Month is what the employee's other payslips of the same month, such
+	// as the regular salary next to a THR payslip, earned and withheld. The
+	// month is taxed as a whole and this payslip withholds the rest.
+	Month domain.TaxYTD
+}
+
+type Result struct {
+	Tax    int64
+	Method string
+	Lines  []domain.PayslipTaxLine
+}
+
+// Calculate returns the PPh 21 to withhold for the month. January to
+// November use the TER monthly effective rate; an annualized month computes
+// the full-year liability with the progressive brackets and withholds the
+// difference from what was already withheld, which may be negative.
+func Calculate(rates domain.TaxRates, in Input) (Result, error) {
+	ptkp, ok := rates.PTKP[in.PTKPStatus]
+	if !ok {
+		return Result{}, fmt.Errorf("%w: %q", ErrUnknownPTKPStatus, in.PTKPStatus)
+	}
+	if in.Annualize {
+		return annual(rates, ptkp, in), nil
+	}
+	return monthly(rates, ptkp, in)
+}
+
+func monthly(rates domain.TaxRates, ptkp domain.PTKPRate, in Input) (Result, error) {
+	table, ok := rates.TER[ptkp.TERCategory]
+	if !ok {
+		return Result{}, fmt.Errorf("%w %q", ErrNoTERTable, ptkp.TERCategory)
+	}
+
+	var b lines
+	b.add("GROSS", "Gross income", in.GrossIncome)
+
+	gross := in.GrossIncome
+	if in.Month.TaxableIncome != 0 {
+		b.add("GROSS_MONTH", "Gross income of other payslips this month", in.Month.TaxableIncome)
+		gross += in.Month.TaxableIncome
+	}
+
+	rate := findRate(table, gross)
+	tax := applyRate(gross, rate)
+	b.add("TER", fmt.Sprintf("TER category %s at %s", ptkp.TERCategory, formatBP(rate)), tax)
+
+	tax = surcharge(rates, in, tax, &b)
+	if in.Month.IncomeTax != 0 {
+		b.add("WITHHELD_MONTH", "PPh 21 already withheld this month", -in.Month.IncomeTax)
+		tax -= in.Month.IncomeTax
+	}
+	b.add("PPH21", "PPh 21 withheld", tax)
+
+	return Result{Tax: tax, Method: MethodTER, Lines: b.items}, nil
+}
+
+func annual(rates domain.TaxRates, ptkp domain.PTKPRate, in Input) Result {
+	var b lines
+
+	months := int64(in.YTD.Months + 1)
+	gross := in.YTD.TaxableIncome + in.Month.TaxableIncome + in.GrossIncome
+	b.add("GROSS_ANNUAL", "Gross income, year to date", gross)
+
+	positionCost := applyRate(gross, rates.PositionCostRateBP)
+	limit := min(rates.PositionCostAnnualCap, rates.PositionCostMonthlyCap*months)
+	positionCost = min(positionCost, limit)
+	b.add("POSITION_COST", "Position cost (biaya jabatan)", -positionCost)
+
+	pension := in.YTD.PensionContribution + in.Month.PensionContribution + in.PensionContribution
+	if pension != 0 {
+		b.add("PENSION", "Employee JHT/JP contributions", -pension)
+	}
+
+	net := gross - positionCost - pension
+	b.add("NET_ANNUAL", "Net annual income", net)
+	b.add("PTKP", fmt.Sprintf("Non-taxable income (PTKP %s)", ptkp.Status), -ptkp.Amount)
+
+	pkp := max(net-ptkp.Amount, 0)
+	pkp = pkp / 1000 * 1000
+	b.add("PKP", "Taxable income (PKP)", pkp)
+
+	annualTax := progressive(rates.Brackets, pkp)
+	b.add("ANNUAL_TAX", "Annual PPh 21", annualTax)
+	annualTax = surcharge(rates, in, annualTax, &b)
+
+	withheld := in.YTD.IncomeTax + in.Month.IncomeTax
+	b.add("WITHHELD_YTD", "PPh 21 already withheld this year", -withheld)
+
+	tax := annualTax - withheld
+	b.add("PPH21", "PPh 21 withheld", tax)
+
+	return Result{Tax: tax, Method: MethodAnnual, Lines: b.items}
+}
+
+func surcharge(rates domain.TaxRates, in Input, tax int64, b *lines) int64 {
+	if in.HasNPWP || rates.NonNPWPSurchargeBP == 0 || tax <= 0 {
+		return tax
+	}
+	extra := applyRate(tax, rates.NonNPWPSurchargeBP)
+	b.add("NON_NPWP", fmt.Sprintf("Surcharge without NPWP (%s)", formatBP(rates.NonNPWPSurchargeBP)), extra)
+	return tax + extra
+}
+
+func findRate(table []domain.TaxBracket, income int64) int64 {
+	for _, br := range table {
+		if income > br.LowerBound || br.LowerBound == 0 {
+			if br.UpperBound == 0 || income <= br.UpperBound {
+				return br.RateBP
+			}
+		}
+	}
+	return 0
+}
+
+func progressive(brackets []domain.TaxBracket, income int64) int64 {
+	var total int64
+	for _, br := range brackets {
+		if income <= br.LowerBound {
+			break
+		}
+		upper := income
+		if br.UpperBound != 0 && br.UpperBound < upper {
+			upper = br.UpperBound
+		}
+		total += applyRate(upper-br.LowerBound, br.RateBP)
+	}
+	return total
+}
+
+func applyRate(amount, rateBP int64) int64 {
+	return amount * rateBP / 10000
+}
+
+func formatBP(bp int64) string {
+	return fmt.Sprintf("%d.%02d%%", bp/100, bp%100)
+}
+
+type lines struct {
+	items []domain.PayslipTaxLine
+}
+
+func (l *lines) add(code, label string, amount int64) {
+	l.items = append(l.items, domain.PayslipTaxLine{
+		LineNo: len(l.items) + 1,
+		Code:   code,
+		Label:  label,
+		Amount: amount,
+	})
+}
@@ -0,0 +1,190 @@
+package tax
+
+import (
+	"errors"
+	"go-payroll-service/internal/payroll/model/domain"
+	"testing"
+)
+
+// rates2024 is the 2024 rate set as seeded by the initial migration, with
+// the TER tables cut short to the incomes the tests use.
+var rates2024 = domain.TaxRates{
+	EffectiveYear:          2024,
+	PositionCostRateBP:     500,
+	PositionCostMonthlyCap: 500000,
+	PositionCostAnnualCap:  6000000,
+	NonNPWPSurchargeBP:     2000,
+	PTKP: map[string]domain.PTKPRate{
+		"TK/0": {Status: "TK/0", Amount: 54000000, TERCategory: "A"},
+		"TK/1": {Status: "TK/1", Amount: 58500000, TERCategory: "A"},
+		"K/1":  {Status: "K/1", Amount: 63000000, TERCategory: "B"},
+		"K/3":  {Status: "K/3", Amount: 72000000, TERCategory: "C"},
+		// Not a real status: its category has no TER table.
+		"K/9": {Status: "K/9", Amount: 72000000, TERCategory: "Z"},
+	},
+	Brackets: []domain.TaxBracket{
+		{LowerBound: 0, UpperBound: 60000000, RateBP: 500},
+		{LowerBound: 60000000, UpperBound: 250000000, RateBP: 1500},
+		{LowerBound: 250000000, UpperBound: 500000000, RateBP: 2500},
+		{LowerBound: 500000000, UpperBound: 5000000000, RateBP: 3000},
+		{LowerBound: 5000000000, RateBP: 3500},
+	},
+	TER: map[string][]domain.TaxBracket{
+		"A": {
+			{LowerBound: 0, UpperBound: 5400000, RateBP: 0},
+			{LowerBound: 5400000, UpperBound: 5650000, RateBP: 25},
+			{LowerBound: 5650000, UpperBound: 5950000, RateBP: 50},
+			{LowerBound: 5950000, UpperBound: 6300000, RateBP: 75},
+			{LowerBound: 6300000, UpperBound: 6750000, RateBP: 100},
+			{LowerBound: 6750000, UpperBound: 7500000, RateBP: 125},
+			{LowerBound: 7500000, UpperBound: 8550000, RateBP: 150},
+			{LowerBound: 8550000, UpperBound: 9650000, RateBP: 175},
+			{LowerBound: 9650000, UpperBound: 10050000, RateBP: 200},
+			{LowerBound: 10050000, UpperBound: 10350000, RateBP: 225},
+			{LowerBound: 10350000, UpperBound: 10700000, RateBP: 250},
+			{LowerBound: 10700000, UpperBound: 11050000, RateBP: 300},
+			{LowerBound: 11050000, UpperBound: 11600000, RateBP: 350},
+			{LowerBound: 11600000, UpperBound: 12500000, RateBP: 400},
+			{LowerBound: 12500000, UpperBound: 13750000, RateBP: 500},
+			{LowerBound: 13750000, UpperBound: 15100000, RateBP: 600},
+		},
+		"B": {
+			{LowerBound: 0, UpperBound: 6200000, RateBP: 0},
+			{LowerBound: 6200000, UpperBound: 6500000, RateBP: 25},
+			{LowerBound: 6500000, UpperBound: 6850000, RateBP: 50},
+			{LowerBound: 6850000, UpperBound: 7300000, RateBP: 75},
+		},
+		"C": {
+			{LowerBound: 0, UpperBound: 6600000, RateBP: 0},
+			{LowerBound: 6600000, UpperBound: 6950000, RateBP: 25},
+			{LowerBound: 6950000, UpperBound: 7350000, RateBP: 50},
+		},
+	},
+}
+
+func TestCalculateTER(t *testing.T) {
+	tests := []struct {
+		name string
+		in   Input
+		want int64
+	}{
+		{"top of the zero bracket", Input{PTKPStatus: "TK/0", HasNPWP: true, GrossIncome: 5400000}, 0},
+		{"just above the zero bracket", Input{PTKPStatus: "TK/0", HasNPWP: true, GrossIncome: 5400001}, 13500},
+		{"category A", Input{PTKPStatus: "TK/0", HasNPWP: true, GrossIncome: 7000000}, 87500},
+		{"TK/1 is category A", Input{PTKPStatus: "TK/1", HasNPWP: true, GrossIncome: 7000000}, 87500},
+		{"category B", Input{PTKPStatus: "K/1", HasNPWP: true, GrossIncome: 7000000}, 52500},
+		{"category C", Input{PTKPStatus: "K/3", HasNPWP: true, GrossIncome: 7000000}, 35000},
+		{"10 million at 2%", Input{PTKPStatus: "TK/0", HasNPWP: true, GrossIncome: 10000000}, 200000},
+		{"no NPWP pays 20% more", Input{PTKPStatus: "TK/0", GrossIncome: 10000000}, 240000},
+		{"no NPWP on zero tax", Input{PTKPStatus: "TK/0", GrossIncome: 5000000}, 0},
+		{
+			// A THR payslip next to a regular salary of 10 million that
+			// withheld 200,000: the month is taxed on 15 million.
+			"other payslips of the month",
+			Input{PTKPStatus: "TK/0", HasNPWP: true, GrossIncome: 5000000,
+				Month: domain.TaxYTD{TaxableIncome: 10000000, IncomeTax: 200000}},
+			700000,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Calculate(rates2024, tt.in)
+			if err != nil {
+				t.Fatalf("Calculate() error = %v", err)
+			}
+			if got.Tax != tt.want {
+				t.Errorf("Calculate() tax = %d, want %d", got.Tax, tt.want)
+			}
+			if got.Method != MethodTER {
+				t.Errorf("Calculate() method = %s, want %s", got.Method, MethodTER)
+			}
+			if last := got.Lines[len(got.Lines)-1]; last.Code != "PPH21" || last.Amount != got.Tax {
+				t.Errorf("last line = %s %d, want PPH21 %d", last.Code, last.Amount, got.Tax)
+			}
+		})
+	}
+}
+
+func TestCalculateAnnual(t *testing.T) {
+	tests := []struct {
+		name string
+		in   Input
+		want int64
+	}{
+		{
+			// 120 million a year less 6 million position cost, 3.6 million
+			// JHT/JP and 54 million PTKP is 56.4 million PKP at 5%, of which
+			// 11 months of 200,000 TER were withheld.
+			"December true-up",
+			Input{PTKPStatus: "TK/0", HasNPWP: true, Annualize: true, GrossIncome: 10000000, PensionContribution: 300000,
+				YTD: domain.TaxYTD{Months: 11, TaxableIncome: 110000000, PensionContribution: 3300000, IncomeTax: 2200000}},
+			620000,
+		},
+		{
+			"December true-up without NPWP",
+			Input{PTKPStatus: "TK/0", Annualize: true, GrossIncome: 10000000, PensionContribution: 300000,
+				YTD: domain.TaxYTD{Months: 11, TaxableIncome: 110000000, PensionContribution: 3300000, IncomeTax: 2200000}},
+			1184000,
+		},
+		{
+			// 5% of 240 million is capped at 6 million: 234 million net less
+			// PTKP is 180 million, 60 million at 5% and 120 million at 15%.
+			"position cost capped a year",
+			Input{PTKPStatus: "TK/0", HasNPWP: true, Annualize: true, GrossIncome: 20000000,
+				YTD: domain.TaxYTD{Months: 11, TaxableIncome: 220000000}},
+			21000000,
+		},
+		{
+			// Three months employed: the cap is 3 times 500,000.
+			"position cost capped a month",
+			Input{PTKPStatus: "TK/0", HasNPWP: true, Annualize: true, GrossIncome: 20000000,
+				YTD: domain.TaxYTD{Months: 2, TaxableIncome: 40000000}},
+			225000,
+		},
+		{
+			"below PTKP refunds what was withheld",
+			Input{PTKPStatus: "K/3", HasNPWP: true, Annualize: true, GrossIncome: 5000000,
+				YTD: domain.TaxYTD{Months: 11, TaxableIncome: 55000000, IncomeTax: 100000}},
+			-100000,
+		},
+		{
+			// A single month: 61,234,567 less 500,000 position cost and 54
+			// million PTKP is 6,734,567, rounded down to 6,734,000 PKP.
+			"PKP rounded down to thousands",
+			Input{PTKPStatus: "TK/0", HasNPWP: true, Annualize: true, GrossIncome: 61234567},
+			336700,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Calculate(rates2024, tt.in)
+			if err != nil {
+				t.Fatalf("Calculate() error = %v", err)
+			}
+			if got.Tax != tt.want {
+				t.Errorf("Calculate() tax = %d, want %d", got.Tax, tt.want)
+			}
+			if got.Method != MethodAnnual {
+				t.Errorf("Calculate() method = %s, want %s", got.Method, MethodAnnual)
+			}
+		})
+	}
+}
+
+func TestCalculateErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		in   Input
+		want error
+	}{
+		{"unknown PTKP status", Input{PTKPStatus: "X/0", GrossIncome: 1}, ErrUnknownPTKPStatus},
+		{"no TER table", Input{PTKPStatus: "K/9", GrossIncome: 1}, ErrNoTERTable},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := Calculate(rates2024, tt.in); !errors.Is(err, tt.want) {
+				t.Errorf("Calculate() error = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
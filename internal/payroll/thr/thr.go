@@ -0,0 +1,26 @@
+// Package thr works out the religious holiday allowance (Tunjangan Hari
+// Raya) as Permenaker 6/2016 sets it out: one month's wage after a year of
+// service, a share of it by whole months served before that, paid at least
+// a week before the holiday.
+package thr
+
+// Service an employee needs on the holiday, in whole months, to be owed THR
+// and to be owed all of it.
+const (
+	MinServiceMonths  = 1
+	FullServiceMonths = 12
+)
+
+// DueDaysBefore is how many days before the holiday THR must be paid.
+const DueDaysBefore = 7
+
+// Amount is the THR owed on monthlyWage, the base salary plus fixed
+// allowances, after serviceMonths whole months of service, and the months it
+// pays for out of FullServiceMonths. It is zero below MinServiceMonths.
+func Amount(monthlyWage int64, serviceMonths int) (amount int64, months int) {
+	if serviceMonths < MinServiceMonths {
+		return 0, 0
+	}
+	months = min(serviceMonths, FullServiceMonths)
+	return monthlyWage * int64(months) / FullServiceMonths, months
+}
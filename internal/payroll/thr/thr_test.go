@@ -0,0 +1,30 @@
+package thr
+
+import "testing"
+
+func TestAmount(t *testing.T) {
+	tests := []struct {
+		name          string
+		wage          int64
+		serviceMonths int
+		wantAmount    int64
+		wantMonths    int
+	}{
+		{"less than a month", 6000000, 0, 0, 0},
+		{"one month", 6000000, 1, 500000, 1},
+		{"six months", 6000000, 6, 3000000, 6},
+		{"eleven months", 6000000, 11, 5500000, 11},
+		{"a year", 6000000, 12, 6000000, 12},
+		{"more than a year", 6000000, 40, 6000000, 12},
+		{"rounded down", 5000000, 7, 2916666, 7},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			amount, months := Amount(tt.wage, tt.serviceMonths)
+			if amount != tt.wantAmount || months != tt.wantMonths {
+				t.Errorf("Amount(%d, %d) = %d, %d, want %d, %d",
+					tt.wage, tt.serviceMonths, amount, months, tt.wantAmount, tt.wantMonths)
+			}
+		})
+	}
+}
@@ -1,7 +1,37 @@
 package util
 
-import "errors"
+import (
+	"errors"
+	"fmt"
+)
 
 var (
 	ErrNotFound = errors.New("not found")
+	ErrInvalid  = errors.New("invalid input")
+
+	ErrCalculation     = errors.New("payroll calculation failed")
+	ErrPeriodClosed    = errors.New("payroll period is closed")
+	ErrPeriodNotOpen   = errors.New("payroll period is not open")
+	ErrPeriodUnlocked  = errors.New("payroll period is not locked")
+	ErrPeriodNotClosed = errors.New("payroll period is not closed")
+	ErrTransition      = errors.New("transition not allowed")
+	ErrRunNotApproved  = errors.New("payroll run is not approved")
+	ErrForbidden       = errors.New("not allowed")
 )
+
+// PayrollRunError reports the employee a payroll run stopped on. The run is
+// rolled back, so no payslip of the period was changed.
+type PayrollRunError struct {
+	PeriodCode   string
+	EmployeeID   int64
+	EmployeeCode string
+	Err          error
+}
+
+func (e *PayrollRunError) Error() string {
+	return fmt.Sprintf("payroll %s: employee %s (id %d): %v", e.PeriodCode, e.EmployeeCode, e.EmployeeID, e.Err)
+}
+
+func (e *PayrollRunError) Unwrap() error {
+	return e.Err
+}